@@ -0,0 +1,515 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+// Conflict policies accepted by --on-conflict.
+const (
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+	conflictRename    = "rename"
+	conflictFail      = "fail"
+)
+
+// manifestFileName is written alongside saved artifacts and records every file
+// a2acli saved into that directory.
+const manifestFileName = "artifacts.json"
+
+// onConflict is the --on-conflict policy applied when an artifact's target
+// path already exists on disk.
+var onConflict = conflictRename
+
+var (
+	// artifactMu guards sessionPaths and manifest read-modify-write cycles.
+	artifactMu sync.Mutex
+	// sessionPaths maps an artifact key to the path it was first saved to in
+	// this process, so repeated updates of the same streaming artifact
+	// overwrite their own file instead of tripping the conflict policy.
+	sessionPaths = map[string]string{}
+)
+
+// errArtifactSkipped is returned when --on-conflict=skip left an existing
+// file untouched.
+var errArtifactSkipped = errors.New("file exists, skipped (--on-conflict=skip)")
+
+// errDownloadFailed wraps network and size-limit failures of a URL part, which
+// are reported by surfacing the URL instead of a saved file.
+var errDownloadFailed = errors.New("download failed")
+
+// manifestEntry describes one file written by saveArtifact.
+type manifestEntry struct {
+	Name         string    `json:"name"`
+	Path         string    `json:"path"`
+	MediaType    string    `json:"mediaType,omitempty"`
+	Size         int64     `json:"size"`
+	SHA256       string    `json:"sha256"`
+	ArtifactID   string    `json:"artifactId,omitempty"`
+	ArtifactName string    `json:"artifactName,omitempty"`
+	Part         *int      `json:"part,omitempty"`
+	SavedAt      time.Time `json:"savedAt"`
+}
+
+// manifestArtifact groups the files saved from the parts of one artifact.
+type manifestArtifact struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Files       []string `json:"files"`
+}
+
+// artifactManifest is the on-disk shape of artifacts.json.
+type artifactManifest struct {
+	Files     []manifestEntry    `json:"files"`
+	Artifacts []manifestArtifact `json:"artifacts,omitempty"`
+}
+
+// validateOnConflict checks the --on-conflict flag value.
+func validateOnConflict(policy string) error {
+	switch policy {
+	case conflictSkip, conflictOverwrite, conflictRename, conflictFail:
+		return nil
+	}
+	return fmt.Errorf("invalid --on-conflict value %q: must be skip, overwrite, rename, or fail", policy)
+}
+
+// sanitizeFileName reduces a server-supplied name to a single safe path
+// element. Directory components, leading dots, control characters and
+// characters that are invalid on common filesystems are removed. It returns
+// "" when nothing usable remains.
+func sanitizeFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		switch r {
+		case '<', '>', ':', '"', '|', '?', '*':
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	if len(name) > 200 {
+		ext := filepath.Ext(name)
+		if len(ext) > 20 {
+			ext = ""
+		}
+		name = name[:200-len(ext)] + ext
+	}
+	if name == manifestFileName {
+		name = "artifact-" + name
+	}
+	return name
+}
+
+// confinedPath joins dir and name and verifies that the result stays inside
+// dir, so server-controlled names can never escape --out-dir.
+func confinedPath(dir, name string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	p := filepath.Join(dir, name)
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("artifact name %q resolves outside of %s", name, dir)
+	}
+	return p, nil
+}
+
+// resolveConflict applies the --on-conflict policy to path. key identifies the
+// artifact being written; a path this process already wrote for the same key
+// is always reused. Must be called with artifactMu held.
+func resolveConflict(path, key string) (string, error) {
+	if prev, ok := sessionPaths[key]; ok && key != "" {
+		return prev, nil
+	}
+	fi, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) && !pathClaimed(path) {
+		return path, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if fi != nil && fi.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("refusing to write through symlink %s", path)
+	}
+	switch onConflict {
+	case conflictOverwrite:
+		return path, nil
+	case conflictSkip:
+		return path, errArtifactSkipped
+	case conflictFail:
+		return "", fmt.Errorf("%s already exists (use --on-conflict overwrite|rename|skip)", path)
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) && !pathClaimed(candidate) {
+			return candidate, nil
+		}
+	}
+}
+
+// pathClaimed reports whether another artifact saved in this process already
+// owns path, including downloads still in flight. Must be called with
+// artifactMu held.
+func pathClaimed(path string) bool {
+	for _, p := range sessionPaths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// claimArtifactPath applies the conflict policy to path and reserves the
+// result for key, so concurrent downloads never pick the same file.
+func claimArtifactPath(path, key string) (string, error) {
+	artifactMu.Lock()
+	defer artifactMu.Unlock()
+	final, err := resolveConflict(path, key)
+	if err == nil && key != "" {
+		sessionPaths[key] = final
+	}
+	return final, err
+}
+
+// recordArtifactFile adds a file that has already been written to the
+// directory's artifacts.json manifest.
+func recordArtifactFile(path string, size int64, sum string, entry manifestEntry, artifact a2a.Artifact) {
+	artifactMu.Lock()
+	defer artifactMu.Unlock()
+	entry.SHA256 = sum
+	entry.Size = size
+	entry.SavedAt = time.Now().UTC()
+	if err := recordManifest(path, entry, artifact); err != nil {
+		verboseLog("saveArtifact: failed to update %s: %v", manifestFileName, err)
+	}
+}
+
+// writeArtifactFile writes data to path under the conflict policy and records
+// the result in the directory's artifacts.json manifest.
+func writeArtifactFile(path, key string, data []byte, entry manifestEntry, artifact a2a.Artifact) (string, error) {
+	final, err := claimArtifactPath(path, key)
+	if err != nil {
+		return final, err
+	}
+	if err := os.MkdirAll(filepath.Dir(final), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(final, data, 0644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	recordArtifactFile(final, int64(len(data)), hex.EncodeToString(sum[:]), entry, artifact)
+	return final, nil
+}
+
+// downloadArtifactFile streams a URL part to path under the conflict policy
+// and records it in the manifest. If the download fails, the reservation is
+// released so the caller can fall back to printing the URL.
+func downloadArtifactFile(rawURL, path, key string, entry manifestEntry, artifact a2a.Artifact) (string, error) {
+	final, err := claimArtifactPath(path, key)
+	if err != nil {
+		return final, err
+	}
+	ctx := context.Background()
+	if requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+	res, err := downloadToFile(ctx, rawURL, final)
+	if err != nil {
+		if key != "" {
+			artifactMu.Lock()
+			delete(sessionPaths, key)
+			artifactMu.Unlock()
+		}
+		return "", fmt.Errorf("%w: %w", errDownloadFailed, err)
+	}
+	recordArtifactFile(final, res.Size, res.SHA256, entry, artifact)
+	return final, nil
+}
+
+// recordManifest adds or replaces the entry for path in the manifest stored
+// next to it, and lists the file under its artifact's group. Must be called
+// with artifactMu held.
+func recordManifest(path string, entry manifestEntry, artifact a2a.Artifact) error {
+	dir := filepath.Dir(path)
+	entry.Path = filepath.Base(path)
+	manifestPath := filepath.Join(dir, manifestFileName)
+
+	var m artifactManifest
+	if b, err := os.ReadFile(manifestPath); err == nil {
+		if err := json.Unmarshal(b, &m); err != nil {
+			verboseLog("ignoring malformed %s: %v", manifestPath, err)
+			m = artifactManifest{}
+		}
+	}
+	replaced := false
+	for i := range m.Files {
+		if m.Files[i].Path == entry.Path {
+			m.Files[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		m.Files = append(m.Files, entry)
+	}
+	if artifact.ID != "" {
+		m.addToGroup(artifact, entry.Path)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, b, 0644)
+}
+
+// addToGroup records file under the artifact's group, creating it if needed.
+func (m *artifactManifest) addToGroup(artifact a2a.Artifact, file string) {
+	for i := range m.Artifacts {
+		g := &m.Artifacts[i]
+		if g.ID != string(artifact.ID) {
+			continue
+		}
+		if !slices.Contains(g.Files, file) {
+			g.Files = append(g.Files, file)
+		}
+		return
+	}
+	m.Artifacts = append(m.Artifacts, manifestArtifact{
+		ID:          string(artifact.ID),
+		Name:        artifact.Name,
+		Description: artifact.Description,
+		Files:       []string{file},
+	})
+}
+
+// savedPart is the outcome of saving one part of an artifact.
+type savedPart struct {
+	// Path is the local file written, or the remote URL when a URL part was
+	// not downloaded (no --out-dir, or the download failed; Err then holds
+	// the reason).
+	Path    string
+	IsURL   bool
+	Skipped bool
+	Err     error
+}
+
+// partExt picks a file extension for a part from its media type, falling back
+// to the extension of its filename and then to def.
+func partExt(p *a2a.Part, def string) string {
+	if p.MediaType != "" {
+		return mimeToExt(p.MediaType)
+	}
+	if e := filepath.Ext(p.Filename); e != "" {
+		return e
+	}
+	return def
+}
+
+// partManifestEntry returns the session key and manifest entry for part i of
+// artifact.
+func partManifestEntry(artifact a2a.Artifact, p *a2a.Part, i int, mediaType string) (string, manifestEntry) {
+	originalName := p.Filename
+	if originalName == "" {
+		originalName = artifact.Name
+	}
+	key := ""
+	if artifact.ID != "" {
+		key = fmt.Sprintf("%s#%d", artifact.ID, i)
+	}
+	return key, manifestEntry{
+		Name:         originalName,
+		MediaType:    mediaType,
+		ArtifactID:   string(artifact.ID),
+		ArtifactName: artifact.Name,
+		Part:         &i,
+	}
+}
+
+// saveArtifact writes every part of artifact to disk. A single-part artifact
+// is saved under the artifact name (or --file); each part of a multi-part
+// artifact gets its own file, named after Part.Filename when present and
+// <artifact>-part<N> otherwise. The grouping is recorded in artifacts.json.
+func saveArtifact(outDir, outFile string, artifact a2a.Artifact, index int) ([]savedPart, error) {
+	multi := len(artifact.Parts) > 1
+
+	// Determine the target path for a part once its extension is known.
+	// --file is user-supplied and used verbatim; artifact and part names come
+	// from the agent and are sanitised and confined to the output directory.
+	basePath := func(partIdx int, ext, partFilename string) (string, error) {
+		if outFile != "" {
+			fName := outFile
+			e := filepath.Ext(outFile)
+			base := strings.TrimSuffix(outFile, e)
+			if index > 0 {
+				base = fmt.Sprintf("%s_%d", base, index)
+			}
+			if multi {
+				if ext != "" {
+					e = ext
+				}
+				base = fmt.Sprintf("%s-part%d", base, partIdx)
+			}
+			fName = base + e
+			if outDir != "" {
+				return filepath.Join(outDir, fName), nil
+			}
+			return fName, nil
+		}
+		dir := outDir
+		if dir == "" {
+			dir = "."
+		}
+		artifactName := sanitizeFileName(artifact.Name)
+		var name string
+		switch {
+		case multi && sanitizeFileName(partFilename) != "":
+			name = sanitizeFileName(partFilename)
+		case multi:
+			base := strings.TrimSuffix(artifactName, filepath.Ext(artifactName))
+			if base == "" {
+				base = fmt.Sprintf("artifact_%d_%d", time.Now().Unix(), index)
+			}
+			name = fmt.Sprintf("%s-part%d", base, partIdx)
+		default:
+			name = artifactName
+			if name == "" {
+				name = sanitizeFileName(partFilename)
+			}
+			if name == "" {
+				name = fmt.Sprintf("artifact_%d_%d", time.Now().Unix(), index)
+			}
+		}
+		// Append ext if not already present.
+		if ext != "" && !strings.HasSuffix(strings.ToLower(name), strings.ToLower(ext)) {
+			name += ext
+		}
+		return confinedPath(dir, name)
+	}
+
+	var results []savedPart
+	for i, p := range artifact.Parts {
+		var (
+			contentBytes []byte
+			mediaType    = p.MediaType
+			ext          string
+		)
+
+		switch v := p.Content.(type) {
+		case a2a.Text:
+			contentBytes = []byte(string(v))
+			if multi {
+				ext = partExt(p, ".txt")
+			}
+
+		case a2a.Data:
+			prettyJSON, _ := json.MarshalIndent(v.Value, "", "  ")
+			contentBytes = prettyJSON
+			ext = ".json"
+			if p.MediaType != "" {
+				ext = mimeToExt(p.MediaType)
+			} else {
+				mediaType = "application/json"
+			}
+
+		case a2a.Raw:
+			contentBytes = []byte(v)
+			ext = ".bin"
+			if e := filepath.Ext(p.Filename); e != "" {
+				ext = e
+			}
+			if p.MediaType != "" {
+				ext = mimeToExt(p.MediaType)
+			}
+			verboseLog("saveArtifact: Raw part %d bytes mediaType=%q ext=%s", len(contentBytes), p.MediaType, ext)
+
+		case a2a.URL:
+			rawURL := string(v)
+			verboseLog("saveArtifact: URL part %s mediaType=%q", rawURL, p.MediaType)
+			if outDir == "" && outFile == "" {
+				results = append(results, savedPart{Path: rawURL, IsURL: true})
+				continue
+			}
+			path, err := basePath(i, partExt(p, ".bin"), p.Filename)
+			if err != nil {
+				results = append(results, savedPart{Err: err})
+				continue
+			}
+			key, entry := partManifestEntry(artifact, p, i, p.MediaType)
+			final, err := downloadArtifactFile(rawURL, path, key, entry, artifact)
+			switch {
+			case errors.Is(err, errArtifactSkipped):
+				results = append(results, savedPart{Path: final, Skipped: true, Err: err})
+			case errors.Is(err, errDownloadFailed):
+				verboseLog("saveArtifact: %v — printing URL instead", err)
+				results = append(results, savedPart{Path: rawURL, IsURL: true, Err: err})
+			case err != nil:
+				results = append(results, savedPart{Err: err})
+			default:
+				results = append(results, savedPart{Path: final})
+			}
+			continue
+
+		default:
+			continue
+		}
+
+		if len(contentBytes) == 0 {
+			continue
+		}
+		path, err := basePath(i, ext, p.Filename)
+		if err != nil {
+			results = append(results, savedPart{Err: err})
+			continue
+		}
+
+		key, entry := partManifestEntry(artifact, p, i, mediaType)
+		final, err := writeArtifactFile(path, key, contentBytes, entry, artifact)
+		results = append(results, savedPart{
+			Path:    final,
+			Skipped: errors.Is(err, errArtifactSkipped),
+			Err:     err,
+		})
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no saveable content in artifact")
+	}
+	return results, nil
+}
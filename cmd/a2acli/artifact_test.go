@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"report.txt", "report.txt"},
+		{"../../.bashrc", "bashrc"},
+		{"/etc/passwd", "passwd"},
+		{`..\..\windows\system32`, "system32"},
+		{"a:b*c?.txt", "a_b_c_.txt"},
+		{"..", ""},
+		{"", ""},
+		{"artifacts.json", "artifact-artifacts.json"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFileName(tt.in); got != tt.want {
+			t.Errorf("sanitizeFileName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSaveArtifactConfinesToOutDir(t *testing.T) {
+	dir := t.TempDir()
+	art := a2a.Artifact{
+		ID:    "evil",
+		Name:  "../../escape.txt",
+		Parts: []*a2a.Part{a2a.NewTextPart("pwned")},
+	}
+	saved, err := saveArtifact(dir, "", art, 0)
+	if err != nil || len(saved) != 1 || saved[0].Err != nil {
+		t.Fatalf("saveArtifact failed: %v %+v", err, saved)
+	}
+	if filepath.Dir(saved[0].Path) != dir {
+		t.Errorf("artifact escaped out dir: %s", saved[0].Path)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "..", "escape.txt")); err == nil {
+		t.Errorf("file written outside out dir")
+	}
+}
+
+func TestSaveArtifactConflictPolicies(t *testing.T) {
+	origPolicy := onConflict
+	defer func() { onConflict = origPolicy }()
+
+	save := func(dir, id string) (string, error) {
+		saved, err := saveArtifact(dir, "", a2a.Artifact{
+			ID:    a2a.ArtifactID(id),
+			Name:  "out.txt",
+			Parts: []*a2a.Part{a2a.NewTextPart("content " + id)},
+		}, 0)
+		if err != nil {
+			return "", err
+		}
+		return saved[0].Path, saved[0].Err
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		onConflict = conflictRename
+		dir := t.TempDir()
+		first, _ := save(dir, "r1")
+		second, err := save(dir, "r2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if first == second || !strings.HasSuffix(second, "out-1.txt") {
+			t.Errorf("expected renamed file, got %s and %s", first, second)
+		}
+	})
+
+	t.Run("same artifact reuses its path", func(t *testing.T) {
+		onConflict = conflictFail
+		dir := t.TempDir()
+		first, _ := save(dir, "same")
+		second, err := save(dir, "same")
+		if err != nil || first != second {
+			t.Errorf("expected streaming update to overwrite %s, got %s (err %v)", first, second, err)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		onConflict = conflictSkip
+		dir := t.TempDir()
+		_, _ = save(dir, "s1")
+		_, err := save(dir, "s2")
+		if !errors.Is(err, errArtifactSkipped) {
+			t.Errorf("expected errArtifactSkipped, got %v", err)
+		}
+		b, _ := os.ReadFile(filepath.Join(dir, "out.txt"))
+		if string(b) != "content s1" {
+			t.Errorf("skip policy modified existing file: %q", b)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		onConflict = conflictFail
+		dir := t.TempDir()
+		_, _ = save(dir, "f1")
+		if _, err := save(dir, "f2"); err == nil {
+			t.Errorf("expected error with --on-conflict=fail")
+		}
+	})
+}
+
+func TestSaveArtifactWritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	saved, err := saveArtifact(dir, "", a2a.Artifact{
+		ID:    "m1",
+		Name:  "data",
+		Parts: []*a2a.Part{a2a.NewDataPart(map[string]any{"k": "v"})},
+	}, 0)
+	if err != nil {
+		t.Fatalf("saveArtifact failed: %v", err)
+	}
+	path := saved[0].Path
+
+	b, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var m artifactManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if len(m.Files) != 1 {
+		t.Fatalf("expected 1 manifest entry, got %d", len(m.Files))
+	}
+	e := m.Files[0]
+	if e.Name != "data" || e.Path != filepath.Base(path) || e.MediaType != "application/json" {
+		t.Errorf("unexpected manifest entry: %+v", e)
+	}
+	if e.Size == 0 || len(e.SHA256) != 64 {
+		t.Errorf("expected size and sha256 in manifest entry: %+v", e)
+	}
+}
+
+func TestSaveArtifactMultiPart(t *testing.T) {
+	dir := t.TempDir()
+	png := a2a.NewRawPart([]byte{0x89, 'P', 'N', 'G'})
+	png.MediaType = "image/png"
+	named := a2a.NewRawPart([]byte("a,b\n1,2\n"))
+	named.Filename = "../table.csv"
+
+	saved, err := saveArtifact(dir, "", a2a.Artifact{
+		ID:    "multi",
+		Name:  "report",
+		Parts: []*a2a.Part{a2a.NewTextPart("summary"), png, a2a.NewDataPart(map[string]any{"n": 1}), named},
+	}, 0)
+	if err != nil {
+		t.Fatalf("saveArtifact failed: %v", err)
+	}
+
+	want := []string{"report-part0.txt", "report-part1.png", "report-part2.json", "table.csv"}
+	if len(saved) != len(want) {
+		t.Fatalf("expected %d files, got %+v", len(want), saved)
+	}
+	for i, sp := range saved {
+		if sp.Err != nil || filepath.Base(sp.Path) != want[i] {
+			t.Errorf("part %d: got %s (err %v), want %s", i, sp.Path, sp.Err, want[i])
+		}
+		if _, err := os.Stat(sp.Path); err != nil {
+			t.Errorf("part %d not written: %v", i, err)
+		}
+	}
+
+	b, _ := os.ReadFile(filepath.Join(dir, manifestFileName))
+	var m artifactManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if len(m.Artifacts) != 1 || len(m.Artifacts[0].Files) != 4 {
+		t.Errorf("expected one artifact group with 4 files, got %+v", m.Artifacts)
+	}
+}
@@ -15,11 +15,16 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/a2aproject/a2a-go/v2/a2a"
@@ -30,6 +35,13 @@ import (
 var (
 	authClientID     string
 	authClientSecret string
+	authClientAuth   string
+	authAudience     string
+	authResource     string
+	authDevice       bool
+	authRedirectURI  string
+	authScopes       []string
+	authLocalOnly    bool
 )
 
 // setupAuthCmd builds the `auth` command group.
@@ -40,7 +52,9 @@ func setupAuthCmd() *cobra.Command {
 		Short:   "Manage OAuth 2.1 authentication for A2A services",
 		Long: `Obtain, inspect, and revoke OAuth 2.1 tokens for A2A services that
 require authentication. Tokens are stored in ~/.config/a2acli/tokens/ (0600)
-and used automatically by send/discover when present.
+and used automatically by send/discover when present. Set token_store in
+config.yaml (globally or per environment) to keep them in the desktop keyring
+(secret-service) or an external credential helper (helper:<name>) instead.
 
 Client identity: a2acli uses the CIMD pattern — its client_id is the URL of its
 own metadata document (` + oauth.CIMDURL + `).`,
@@ -53,19 +67,49 @@ own metadata document (` + oauth.CIMDURL + `).`,
 		Long: `Drive an OAuth 2.1 auth-code + PKCE flow against the service's
 advertised authorization server. Opens a browser for the user to authenticate.
 
-The service's AgentCard must advertise an OAuth2SecurityScheme with an
-authorization URL and token endpoint. Use --client-id/--client-secret for
-non-interactive client credentials flow instead.
+The service's AgentCard must advertise an OAuth2SecurityScheme or an
+OpenIDConnectSecurityScheme. Endpoints missing from the card's inline flow
+are discovered from the scheme's oauth2MetadataUrl (RFC 8414) or
+openIdConnectUrl (OpenID Connect Discovery), which also supply the
+revocation endpoint and the PKCE methods the server supports (S256 is
+preferred). OpenID Connect logins request the openid scope and validate the
+returned ID token's signature (against the provider's JWKS), issuer,
+audience, expiry and nonce before the token is stored. Use --client-id/--client-secret for
+non-interactive client credentials flow instead (service accounts, CI jobs).
+The secret may also be supplied via A2ACLI_CLIENT_SECRET to keep it out of
+the process list. Scopes are taken from the card's clientCredentials flow,
+and the token is re-minted automatically when it expires.
 
 Callback server binds to ` + oauth.RedirectURI + ` (pre-registered in the
-mithlond consent SPA). Port 8080 must be free.`,
+mithlond consent SPA). Port 8080 must be free unless the environment configures
+its own OAuth client under envs.<name>.oauth (client_id, client_secret,
+auth_method, redirect_uri, scopes, audience, auth_params). A loopback
+redirect_uri with port 0, e.g. http://127.0.0.1:0/callback, binds any free
+port (RFC 8252). A confidential client sends its secret with HTTP Basic
+unless auth_method or --client-auth is post; refreshes and revocation use
+the same method.
+
+On machines without a browser or a free port 8080 (SSH sessions, containers),
+use --device for the OAuth device authorization grant (RFC 8628): a2acli
+prints a verification URL and a short code to enter on any other device, then
+waits for the approval. The card must advertise a deviceCode flow.`,
 		Example: `  a2acli auth login --service-url https://eldamo.mithlond.com
-  a2acli auth login -u https://eldamo.mithlond.com --client-id myid --client-secret mysecret`,
+  a2acli auth login -u https://eldamo.mithlond.com --client-id myid --client-secret mysecret
+  a2acli auth login -u https://agent.example.com --device
+  a2acli auth login -u https://agent.example.com --scopes reports.write
+  a2acli auth login --env corp --redirect-uri http://127.0.0.1:0/callback
+  A2ACLI_CLIENT_SECRET=mysecret a2acli auth login -u https://agent.example.com --client-id ci-bot --audience https://agent.example.com`,
 		Args: cobra.NoArgs,
 		Run:  runAuthLogin,
 	}
 	loginCmd.Flags().StringVar(&authClientID, "client-id", "", "Client ID for client credentials flow (non-interactive)")
-	loginCmd.Flags().StringVar(&authClientSecret, "client-secret", "", "Client secret for client credentials flow")
+	loginCmd.Flags().StringVar(&authRedirectURI, "redirect-uri", "", "Loopback redirect URI for the callback server; use port 0 for an ephemeral port (overrides envs.<name>.oauth.redirect_uri)")
+	loginCmd.Flags().BoolVar(&authDevice, "device", false, "Use the device authorization grant (no browser or callback port needed)")
+	loginCmd.Flags().StringVar(&authClientSecret, "client-secret", "", "Client secret for client credentials flow (or A2ACLI_CLIENT_SECRET)")
+	loginCmd.Flags().StringVar(&authClientAuth, "client-auth", "", "How to send the client secret to the token endpoint: basic (HTTP Basic, the default) or post (form body) (overrides envs.<name>.oauth.auth_method)")
+	loginCmd.Flags().StringVar(&authAudience, "audience", "", "Audience parameter for the client credentials token request")
+	loginCmd.Flags().StringSliceVar(&authScopes, "scopes", nil, "Additional scopes to request; scopes already granted are kept (incremental authorization)")
+	loginCmd.Flags().StringVar(&authResource, "resource", "", "Resource indicator (RFC 8707) for the client credentials token request")
 
 	// status
 	statusCmd := &cobra.Command{
@@ -78,12 +122,18 @@ mithlond consent SPA). Port 8080 must be free.`,
 
 	// logout
 	logoutCmd := &cobra.Command{
-		Use:     "logout",
-		Short:   "Delete the stored token for a service",
-		Example: `  a2acli auth logout --service-url https://eldamo.mithlond.com`,
-		Args:    cobra.NoArgs,
-		Run:     runAuthLogout,
+		Use:   "logout",
+		Short: "Revoke and delete the stored token for a service",
+		Long: `Revoke the stored refresh and access tokens at the authorization server's
+revocation endpoint (RFC 7009), then delete them locally. The endpoint comes
+from the metadata discovered at login, or from the token issuer's metadata.
+If revocation fails the token is still deleted locally and a warning is shown.`,
+		Example: `  a2acli auth logout --service-url https://eldamo.mithlond.com
+  a2acli auth logout -u https://agent.example.com --local`,
+		Args: cobra.NoArgs,
+		Run:  runAuthLogout,
 	}
+	logoutCmd.Flags().BoolVar(&authLocalOnly, "local", false, "Only delete the local copy; do not contact the revocation endpoint")
 
 	// token (print raw JWT — for scripting, equivalent to make token)
 	tokenCmd := &cobra.Command{
@@ -98,35 +148,393 @@ Exits non-zero if no valid token is stored.`,
 		Run: runAuthToken,
 	}
 
-	authCmd.AddCommand(loginCmd, statusCmd, logoutCmd, tokenCmd)
+	// list
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List every stored credential with its scope and expiry",
+		Long: `List all credentials in the active token store. Each credential is bound to
+the exact service URL (scheme, host, port and path) and to the issuer and
+audience it was obtained from, so agents sharing a host never share a token.
+
+Entries marked "legacy" were stored by older releases keyed by host only; each
+is migrated to the first service URL on that host that uses it. Entries with
+grant "token_command" are cached output of an environment's token_command.`,
+		Example: `  a2acli auth list
+  a2acli auth list --output json`,
+		Args: cobra.NoArgs,
+		Run:  runAuthList,
+	}
+
+	authCmd.AddCommand(loginCmd, statusCmd, logoutCmd, tokenCmd, listCmd)
 	return authCmd
 }
 
-// oauthSchemeFromCard extracts the first OAuth2SecurityScheme from an AgentCard.
-// Returns nil if none is present.
-func oauthSchemeFromCard(card *a2a.AgentCard) *a2a.OAuth2SecurityScheme {
-	for _, scheme := range card.SecuritySchemes {
-		if s, ok := scheme.(a2a.OAuth2SecurityScheme); ok {
-			return &s
-		}
+// OAuth grants a2acli can drive, used to pick the matching flow from a card.
+const (
+	grantAuthorizationCode = "authorization_code"
+	grantClientCredentials = oauth.GrantClientCredentials
+	grantDeviceCode        = oauth.GrantDeviceCode
+)
+
+// loginEndpoints are the authorization server endpoints for one grant. They
+// come from the card's inline OAuth flow and are completed from RFC 8414
+// authorization server metadata (oauth2MetadataUrl) or OpenID Connect
+// discovery (openIdConnectUrl).
+type loginEndpoints struct {
+	Scheme        string
+	AuthURL       string
+	TokenURL      string
+	RefreshURL    string
+	DeviceURL     string
+	RevocationURL string
+	Issuer        string
+	JWKSURI       string
+	IDTokenAlgs   []string
+	PKCEMethod    string
+	Scopes        []string
+	// OIDC is set for OpenIDConnectSecurityScheme logins, which request the
+	// openid scope and validate the returned ID token.
+	OIDC bool
+}
+
+// complete reports whether ep has every endpoint grant needs.
+func (ep *loginEndpoints) complete(grant string) bool {
+	switch grant {
+	case grantAuthorizationCode:
+		return ep.AuthURL != "" && ep.TokenURL != ""
+	case grantDeviceCode:
+		return ep.DeviceURL != "" && ep.TokenURL != ""
+	}
+	return ep.TokenURL != ""
+}
+
+// merge fills endpoints the card left out from discovery metadata. Inline
+// card values win; the PKCE method and revocation/JWKS endpoints only exist
+// in metadata.
+func (ep *loginEndpoints) merge(md *oauth.Metadata) error {
+	if ep.AuthURL == "" {
+		ep.AuthURL = md.AuthorizationEndpoint
+	}
+	if ep.TokenURL == "" {
+		ep.TokenURL = md.TokenEndpoint
+	}
+	if ep.DeviceURL == "" {
+		ep.DeviceURL = md.DeviceAuthorizationEndpoint
 	}
+	ep.RevocationURL = md.RevocationEndpoint
+	ep.Issuer = md.Issuer
+	ep.JWKSURI = md.JWKSURI
+	ep.IDTokenAlgs = md.IDTokenSigningAlgValuesSupported
+	method, err := md.SelectPKCEMethod()
+	if err != nil {
+		return err
+	}
+	ep.PKCEMethod = method
 	return nil
 }
 
-// authURLsFromScheme extracts (authorizationURL, tokenURL) from an OAuth2 scheme.
-func authURLsFromScheme(s *a2a.OAuth2SecurityScheme) (authURL, tokenURL string) {
-	if s == nil {
-		return "", ""
+// flowScopes returns the sorted scope names of an OAuth flow's scope map.
+func flowScopes(m map[string]string) []string {
+	scopes := make([]string, 0, len(m))
+	for scope := range m {
+		scopes = append(scopes, scope)
+	}
+	slices.Sort(scopes)
+	return scopes
+}
+
+// resolveLoginEndpoints finds the endpoints for grant. A scheme whose inline
+// flow matches the grant is preferred; otherwise any OAuth2 scheme with a
+// metadata URL, or an OpenID Connect scheme, whose discovered metadata
+// supports the grant is used. Schemes are visited in name order so the
+// choice is stable.
+func resolveLoginEndpoints(ctx context.Context, card *a2a.AgentCard, grant string) (*loginEndpoints, error) {
+	names := make([]string, 0, len(card.SecuritySchemes))
+	for name := range card.SecuritySchemes {
+		names = append(names, string(name))
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		s, ok := card.SecuritySchemes[a2a.SecuritySchemeName(name)].(a2a.OAuth2SecurityScheme)
+		if !ok {
+			continue
+		}
+		ep := &loginEndpoints{Scheme: name, PKCEMethod: oauth.PKCEMethodS256}
+		switch f := s.Flows.(type) {
+		case a2a.AuthorizationCodeOAuthFlow:
+			if grant != grantAuthorizationCode {
+				continue
+			}
+			ep.AuthURL, ep.TokenURL, ep.RefreshURL, ep.Scopes = f.AuthorizationURL, f.TokenURL, f.RefreshURL, flowScopes(f.Scopes)
+		case a2a.ClientCredentialsOAuthFlow:
+			if grant != grantClientCredentials {
+				continue
+			}
+			ep.TokenURL, ep.RefreshURL, ep.Scopes = f.TokenURL, f.RefreshURL, flowScopes(f.Scopes)
+		case a2a.DeviceCodeOAuthFlow:
+			if grant != grantDeviceCode {
+				continue
+			}
+			ep.DeviceURL, ep.TokenURL, ep.RefreshURL, ep.Scopes = f.DeviceAuthorizationURL, f.TokenURL, f.RefreshURL, flowScopes(f.Scopes)
+		default:
+			continue
+		}
+		if s.Oauth2MetadataURL != "" {
+			md, err := oauth.FetchMetadata(ctx, s.Oauth2MetadataURL)
+			switch {
+			case err == nil:
+				if err := ep.merge(md); err != nil {
+					return nil, fmt.Errorf("scheme %s: %w", name, err)
+				}
+			case ep.complete(grant):
+				verboseLog("scheme %s: metadata %s unavailable, using inline flow: %v", name, s.Oauth2MetadataURL, err)
+			default:
+				return nil, fmt.Errorf("scheme %s: %w", name, err)
+			}
+		}
+		if ep.complete(grant) {
+			return ep, nil
+		}
+	}
+
+	// Fall back to schemes that only advertise a discovery document.
+	var errs []error
+	for _, name := range names {
+		ep := &loginEndpoints{Scheme: name}
+		var discoveryURL string
+		switch s := card.SecuritySchemes[a2a.SecuritySchemeName(name)].(type) {
+		case a2a.OAuth2SecurityScheme:
+			discoveryURL = s.Oauth2MetadataURL
+		case a2a.OpenIDConnectSecurityScheme:
+			discoveryURL = s.OpenIDConnectURL
+			ep.OIDC = true
+			ep.Scopes = []string{"openid"}
+		}
+		if discoveryURL == "" {
+			continue
+		}
+		md, err := oauth.FetchMetadata(ctx, discoveryURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scheme %s: %w", name, err))
+			continue
+		}
+		if len(md.GrantTypesSupported) > 0 && !slices.Contains(md.GrantTypesSupported, grant) {
+			continue
+		}
+		if err := ep.merge(md); err != nil {
+			errs = append(errs, fmt.Errorf("scheme %s: %w", name, err))
+			continue
+		}
+		if ep.complete(grant) {
+			return ep, nil
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nil, fmt.Errorf("no OAuth2 or OpenID Connect scheme in the AgentCard supports the %s grant", grant)
+}
+
+// withScope returns scopes with scope added if it is missing.
+func withScope(scopes []string, scope string) []string {
+	if slices.Contains(scopes, scope) {
+		return scopes
+	}
+	return append([]string{scope}, scopes...)
+}
+
+// withRequestedScopes applies --scopes. The extra scopes are added to base
+// and to the scopes the stored token already holds, so authorizing one skill
+// does not drop the scopes another skill relies on.
+func withRequestedScopes(base []string) []string {
+	if len(authScopes) == 0 {
+		return base
+	}
+	scopes := slices.Clone(base)
+	if tok, err := oauth.LoadToken(serviceURL); err == nil && tok != nil {
+		scopes = append(scopes, strings.Fields(tok.Scope)...)
+	}
+	scopes = append(scopes, authScopes...)
+	slices.Sort(scopes)
+	return slices.Compact(scopes)
+}
+
+// missingSkillScopes returns the OAuth scopes skill requires that are not in
+// granted. A skill's SecurityRequirements are alternatives (OR) of schemes
+// that apply together (AND); only OAuth2 and OpenID Connect schemes carry
+// scopes. It returns nil when an alternative is already satisfied, and
+// otherwise the missing scopes of the alternative closest to being met.
+func missingSkillScopes(card *a2a.AgentCard, skillID string, granted []string) []string {
+	var skill *a2a.AgentSkill
+	for i := range card.Skills {
+		if card.Skills[i].ID == skillID {
+			skill = &card.Skills[i]
+			break
+		}
+	}
+	if skill == nil || len(skill.SecurityRequirements) == 0 {
+		return nil
+	}
+
+	var best []string
+	for i, req := range skill.SecurityRequirements {
+		var missing []string
+		for name, scopes := range req {
+			switch card.SecuritySchemes[name].(type) {
+			case a2a.OAuth2SecurityScheme, a2a.OpenIDConnectSecurityScheme:
+			default:
+				continue
+			}
+			for _, scope := range scopes {
+				if !slices.Contains(granted, scope) && !slices.Contains(missing, scope) {
+					missing = append(missing, scope)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		if i == 0 || len(missing) < len(best) {
+			best = missing
+		}
+	}
+	slices.Sort(best)
+	return best
+}
+
+// ensureSkillScopes checks, before 'send --skill', that the stored token was
+// granted the scopes the skill requires. When scopes are missing it offers an
+// incremental 'auth login --scopes' on an interactive terminal and otherwise
+// fails with the exact command to run, instead of a generic 401 later.
+func ensureSkillScopes(ctx context.Context, card *a2a.AgentCard) {
+	if skillID == "" || authToken != "" || commandTokens != nil {
+		return
+	}
+	tok, err := oauth.LoadValidToken(ctx, serviceURL)
+	if err != nil || tok == nil || tok.Scope == "" || tok.GrantType == oauth.GrantStatic {
+		return
+	}
+	missing := missingSkillScopes(card, skillID, strings.Fields(tok.Scope))
+	if len(missing) == 0 {
+		return
+	}
+	verboseLog("skill %s requires scopes %v not granted (have %q)", skillID, missing, tok.Scope)
+
+	login := fmt.Sprintf("a2acli auth login -u %s --scopes %s", serviceURL, strings.Join(missing, ","))
+	switch tok.GrantType {
+	case oauth.GrantClientCredentials:
+		login = fmt.Sprintf("a2acli auth login -u %s --client-id %s --scopes %s", serviceURL, tok.ClientID, strings.Join(missing, ","))
+	case oauth.GrantDeviceCode:
+		login += " --device"
+	}
+
+	if disableTUI || !isTTY() || isStdinPiped() {
+		fatalCode(ErrCodeUnauthenticated, fmt.Sprintf("stored token lacks scopes required by skill %q", skillID),
+			fmt.Errorf("missing scopes: %s", strings.Join(missing, " ")), "Run: "+login)
+	}
+
+	fmt.Fprintf(os.Stderr, "Skill %s requires scopes your token was not granted: %s\n",
+		StyleAccent.Render(skillID), StyleWarn.Render(strings.Join(missing, " ")))
+	fmt.Fprintf(os.Stderr, "Run %s now? [Y/n] ", StyleCommand.Render(login))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+		fatalCode(ErrCodeUnauthenticated, fmt.Sprintf("stored token lacks scopes required by skill %q", skillID),
+			fmt.Errorf("missing scopes: %s", strings.Join(missing, " ")), "Run: "+login)
+	}
+
+	authScopes = missing
+	if tok.AuthMethod != "" {
+		authClientAuth = tok.AuthMethod
+	}
+	switch tok.GrantType {
+	case oauth.GrantClientCredentials:
+		authClientID, authClientSecret = tok.ClientID, tok.ClientSecret
+		authAudience, authResource = tok.Audience, tok.Resource
+	case oauth.GrantDeviceCode:
+		authDevice = true
+	}
+	runAuthLogin(nil, nil)
+}
+
+// validateIDToken checks an ID token returned to an OpenID Connect login and
+// returns its subject. Plain OAuth logins ignore ID tokens.
+func validateIDToken(ctx context.Context, ep *loginEndpoints, tok *oauth.TokenResponse, clientID, nonce string) string {
+	if !ep.OIDC {
+		return ""
+	}
+	if tok.IDToken == "" {
+		fatalCode(ErrCodeUnauthenticated, "OpenID provider returned no id_token", fmt.Errorf("issuer=%s", ep.Issuer),
+			"Check that the client is allowed the openid scope")
 	}
-	switch f := s.Flows.(type) {
-	case a2a.AuthorizationCodeOAuthFlow:
-		return f.AuthorizationURL, f.TokenURL
-	case a2a.ClientCredentialsOAuthFlow:
-		return "", f.TokenURL
-	case a2a.DeviceCodeOAuthFlow:
-		return f.DeviceAuthorizationURL, f.TokenURL
+	claims, err := oauth.ValidateIDToken(ctx, tok.IDToken, oauth.IDTokenCheck{
+		Issuer:     ep.Issuer,
+		ClientID:   clientID,
+		Nonce:      nonce,
+		JWKSURI:    ep.JWKSURI,
+		Algorithms: ep.IDTokenAlgs,
+	})
+	if err != nil {
+		fatalCode(ErrCodeUnauthenticated, "ID token validation failed", err,
+			"The token was not stored; check the provider's issuer and JWKS configuration")
 	}
-	return "", ""
+	verboseLog("id_token valid: sub=%s iss=%s", claims.Subject, claims.Issuer)
+	return claims.Subject
+}
+
+// tokenStoreHint names the active token store in save failures.
+func tokenStoreHint() string {
+	if name := oauth.CurrentTokenStore().Name(); name != "file" {
+		return "Check that the " + name + " token store is available and unlocked"
+	}
+	return "Check ~/.config/a2acli/tokens/ permissions"
+}
+
+// grantedScope returns the scope a token response granted. The server may
+// omit it when it granted exactly the requested scopes (RFC 6749 §5.1).
+func grantedScope(tok *oauth.TokenResponse, requested []string) string {
+	if tok.Scope != "" {
+		return tok.Scope
+	}
+	return strings.Join(requested, " ")
+}
+
+// clientSecret returns the secret of clientID: --client-secret,
+// A2ACLI_CLIENT_SECRET, or the environment's oauth.client_secret when
+// clientID is the environment's client.
+func clientSecret(clientID string) string {
+	// a2acli's own client is public.
+	if clientID == "" {
+		return ""
+	}
+	if authClientSecret != "" {
+		return authClientSecret
+	}
+	if s := os.Getenv("A2ACLI_CLIENT_SECRET"); s != "" {
+		return s
+	}
+	if clientID == oauthConfig.ClientID {
+		return oauthConfig.ClientSecret
+	}
+	return ""
+}
+
+// clientAuthMethod returns how a confidential client authenticates to the
+// token endpoint: --client-auth when given, otherwise the environment's
+// oauth.auth_method, otherwise HTTP Basic.
+func clientAuthMethod() string {
+	name, source := authClientAuth, "--client-auth"
+	if name == "" {
+		name, source = oauthConfig.AuthMethod, "oauth.auth_method"
+	}
+	switch name {
+	case "", "basic", oauth.ClientAuthBasic:
+		return oauth.ClientAuthBasic
+	case "post", oauth.ClientAuthPost:
+		return oauth.ClientAuthPost
+	}
+	fatalCode(ErrCodeInvalidArgument, "invalid "+source, fmt.Errorf("%q", name), "Use basic or post")
+	return ""
 }
 
 // newState generates a random CSRF state token.
@@ -146,33 +554,26 @@ func runAuthLogin(_ *cobra.Command, _ []string) {
 		fatalf("failed to resolve AgentCard", err, "Check --service-url or A2ACLI_SERVICE_URL")
 	}
 
-	scheme := oauthSchemeFromCard(card)
-	if scheme == nil {
-		fatalf("no OAuth2 security scheme in AgentCard", fmt.Errorf("serviceURL=%s", serviceURL),
-			"Run 'a2acli discover' to inspect the card's security schemes")
-	}
-
-	authURL, tokenURL := authURLsFromScheme(scheme)
-	if tokenURL == "" {
-		fatalf("no token URL in OAuth2 scheme", fmt.Errorf("flows=%T", scheme.Flows),
-			"The AgentCard's OAuth2SecurityScheme must include a token endpoint")
+	// Device authorization grant (headless).
+	if authDevice {
+		runDeviceLogin(ctx, card)
+		return
 	}
 
-	verboseLog("oauth2: authURL=%s tokenURL=%s", authURL, tokenURL)
-
 	// Client credentials flow (non-interactive).
 	if authClientID != "" {
-		runClientCredentials(ctx, tokenURL)
+		runClientCredentials(ctx, card)
 		return
 	}
 
-	// Auth-code + PKCE flow requires an authorization URL.
-	if authURL == "" {
-		fatalf("no authorization URL in OAuth2 scheme", fmt.Errorf("flows=%T", scheme.Flows),
-			"Use --client-id/--client-secret for client credentials flow, or check the AgentCard")
+	ep, err := resolveLoginEndpoints(ctx, card, grantAuthorizationCode)
+	if err != nil {
+		fatalCode(ErrCodeFailedPrecondition, "no usable OAuth2 authorization-code flow in AgentCard", err,
+			"Run 'a2acli discover' to inspect the card's security schemes, or use --client-id/--client-secret or --device")
 	}
+	verboseLog("oauth2: scheme=%s authURL=%s tokenURL=%s pkce=%s oidc=%v", ep.Scheme, ep.AuthURL, ep.TokenURL, ep.PKCEMethod, ep.OIDC)
 
-	pkce, err := oauth.NewChallenge()
+	pkce, err := oauth.NewChallengeWithMethod(ep.PKCEMethod)
 	if err != nil {
 		fatalf("failed to generate PKCE challenge", err, "")
 	}
@@ -182,15 +583,36 @@ func runAuthLogin(_ *cobra.Command, _ []string) {
 		fatalf("failed to generate state", err, "")
 	}
 
-	// Start callback server — fails immediately if port 8080 is in use (a2ac-38z.2).
-	ch, stop, err := oauth.StartCallbackServer(ctx, state)
+	cfg := oauthConfig
+	if authRedirectURI != "" {
+		cfg.RedirectURI = authRedirectURI
+	}
+	cfg.AuthMethod = clientAuthMethod()
+	cfg.Scopes = withRequestedScopes(cfg.Scopes)
+	var nonce string
+	if ep.OIDC {
+		cfg.Scopes = withScope(slices.Clone(cfg.Scopes), "openid")
+		if nonce, err = oauth.NewNonce(); err != nil {
+			fatalf("failed to generate nonce", err, "")
+		}
+		params := make(map[string]string, len(cfg.AuthParams)+1)
+		for k, v := range cfg.AuthParams {
+			params[k] = v
+		}
+		params["nonce"] = nonce
+		cfg.AuthParams = params
+	}
+
+	// Start callback server — fails immediately if the port is in use (a2ac-38z.2).
+	ch, stop, redirectURI, err := oauth.StartCallbackServer(ctx, state, cfg)
 	if err != nil {
 		fatalf("failed to start callback server", err,
-			"Free port 8080 and retry. The OAuth callback must listen on "+oauth.RedirectURI)
+			"Free the port and retry, or set --redirect-uri http://127.0.0.1:0/callback if your client allows any loopback port")
 	}
 	defer stop()
+	cfg.RedirectURI = redirectURI
 
-	loginURL := oauth.AuthURL(authURL, state, pkce)
+	loginURL := oauth.AuthURL(ep.AuthURL, state, pkce, cfg)
 	verboseLog("opening browser: %s", loginURL)
 
 	fmt.Printf("Opening browser for authentication...\n")
@@ -200,7 +622,7 @@ func runAuthLogin(_ *cobra.Command, _ []string) {
 		verboseLog("browser open failed: %v (user must navigate manually)", err)
 	}
 
-	fmt.Printf("Waiting for callback on %s ...\n", oauth.RedirectURI)
+	fmt.Printf("Waiting for callback on %s ...\n", redirectURI)
 
 	select {
 	case result := <-ch:
@@ -208,21 +630,30 @@ func runAuthLogin(_ *cobra.Command, _ []string) {
 			fatalf("authentication failed", result.Err, "Check the browser for error details")
 		}
 		verboseLog("received code, exchanging for token")
-		tok, err := oauth.ExchangeCode(ctx, tokenURL, result.Code, pkce)
+		tok, err := oauth.ExchangeCode(ctx, ep.TokenURL, result.Code, pkce, cfg)
 		if err != nil {
 			fatalf("token exchange failed", err, "Check the token endpoint or your credentials")
 		}
+		subject := validateIDToken(ctx, ep, tok, cfg.EffectiveClientID(), nonce)
 		stored := &oauth.StoredToken{
 			AccessToken:  tok.AccessToken,
 			RefreshToken: tok.RefreshToken,
-			Scope:        tok.Scope,
-			TokenURL:     tokenURL,
+			Scope:        grantedScope(tok, cfg.Scopes),
+			TokenURL:     ep.TokenURL,
+			// Refreshes must authenticate as the client the token was issued to.
+			ClientID:      cfg.ClientID,
+			ClientSecret:  cfg.ClientSecret,
+			AuthMethod:    cfg.AuthMethod,
+			Audience:      cfg.Audience,
+			Issuer:        ep.Issuer,
+			RevocationURL: ep.RevocationURL,
+			Subject:       subject,
 		}
 		if tok.ExpiresIn > 0 {
 			stored.ExpiresAt = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
 		}
 		if err := oauth.SaveToken(serviceURL, stored); err != nil {
-			fatalf("failed to save token", err, "Check ~/.config/a2acli/tokens/ permissions")
+			fatalf("failed to save token", err, tokenStoreHint())
 		}
 		fmt.Printf("Authenticated. Token stored for %s\n", serviceURL)
 		if !stored.ExpiresAt.IsZero() {
@@ -235,12 +666,153 @@ func runAuthLogin(_ *cobra.Command, _ []string) {
 	}
 }
 
-func runClientCredentials(ctx context.Context, _ string) {
-	body := fmt.Sprintf("grant_type=client_credentials&client_id=%s&client_secret=%s",
-		authClientID, authClientSecret)
-	_ = body // exchange via ExchangeCode variant — TODO: implement client_credentials exchange
-	fmt.Printf("client credentials flow not yet implemented — use --token $(make token) for now\n")
-	_ = ctx
+// runClientCredentials mints a token with the client_credentials grant and
+// stores it with enough context for LoadValidToken to re-mint it on expiry.
+func runClientCredentials(ctx context.Context, card *a2a.AgentCard) {
+	ep, err := resolveLoginEndpoints(ctx, card, grantClientCredentials)
+	if err != nil {
+		fatalCode(ErrCodeFailedPrecondition, "no OAuth2 token endpoint in AgentCard", err,
+			"Run 'a2acli discover' to inspect the card's security schemes")
+	}
+
+	secret := clientSecret(authClientID)
+	audience := authAudience
+	if audience == "" {
+		audience = oauthConfig.Audience
+	}
+
+	method := clientAuthMethod()
+
+	scopes := ep.Scopes
+	if len(oauthConfig.Scopes) > 0 {
+		scopes = oauthConfig.Scopes
+	}
+	scopes = withRequestedScopes(scopes)
+	verboseLog("client_credentials: scheme=%s tokenURL=%s auth=%s scopes=%v audience=%q resource=%q",
+		ep.Scheme, ep.TokenURL, method, scopes, audience, authResource)
+
+	stored, err := oauth.NewClientCredentialsToken(ctx, oauth.ClientCredentials{
+		TokenURL:     ep.TokenURL,
+		ClientID:     authClientID,
+		ClientSecret: secret,
+		AuthMethod:   method,
+		Scopes:       scopes,
+		Audience:     audience,
+		Resource:     authResource,
+	})
+	if err != nil {
+		fatalCode(ErrCodeUnauthenticated, "client credentials exchange failed", err,
+			"Check --client-id/--client-secret, or try --client-auth post if the server rejects HTTP Basic")
+	}
+	stored.Issuer = ep.Issuer
+	stored.RevocationURL = ep.RevocationURL
+	if err := oauth.SaveToken(serviceURL, stored); err != nil {
+		fatalf("failed to save token", err, tokenStoreHint())
+	}
+
+	if disableTUI {
+		b, _ := json.MarshalIndent(map[string]any{
+			"service_url": serviceURL,
+			"grant_type":  oauth.GrantClientCredentials,
+			"expires_at":  stored.ExpiresAt,
+			"scope":       stored.Scope,
+		}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Printf("Authenticated as %s. Token stored for %s\n", authClientID, serviceURL)
+	if !stored.ExpiresAt.IsZero() {
+		fmt.Printf("Expires: %s (re-minted automatically)\n", stored.ExpiresAt.Format(time.RFC3339))
+	}
+}
+
+// runDeviceLogin drives the RFC 8628 device authorization grant: it prints
+// the verification URI and user code, then polls until the user approves.
+func runDeviceLogin(ctx context.Context, card *a2a.AgentCard) {
+	ep, err := resolveLoginEndpoints(ctx, card, grantDeviceCode)
+	if err != nil {
+		fatalCode(ErrCodeFailedPrecondition, "no deviceCode OAuth flow in AgentCard", err,
+			"The agent does not support the device authorization grant; run 'a2acli discover' to inspect its security schemes")
+	}
+
+	scopes := ep.Scopes
+	if len(oauthConfig.Scopes) > 0 {
+		scopes = oauthConfig.Scopes
+	}
+	scopes = withRequestedScopes(scopes)
+	if ep.OIDC {
+		scopes = withScope(slices.Clone(scopes), "openid")
+	}
+	clientID := authClientID
+	if clientID == "" {
+		clientID = oauthConfig.ClientID
+	}
+	cfg := oauth.Config{ClientID: clientID, ClientSecret: clientSecret(clientID), Scopes: scopes}
+	if cfg.ClientSecret != "" {
+		cfg.AuthMethod = clientAuthMethod()
+	}
+	verboseLog("device flow: scheme=%s deviceURL=%s tokenURL=%s scopes=%v confidential=%v",
+		ep.Scheme, ep.DeviceURL, ep.TokenURL, scopes, cfg.ClientSecret != "")
+
+	da, err := oauth.RequestDeviceCode(ctx, ep.DeviceURL, cfg)
+	if err != nil {
+		fatalf("device authorization failed", err, "Check the AgentCard's deviceAuthorizationUrl")
+	}
+
+	// Instructions go to stderr so stdout stays clean for --output json.
+	if disableTUI {
+		b, _ := json.Marshal(map[string]any{
+			"verification_uri":          da.VerificationURI,
+			"verification_uri_complete": da.VerificationURIComplete,
+			"user_code":                 da.UserCode,
+			"expires_in":                da.ExpiresIn,
+		})
+		fmt.Fprintln(os.Stderr, string(b))
+	} else {
+		fmt.Fprintf(os.Stderr, "To sign in, visit:\n  %s\n", StyleAccent.Render(da.VerificationURI))
+		fmt.Fprintf(os.Stderr, "and enter the code: %s\n", StyleID.Render(da.UserCode))
+		if da.VerificationURIComplete != "" {
+			fmt.Fprintf(os.Stderr, "Or open: %s\n", da.VerificationURIComplete)
+		}
+		fmt.Fprintf(os.Stderr, "\nWaiting for approval...\n")
+	}
+
+	tok, err := oauth.PollDeviceToken(ctx, ep.TokenURL, da, cfg)
+	if err != nil {
+		fatalCode(ErrCodeUnauthenticated, "device login failed", err, "Restart 'a2acli auth login --device' and approve the request before the code expires")
+	}
+
+	// Device flows carry no nonce; the ID token is still checked against the
+	// provider's keys, issuer and audience.
+	subject := validateIDToken(ctx, ep, tok, cfg.EffectiveClientID(), "")
+
+	tokenURL := ep.TokenURL
+	if ep.RefreshURL != "" {
+		tokenURL = ep.RefreshURL
+	}
+	stored := &oauth.StoredToken{
+		AccessToken:   tok.AccessToken,
+		RefreshToken:  tok.RefreshToken,
+		Scope:         grantedScope(tok, scopes),
+		TokenURL:      tokenURL,
+		GrantType:     oauth.GrantDeviceCode,
+		ClientID:      clientID,
+		ClientSecret:  cfg.ClientSecret,
+		AuthMethod:    cfg.AuthMethod,
+		Issuer:        ep.Issuer,
+		RevocationURL: ep.RevocationURL,
+		Subject:       subject,
+	}
+	if tok.ExpiresIn > 0 {
+		stored.ExpiresAt = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	}
+	if err := oauth.SaveToken(serviceURL, stored); err != nil {
+		fatalf("failed to save token", err, tokenStoreHint())
+	}
+	fmt.Printf("Authenticated. Token stored for %s\n", serviceURL)
+	if !stored.ExpiresAt.IsZero() {
+		fmt.Printf("Expires: %s\n", stored.ExpiresAt.Format(time.RFC3339))
+	}
 }
 
 func runAuthStatus(_ *cobra.Command, _ []string) {
@@ -262,12 +834,15 @@ func runAuthStatus(_ *cobra.Command, _ []string) {
 			"expired":     tok.IsExpired(),
 			"has_refresh": tok.RefreshToken != "",
 			"scope":       tok.Scope,
+			"grant_type":  tok.GrantType,
+			"issuer":      tok.Issuer,
+			"subject":     tok.Subject,
 		}, "", "  ")
 		fmt.Println(string(b))
 		return
 	}
 
-	fmt.Printf("Token for %s:\n", serviceURL)
+	fmt.Printf("Token for %s:\n", tok.ServiceURL)
 	if tok.IsExpired() {
 		fmt.Printf("  Status:  EXPIRED\n")
 	} else if tok.ExpiresAt.IsZero() {
@@ -277,12 +852,169 @@ func runAuthStatus(_ *cobra.Command, _ []string) {
 	}
 	fmt.Printf("  Scope:   %s\n", tok.Scope)
 	fmt.Printf("  Refresh: %v\n", tok.RefreshToken != "")
+	if tok.GrantType == oauth.GrantClientCredentials {
+		fmt.Printf("  Grant:   client_credentials (client %s, re-minted on expiry)\n", tok.ClientID)
+	}
+	if tok.Issuer != "" {
+		fmt.Printf("  Issuer:  %s\n", tok.Issuer)
+	}
+	if tok.Subject != "" {
+		fmt.Printf("  Subject: %s\n", tok.Subject)
+	}
+}
+
+type jsonTokenOut struct {
+	ServiceURL string    `json:"service_url,omitempty"`
+	Issuer     string    `json:"issuer,omitempty"`
+	Audience   string    `json:"audience,omitempty"`
+	Subject    string    `json:"subject,omitempty"`
+	GrantType  string    `json:"grant_type,omitempty"`
+	Scope      string    `json:"scope,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	State      string    `json:"state"`
+	Key        string    `json:"key"`
+}
+
+// tokenState summarises a stored token for listings.
+func tokenState(e oauth.StoredEntry) string {
+	switch {
+	case e.Legacy:
+		return "legacy"
+	case e.Token.GrantType == oauth.GrantStatic:
+		return "static"
+	case e.Token.GrantType == oauth.GrantCommand:
+		if e.Token.IsExpired() {
+			return "expired"
+		}
+		return "cached"
+	case e.Token.IsExpired() && e.Token.RefreshToken == "" && e.Token.GrantType != oauth.GrantClientCredentials:
+		return "expired"
+	case e.Token.IsExpired():
+		return "refreshable"
+	}
+	return "valid"
+}
+
+func runAuthList(_ *cobra.Command, _ []string) {
+	entries, err := oauth.ListTokens()
+	if err != nil {
+		fatalf("failed to list tokens", err, tokenStoreHint())
+	}
+
+	list := make([]jsonTokenOut, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, jsonTokenOut{
+			ServiceURL: e.Token.ServiceURL,
+			Issuer:     e.Token.Issuer,
+			Audience:   e.Token.Audience,
+			Subject:    e.Token.Subject,
+			GrantType:  e.Token.GrantType,
+			Scope:      e.Token.Scope,
+			ExpiresAt:  e.Token.ExpiresAt,
+			State:      tokenState(e),
+			Key:        e.Key,
+		})
+	}
+
+	if disableTUI {
+		b, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+
+	if len(list) == 0 {
+		fmt.Printf("No credentials stored (%s store).\n", oauth.CurrentTokenStore().Name())
+		return
+	}
+	fmt.Printf("%-40s | %-30s | %-11s | %-20s | %s\n", "SERVICE", "ISSUER", "STATE", "EXPIRES", "SCOPE")
+	fmt.Println("-------------------------------------------------------------------------------------------------------------------")
+	for _, t := range list {
+		svc := t.ServiceURL
+		if svc == "" {
+			svc = "(host) " + t.Key
+		}
+		if t.Audience != "" {
+			svc += " aud=" + t.Audience
+		}
+		issuer := t.Issuer
+		if issuer == "" {
+			issuer = "-"
+		}
+		expires := "never"
+		if !t.ExpiresAt.IsZero() {
+			expires = t.ExpiresAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-40s | %-30s | %-11s | %-20s | %s\n", svc, issuer, t.State, expires, t.Scope)
+	}
+}
+
+// revocationEndpoint returns where tok can be revoked: the endpoint recorded
+// at login, or the one advertised by its issuer's metadata.
+func revocationEndpoint(ctx context.Context, tok *oauth.StoredToken) string {
+	if tok.RevocationURL != "" || tok.Issuer == "" {
+		return tok.RevocationURL
+	}
+	md, err := oauth.DiscoverIssuer(ctx, tok.Issuer)
+	if err != nil {
+		verboseLog("revocation: discover %s: %v", tok.Issuer, err)
+		return ""
+	}
+	return md.RevocationEndpoint
 }
 
-func runAuthLogout(_ *cobra.Command, _ []string) {
+func runAuthLogout(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	// LoadToken migrates a legacy host-keyed token so it is removed too.
+	if _, err := oauth.LoadToken(serviceURL); err != nil {
+		fatalf("failed to load token", err, tokenStoreHint())
+	}
+	entries, err := oauth.TokensFor(serviceURL)
+	if err != nil {
+		fatalf("failed to load token", err, tokenStoreHint())
+	}
+
+	var revoked int
+	var warnings []string
+	if !authLocalOnly {
+		for _, e := range entries {
+			if e.Token.GrantType == oauth.GrantStatic || e.Token.GrantType == oauth.GrantCommand {
+				continue
+			}
+			e.Token.RevocationURL = revocationEndpoint(ctx, e.Token)
+			err := e.Token.Revoke(ctx)
+			switch {
+			case err == nil:
+				revoked++
+				verboseLog("revoked token at %s", e.Token.RevocationURL)
+			case errors.Is(err, oauth.ErrNoRevocationEndpoint):
+				verboseLog("token %s: %v", e.Key, err)
+			default:
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+
 	if err := oauth.DeleteToken(serviceURL); err != nil {
 		fatalf("failed to delete token", err, "")
 	}
+
+	if disableTUI {
+		b, _ := json.MarshalIndent(map[string]any{
+			"service_url": serviceURL,
+			"deleted":     len(entries),
+			"revoked":     revoked,
+			"warnings":    warnings,
+		}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "%s revocation failed: %s (token deleted locally)\n", StyleWarn.Render("Warning:"), w)
+	}
+	if revoked > 0 {
+		fmt.Printf("Token revoked and deleted for %s\n", serviceURL)
+		return
+	}
 	fmt.Printf("Token deleted for %s\n", serviceURL)
 }
 
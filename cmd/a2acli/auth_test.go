@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/ghchinoy/a2acli/internal/oauth"
+)
+
+func TestMissingSkillScopes(t *testing.T) {
+	card := &a2a.AgentCard{
+		SecuritySchemes: a2a.NamedSecuritySchemes{
+			"oauth": a2a.OAuth2SecurityScheme{Flows: a2a.AuthorizationCodeOAuthFlow{}},
+			"key":   a2a.APIKeySecurityScheme{Name: "X-Key"},
+		},
+		Skills: []a2a.AgentSkill{
+			{ID: "open"},
+			{ID: "reports", SecurityRequirements: a2a.SecurityRequirementsOptions{
+				{"oauth": {"reports.read", "reports.write"}},
+			}},
+			{ID: "either", SecurityRequirements: a2a.SecurityRequirementsOptions{
+				{"oauth": {"admin", "audit"}},
+				{"oauth": {"reports.read"}, "key": {}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		skill   string
+		granted []string
+		want    []string
+	}{
+		{"open", nil, nil},
+		{"unknown", nil, nil},
+		{"reports", []string{"reports.read", "reports.write"}, nil},
+		{"reports", []string{"reports.read"}, []string{"reports.write"}},
+		{"either", []string{"reports.read"}, nil},
+		{"either", []string{"admin"}, []string{"audit"}},
+		{"either", nil, []string{"reports.read"}},
+	}
+	for _, tt := range tests {
+		got := missingSkillScopes(card, tt.skill, tt.granted)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("missingSkillScopes(%s, %v) = %v, want %v", tt.skill, tt.granted, got, tt.want)
+		}
+	}
+}
+
+func TestResolveLoginEndpointsFromDiscovery(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                           ts.URL,
+			"authorization_endpoint":           ts.URL + "/authorize",
+			"token_endpoint":                   ts.URL + "/token",
+			"revocation_endpoint":              ts.URL + "/revoke",
+			"jwks_uri":                         ts.URL + "/jwks",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	}))
+	defer ts.Close()
+
+	card := &a2a.AgentCard{SecuritySchemes: a2a.NamedSecuritySchemes{
+		"oidc": a2a.OpenIDConnectSecurityScheme{OpenIDConnectURL: ts.URL + "/.well-known/openid-configuration"},
+	}}
+	ep, err := resolveLoginEndpoints(context.Background(), card, grantAuthorizationCode)
+	if err != nil {
+		t.Fatalf("resolveLoginEndpoints: %v", err)
+	}
+	if !ep.OIDC || ep.AuthURL != ts.URL+"/authorize" || ep.RevocationURL != ts.URL+"/revoke" || ep.JWKSURI != ts.URL+"/jwks" {
+		t.Errorf("unexpected endpoints: %+v", ep)
+	}
+	if _, err := resolveLoginEndpoints(context.Background(), card, grantDeviceCode); err == nil {
+		t.Error("expected an error: the provider has no device authorization endpoint")
+	}
+}
+
+func TestClientAuthMethod(t *testing.T) {
+	t.Cleanup(func() { authClientAuth, oauthConfig = "", oauth.Config{} })
+	for _, tt := range []struct{ flag, config, want string }{
+		{"", "", oauth.ClientAuthBasic},
+		{"", "post", oauth.ClientAuthPost},
+		{"", oauth.ClientAuthPost, oauth.ClientAuthPost},
+		{"basic", "post", oauth.ClientAuthBasic},
+		{"post", "", oauth.ClientAuthPost},
+	} {
+		authClientAuth, oauthConfig = tt.flag, oauth.Config{AuthMethod: tt.config}
+		if got := clientAuthMethod(); got != tt.want {
+			t.Errorf("--client-auth %q, auth_method %q: got %s, want %s", tt.flag, tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestGrantedScope(t *testing.T) {
+	requested := []string{"openid", "reports.read"}
+	if got := grantedScope(&oauth.TokenResponse{}, requested); got != "openid reports.read" {
+		t.Errorf("omitted scope = %q, want the requested scopes", got)
+	}
+	if got := grantedScope(&oauth.TokenResponse{Scope: "reports.read"}, requested); got != "reports.read" {
+		t.Errorf("narrowed scope = %q", got)
+	}
+}
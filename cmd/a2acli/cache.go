@@ -15,22 +15,58 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/a2aproject/a2a-go/v2/a2aclient/agentcard"
+	"github.com/ghchinoy/a2acli/internal/jose"
+	"github.com/spf13/viper"
 )
 
+// cardCacheTTL is the default lifetime of a cached AgentCard.
 const cardCacheTTL = 10 * time.Minute
 
+// cacheTTL is how long cached AgentCards are used (--cache-ttl,
+// envs.<name>.cache_ttl or cache_ttl). Zero disables reading the cache.
+var cacheTTL = cardCacheTTL
+
+// cacheTTLSet records that the TTL was configured rather than defaulted; a
+// configured TTL takes precedence over the server's Cache-Control max-age.
+var cacheTTLSet bool
+
 type cachedCard struct {
 	URL       string         `json:"url"`
 	FetchedAt time.Time      `json:"fetchedAt"`
 	Card      *a2a.AgentCard `json:"card"`
+	// Protocol is the --protocol the card was parsed for.
+	Protocol string `json:"protocol,omitempty"`
+	// Identity describes the credentials the card was fetched with, e.g.
+	// "anonymous" or "token+headers"; the credentials themselves only
+	// contribute to the cache key.
+	Identity string `json:"identity,omitempty"`
+	// Env is the environment that was active when the card was fetched.
+	Env string `json:"env,omitempty"`
+	// ETag, LastModified and CacheControl are the validators and caching
+	// directives of the card response, used to revalidate the entry.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	CacheControl string `json:"cacheControl,omitempty"`
+	// Raw is the card as served, kept so its signatures can be verified
+	// against the exact bytes that were signed.
+	Raw json.RawMessage `json:"raw,omitempty"`
 }
 
 func getCacheDir() (string, error) {
@@ -42,21 +78,57 @@ func getCacheDir() (string, error) {
 	return dir, nil
 }
 
+// cacheIdentity describes the credentials sent when fetching a card: a
+// readable label, and a digest that separates cards fetched with different
+// credentials. Authenticated agents may serve richer cards, so an
+// unauthenticated card must never stand in for an authenticated one.
+func cacheIdentity() (label, digest string) {
+	var labels, parts []string
+	switch {
+	case authToken != "":
+		labels = append(labels, "token")
+		parts = append(parts, "token:"+authToken)
+	case commandTokens != nil:
+		labels = append(labels, "token_command")
+		parts = append(parts, "command:"+commandTokens.command)
+	}
+	var headers []string
+	for name, v := range configHeaders {
+		headers = append(headers, strings.ToLower(name)+"="+v)
+	}
+	for name, variable := range headersFromEnv {
+		headers = append(headers, strings.ToLower(name)+"="+os.Getenv(variable))
+	}
+	for _, h := range authHeaders {
+		headers = append(headers, "auth="+h)
+	}
+	if len(headers) > 0 {
+		sort.Strings(headers)
+		labels = append(labels, "headers")
+		parts = append(parts, headers...)
+	}
+	if len(parts) == 0 {
+		return "anonymous", "anonymous"
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return strings.Join(labels, "+"), hex.EncodeToString(sum[:8])
+}
+
+// cacheFilePath returns the cache file for targetURL under the current
+// protocol version and credentials.
 func cacheFilePath(targetURL string) (string, error) {
 	dir, err := getCacheDir()
 	if err != nil {
 		return "", err
 	}
-	h := sha256.Sum256([]byte(targetURL))
+	_, identity := cacheIdentity()
+	h := sha256.Sum256([]byte(targetURL + "\x00" + protocol + "\x00" + identity))
 	hashStr := hex.EncodeToString(h[:])
 	return filepath.Join(dir, hashStr+".json"), nil
 }
 
-func loadCachedCard(targetURL string) (*cachedCard, error) {
-	path, err := cacheFilePath(targetURL)
-	if err != nil {
-		return nil, err
-	}
+// readCachedCard reads a cache file regardless of its age.
+func readCachedCard(path string) (*cachedCard, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -68,13 +140,88 @@ func loadCachedCard(targetURL string) (*cachedCard, error) {
 	if cached.Card == nil {
 		return nil, os.ErrNotExist
 	}
-	if time.Since(cached.FetchedAt) > cardCacheTTL {
+	return &cached, nil
+}
+
+// cacheDirectives parses the max-age, no-cache and no-store directives of a
+// Cache-Control header. maxAge is negative when the header sets none.
+func cacheDirectives(header string) (maxAge time.Duration, noCache, noStore bool) {
+	maxAge = -1
+	for _, d := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+		switch strings.ToLower(name) {
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && n >= 0 {
+				maxAge = time.Duration(n) * time.Second
+			}
+		case "no-cache":
+			noCache = true
+		case "no-store":
+			noStore = true
+		}
+	}
+	return maxAge, noCache, noStore
+}
+
+// lifetime is how long the entry may be used without revalidation: the
+// configured TTL, else the server's max-age, else the default TTL.
+func (c *cachedCard) lifetime() time.Duration {
+	if cacheTTLSet {
+		return cacheTTL
+	}
+	maxAge, noCache, _ := cacheDirectives(c.CacheControl)
+	switch {
+	case noCache:
+		return 0
+	case maxAge >= 0:
+		return maxAge
+	default:
+		return cacheTTL
+	}
+}
+
+// expired reports whether the entry is older than its lifetime.
+func (c *cachedCard) expired() bool {
+	return time.Since(c.FetchedAt) >= c.lifetime()
+}
+
+// lookupCachedCard returns the entry for targetURL under the current
+// protocol and credentials, however old it is.
+func lookupCachedCard(targetURL string) (*cachedCard, error) {
+	path, err := cacheFilePath(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := readCachedCard(path)
+	if err != nil {
+		return nil, err
+	}
+	if cached.URL != targetURL {
 		return nil, os.ErrNotExist
 	}
-	return &cached, nil
+	return cached, nil
+}
+
+func loadCachedCard(targetURL string) (*cachedCard, error) {
+	cached, err := lookupCachedCard(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	if cached.expired() {
+		return nil, os.ErrNotExist
+	}
+	return cached, nil
 }
 
 func saveCachedCard(targetURL string, card *a2a.AgentCard) error {
+	return storeCachedCard(targetURL, card, nil, nil)
+}
+
+// storeCachedCard caches card, the raw document it was parsed from and the
+// validators and Cache-Control of the response headers h; raw and h may be
+// nil. Responses marked no-store are not cached, and any earlier entry is
+// removed.
+func storeCachedCard(targetURL string, card *a2a.AgentCard, raw []byte, h http.Header) error {
 	if card == nil {
 		return nil
 	}
@@ -86,10 +233,30 @@ func saveCachedCard(targetURL string, card *a2a.AgentCard) error {
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return err
 	}
+	identity, _ := cacheIdentity()
+	env := envName
+	if env == "" {
+		env = viper.GetString("default_env")
+	}
 	cached := cachedCard{
-		URL:       targetURL,
-		FetchedAt: time.Now(),
-		Card:      card,
+		URL:          targetURL,
+		FetchedAt:    time.Now(),
+		Card:         card,
+		Protocol:     protocol,
+		Identity:     identity,
+		Env:          env,
+		ETag:         h.Get("ETag"),
+		LastModified: h.Get("Last-Modified"),
+		CacheControl: h.Get("Cache-Control"),
+	}
+	if json.Valid(raw) {
+		cached.Raw = raw
+	}
+	if _, _, noStore := cacheDirectives(cached.CacheControl); noStore {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
 	}
 	data, err := json.MarshalIndent(cached, "", "  ")
 	if err != nil {
@@ -97,3 +264,195 @@ func saveCachedCard(targetURL string, card *a2a.AgentCard) error {
 	}
 	return os.WriteFile(path, data, 0600)
 }
+
+// cardResponse records the headers and body of the last card response.
+type cardResponse struct {
+	base   http.RoundTripper
+	header http.Header
+	body   []byte
+}
+
+func (r *cardResponse) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err == nil {
+		r.header = resp.Header
+	}
+	return resp, err
+}
+
+// recordCardResponse wraps the transport and parser of resolver so the card
+// response's validators and Cache-Control can be cached with the card, and
+// its body kept for signature verification.
+func recordCardResponse(resolver *agentcard.Resolver) *cardResponse {
+	r := &cardResponse{base: resolver.Client.Transport}
+	if r.base == nil {
+		r.base = http.DefaultTransport
+	}
+	resolver.Client.Transport = r
+	parse := resolver.CardParser
+	if parse == nil {
+		parse = agentcard.DefaultCardParser
+	}
+	resolver.CardParser = func(body []byte) (*a2a.AgentCard, error) {
+		r.body = body
+		return parse(body)
+	}
+	return r
+}
+
+// cachedHeader returns the stored value of a response header of c.
+func cachedHeader(c *cachedCard, name string) string {
+	switch name {
+	case "ETag":
+		return c.ETag
+	case "Last-Modified":
+		return c.LastModified
+	case "Cache-Control":
+		return c.CacheControl
+	}
+	return ""
+}
+
+// agentUnreachable reports whether err means the card could not be fetched
+// at all, as opposed to the agent answering with an error or a bad card.
+func agentUnreachable(err error) bool {
+	var status *agentcard.ErrStatusNotOK
+	if errors.As(err, &status) {
+		return status.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// cacheEntry is a cache file and its contents; Card is nil when the file
+// cannot be read.
+type cacheEntry struct {
+	Path string
+	*cachedCard
+}
+
+// Key is the entry's cache key, the name of its file.
+func (e cacheEntry) Key() string {
+	return strings.TrimSuffix(filepath.Base(e.Path), ".json")
+}
+
+// shortCacheKey abbreviates a cache key for display. Keys are normally
+// SHA-256 hashes, but foreign files in the cache directory are listed too,
+// under whatever name they have.
+func shortCacheKey(key string) string {
+	return key[:min(12, len(key))]
+}
+
+// listCachedCards returns every file in the card cache, newest first.
+func listCachedCards() ([]cacheEntry, error) {
+	dir, err := getCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]cacheEntry, 0, len(paths))
+	for _, p := range paths {
+		c, err := readCachedCard(p)
+		if err != nil {
+			c = &cachedCard{}
+		}
+		entries = append(entries, cacheEntry{Path: p, cachedCard: c})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].FetchedAt.After(entries[j].FetchedAt) })
+	return entries, nil
+}
+
+// jwksCacheTTL is how long a fetched card signing JWKS is used before it is
+// fetched again.
+const jwksCacheTTL = time.Hour
+
+// cachedJWKS is a card signing JWKS fetched from URL.
+type cachedJWKS struct {
+	URL       string     `json:"url"`
+	FetchedAt time.Time  `json:"fetchedAt"`
+	Keys      *jose.JWKS `json:"jwks"`
+}
+
+// getJWKSCacheDir returns the directory of cached card signing JWKS, beside
+// the card cache.
+func getJWKSCacheDir() (string, error) {
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userCache, "a2acli", "jwks"), nil
+}
+
+// jwksCachePath returns the cache file of the JWKS at jwksURL.
+func jwksCachePath(jwksURL string) (string, error) {
+	dir, err := getJWKSCacheDir()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(jwksURL))
+	return filepath.Join(dir, hex.EncodeToString(h[:])+".json"), nil
+}
+
+// readCachedJWKS returns the cached JWKS of jwksURL however old it is, or
+// nil.
+func readCachedJWKS(path, jwksURL string) *cachedJWKS {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cached cachedJWKS
+	if err := json.Unmarshal(data, &cached); err != nil || cached.URL != jwksURL || cached.Keys == nil {
+		return nil
+	}
+	return &cached
+}
+
+// fetchCardJWKS returns the card signing keys at jwksURL. A copy fetched
+// within jwksCacheTTL is used without a request (unless --no-cache), and an
+// older one when the URL is unreachable, so cached cards still verify
+// offline.
+func fetchCardJWKS(ctx context.Context, jwksURL string) (*jose.JWKS, error) {
+	path, err := jwksCachePath(jwksURL)
+	if err != nil {
+		verboseLog("JWKS cache unavailable: %v", err)
+	}
+	var cached *cachedJWKS
+	if path != "" && !noCache {
+		cached = readCachedJWKS(path, jwksURL)
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < jwksCacheTTL {
+		verboseLog("using cached JWKS %s (fetched %s ago)", jwksURL, time.Since(cached.FetchedAt).Round(time.Second))
+		return cached.Keys, nil
+	}
+
+	set, err := jose.FetchJWKS(ctx, newHTTPClient(30*time.Second), jwksURL)
+	if err != nil {
+		if cached == nil || !agentUnreachable(err) {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "%s %s is unreachable; using the card signing keys fetched %s ago (%v)\n",
+			StyleWarn.Render("Warning:"), jwksURL, time.Since(cached.FetchedAt).Round(time.Second), err)
+		return cached.Keys, nil
+	}
+	if path != "" {
+		if err := storeCachedJWKS(path, jwksURL, set); err != nil {
+			verboseLog("failed to save JWKS to disk cache: %v", err)
+		}
+	}
+	return set, nil
+}
+
+// storeCachedJWKS writes set to path as the JWKS of jwksURL.
+func storeCachedJWKS(path, jwksURL string, set *jose.JWKS) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cachedJWKS{URL: jwksURL, FetchedAt: time.Now(), Keys: set}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
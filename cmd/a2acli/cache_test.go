@@ -15,7 +15,13 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -97,3 +103,210 @@ func TestCacheExpiration(t *testing.T) {
 		t.Errorf("expected error for expired cache entry, got nil")
 	}
 }
+
+func TestCacheKeyIdentityAndProtocol(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	oldToken, oldProtocol := authToken, protocol
+	t.Cleanup(func() { authToken, protocol = oldToken, oldProtocol })
+
+	targetURL := "http://127.0.0.1:9997"
+	authToken, protocol = "", "1.0"
+	if err := saveCachedCard(targetURL, &a2a.AgentCard{Name: "Public"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A card fetched anonymously must not be served to an authenticated call.
+	authToken = "secret"
+	if _, err := loadCachedCard(targetURL); err == nil {
+		t.Error("anonymous card was reused with a bearer token")
+	}
+	if err := saveCachedCard(targetURL, &a2a.AgentCard{Name: "Private"}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := loadCachedCard(targetURL); err != nil || c.Card.Name != "Private" || c.Identity != "token" {
+		t.Errorf("authenticated entry = %+v, %v", c, err)
+	}
+
+	protocol = "0.3"
+	if _, err := loadCachedCard(targetURL); err == nil {
+		t.Error("1.0 card was reused for protocol 0.3")
+	}
+
+	authToken, protocol = "", "1.0"
+	if c, err := loadCachedCard(targetURL); err != nil || c.Card.Name != "Public" || c.Identity != "anonymous" {
+		t.Errorf("anonymous entry = %+v, %v", c, err)
+	}
+
+	entries, err := listCachedCards()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("listCachedCards = %v, %v", entries, err)
+	}
+	if entries[0].Card.Name != "Private" {
+		t.Errorf("entries are not newest first: %s", entries[0].Card.Name)
+	}
+	if m := matchCacheEntries(entries, targetURL+"/"); len(m) != 2 {
+		t.Errorf("URL match = %d entries", len(m))
+	}
+	if m := matchCacheEntries(entries, entries[1].Key()[:8]); len(m) != 1 || m[0].Card.Name != "Public" {
+		t.Errorf("key match = %v", m)
+	}
+}
+
+func TestCacheListForeignFiles(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	oldTUI := disableTUI
+	t.Cleanup(func() { disableTUI = oldTUI })
+
+	if err := saveCachedCard("http://127.0.0.1:9996", &a2a.AgentCard{Name: "Agent"}); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := getCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A stray file with a short, non-hash name is listed, not a crash, so
+	// that 'cache prune' can be shown cleaning it up.
+	if err := os.WriteFile(filepath.Join(dir, "x.json"), []byte("not a card"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := listCachedCards()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("listCachedCards = %v, %v", entries, err)
+	}
+	for _, tui := range []bool{false, true} {
+		disableTUI = !tui
+		runCacheList(nil, nil)
+	}
+	if got := shortCacheKey("x"); got != "x" {
+		t.Errorf("shortCacheKey(x) = %q", got)
+	}
+	if got := shortCacheKey(entries[0].Key()); len(entries[0].Key()) == 64 && len(got) != 12 {
+		t.Errorf("shortCacheKey of a hash = %q", got)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Cleanup(func() { cacheTTL = cardCacheTTL })
+
+	targetURL := "http://127.0.0.1:9996"
+	if err := saveCachedCard(targetURL, &a2a.AgentCard{Name: "TTL"}); err != nil {
+		t.Fatal(err)
+	}
+	cacheTTL = 0
+	if _, err := loadCachedCard(targetURL); err == nil {
+		t.Error("a zero TTL should disable the cache")
+	}
+	cacheTTL = time.Hour
+	if _, err := loadCachedCard(targetURL); err != nil {
+		t.Errorf("fresh entry not loaded: %v", err)
+	}
+
+	path, _ := cacheFilePath(targetURL)
+	old := []byte(`{"url":"` + targetURL + `","fetchedAt":"` + time.Now().Add(-2*time.Hour).Format(time.RFC3339) + `","card":{"name":"TTL"},"protocol":"1.0"}`)
+	if err := os.WriteFile(path, old, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCachedCard(targetURL); err == nil {
+		t.Error("entry older than the TTL was loaded")
+	}
+	cacheTTL = 3 * time.Hour
+	entries, _ := listCachedCards()
+	if len(entries) != 1 || cacheState(entries[0]) != "fresh" {
+		t.Errorf("state = %v", entries)
+	}
+}
+
+func TestConditionalCardFetch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Cleanup(func() { cacheTTL, cacheTTLSet = cardCacheTTL, false })
+
+	var fetches, notModified atomic.Int32
+	cacheControl := "max-age=3600"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", cacheControl)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fetches.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(a2a.AgentCard{Name: "Conditional"})
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := resolveAgentCard(ctx, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := lookupCachedCard(srv.URL)
+	if err != nil || cached.ETag != `"v1"` || cached.lifetime() != time.Hour {
+		t.Fatalf("cached = %+v, %v", cached, err)
+	}
+
+	// max-age outlives the default TTL, so the card is served from the cache.
+	cached.FetchedAt = time.Now().Add(-30 * time.Minute)
+	writeTestEntry(t, srv.URL, cached)
+	if _, err := resolveAgentCard(ctx, srv.URL); err != nil || fetches.Load() != 1 || notModified.Load() != 0 {
+		t.Fatalf("fresh entry: fetches=%d 304s=%d err=%v", fetches.Load(), notModified.Load(), err)
+	}
+
+	// Once max-age has passed the entry is revalidated with If-None-Match.
+	cached.FetchedAt = time.Now().Add(-2 * time.Hour)
+	writeTestEntry(t, srv.URL, cached)
+	card, err := resolveAgentCard(ctx, srv.URL)
+	if err != nil || card.Name != "Conditional" || fetches.Load() != 1 || notModified.Load() != 1 {
+		t.Fatalf("revalidation: fetches=%d 304s=%d err=%v", fetches.Load(), notModified.Load(), err)
+	}
+	if c, _ := lookupCachedCard(srv.URL); c.expired() || c.ETag != `"v1"` {
+		t.Errorf("a 304 did not renew the entry: %+v", c)
+	}
+
+	// A configured TTL overrides max-age.
+	cacheTTL, cacheTTLSet = 0, true
+	if _, err := resolveAgentCard(ctx, srv.URL); err != nil || notModified.Load() != 2 {
+		t.Errorf("configured TTL: 304s=%d err=%v", notModified.Load(), err)
+	}
+	cacheTTL, cacheTTLSet = cardCacheTTL, false
+
+	// An unreachable agent falls back to the stale entry.
+	srv.Close()
+	cached.FetchedAt = time.Now().Add(-2 * time.Hour)
+	writeTestEntry(t, srv.URL, cached)
+	if card, err := resolveAgentCard(ctx, srv.URL); err != nil || card.Name != "Conditional" {
+		t.Errorf("offline: %v, %v", card, err)
+	}
+	noCache = true
+	defer func() { noCache = false }()
+	if _, err := resolveAgentCard(ctx, srv.URL); err == nil {
+		t.Error("--no-cache served a stale card for an unreachable agent")
+	}
+}
+
+func TestNoStoreCard(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	h := http.Header{"Cache-Control": {"no-store"}}
+	if err := storeCachedCard("http://127.0.0.1:9995", &a2a.AgentCard{Name: "Private"}, nil, h); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lookupCachedCard("http://127.0.0.1:9995"); err == nil {
+		t.Error("a no-store card was cached")
+	}
+
+	if maxAge, noCache, _ := cacheDirectives(`public, no-cache, max-age="60"`); maxAge != time.Minute || !noCache {
+		t.Errorf("cacheDirectives = %v, %v", maxAge, noCache)
+	}
+}
+
+func writeTestEntry(t *testing.T, targetURL string, c *cachedCard) {
+	t.Helper()
+	path, err := cacheFilePath(targetURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(c)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThan time.Duration
+	refreshAll     bool
+)
+
+// setupCacheCmd builds the `cache` command group.
+func setupCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:     "cache",
+		GroupID: GroupSystem,
+		Short:   "Inspect and manage the AgentCard cache",
+		Long: `Resolved AgentCards are cached under $XDG_CACHE_HOME/a2acli/cards and reused
+for the agent's Cache-Control max-age, or 10m when it sends none. A TTL set
+with --cache-ttl, cache_ttl or envs.<name>.cache_ttl in config.yaml takes
+precedence. Expired entries are revalidated with If-None-Match and
+If-Modified-Since, and served with a warning when the agent is unreachable.
+
+Entries are keyed by service URL, --protocol version and the credentials sent
+with the request, so a card fetched anonymously is never reused for an
+authenticated call and a 0.3 card is never reused under 1.0.
+
+Card signing keys fetched from a jwks_url are cached under
+$XDG_CACHE_HOME/a2acli/jwks for 1h, and used past that when the URL is
+unreachable, so cached cards still verify offline.
+
+Entries are identified by URL or by a prefix of their KEY, as shown by
+'a2acli cache list'. --no-cache bypasses the cache for a single command.`,
+		Example: `  a2acli cache list
+  a2acli cache show http://localhost:9001
+  a2acli cache refresh --all
+  a2acli cache prune
+  a2acli cache clear`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List cached AgentCards",
+		Long: `List cached AgentCards with their protocol version, the credentials they were
+fetched with, and whether they are fresh under the current TTL. Entries written
+before cache keys included the protocol and credentials are shown as legacy;
+'a2acli cache prune' removes them.`,
+		Example: `  a2acli cache list
+  a2acli cache list -o json`,
+		Args: cobra.NoArgs,
+		Run:  runCacheList,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show <url|key>",
+		Short: "Print a cached AgentCard",
+		Long: `Print a cached AgentCard and its cache metadata. When several entries exist
+for a URL, the one matching the current protocol and credentials is shown;
+otherwise name the entry by its key.`,
+		Example: `  a2acli cache show http://localhost:9001
+  a2acli cache show 3fa8c2 -o json`,
+		Args: cobra.ExactArgs(1),
+		Run:  runCacheShow,
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear [url|key...]",
+		Short: "Delete cached AgentCards",
+		Long: `Delete the named entries, or every cached AgentCard when none is named.
+Clearing everything also removes the cached card signing keys (JWKS).`,
+		Example: `  a2acli cache clear
+  a2acli cache clear http://localhost:9001`,
+		Run: runCacheClear,
+	}
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete expired, legacy and unreadable entries",
+		Example: `  a2acli cache prune
+  a2acli cache prune --older-than 24h`,
+		Args: cobra.NoArgs,
+		Run:  runCachePrune,
+	}
+	pruneCmd.Flags().DurationVar(&pruneOlderThan, "older-than", 0, "Delete entries older than this instead of the cache TTL")
+
+	refreshCmd := &cobra.Command{
+		Use:   "refresh [url...]",
+		Short: "Fetch AgentCards again and update the cache",
+		Long: `Fetch the AgentCard of each URL (default: the active service URL) with the
+current protocol and credentials, replacing the cached copy. With --all, every
+cached URL whose entry matches the current protocol and credentials is
+refreshed; entries fetched with other credentials are skipped.`,
+		Example: `  a2acli cache refresh
+  a2acli cache refresh --env prod
+  a2acli cache refresh --all`,
+		Run: runCacheRefresh,
+	}
+	refreshCmd.Flags().BoolVar(&refreshAll, "all", false, "Refresh every cached URL that matches the current protocol and credentials")
+
+	cacheCmd.AddCommand(listCmd, showCmd, clearCmd, pruneCmd, refreshCmd)
+	return cacheCmd
+}
+
+// cacheState describes an entry under the current TTL.
+func cacheState(e cacheEntry) string {
+	switch {
+	case e.Card == nil:
+		return "unreadable"
+	case e.Protocol == "":
+		return "legacy"
+	case e.expired():
+		return "stale"
+	default:
+		return "fresh"
+	}
+}
+
+type jsonCacheEntry struct {
+	Key       string    `json:"key"`
+	URL       string    `json:"url,omitempty"`
+	Protocol  string    `json:"protocol,omitempty"`
+	Identity  string    `json:"identity,omitempty"`
+	Env       string    `json:"env,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// TTL is how long the entry is used before it is revalidated.
+	TTL          string `json:"ttl"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	CacheControl string `json:"cache_control,omitempty"`
+	State        string `json:"state"`
+}
+
+func toJSONCacheEntry(e cacheEntry) jsonCacheEntry {
+	out := jsonCacheEntry{
+		Key: e.Key(), URL: e.URL, Protocol: e.Protocol, Identity: e.Identity, Env: e.Env,
+		FetchedAt: e.FetchedAt, TTL: e.lifetime().String(), ETag: e.ETag, LastModified: e.LastModified,
+		CacheControl: e.CacheControl, State: cacheState(e),
+	}
+	if e.Card != nil {
+		out.Agent, out.Version = e.Card.Name, e.Card.Version
+	}
+	return out
+}
+
+func mustListCache() []cacheEntry {
+	entries, err := listCachedCards()
+	if err != nil {
+		fatalf("failed to read the card cache", err, "")
+	}
+	return entries
+}
+
+// matchCacheEntries returns the entries whose URL is arg or whose key starts
+// with arg.
+func matchCacheEntries(entries []cacheEntry, arg string) []cacheEntry {
+	var out []cacheEntry
+	url := strings.TrimSuffix(arg, "/")
+	for _, e := range entries {
+		if (e.URL != "" && strings.TrimSuffix(e.URL, "/") == url) || (len(arg) >= 4 && strings.HasPrefix(e.Key(), arg)) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func runCacheList(_ *cobra.Command, _ []string) {
+	entries := mustListCache()
+	if disableTUI {
+		out := make([]jsonCacheEntry, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, toJSONCacheEntry(e))
+		}
+		b, _ := json.MarshalIndent(out, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("The card cache is empty.")
+		return
+	}
+	rows := [][]string{{"KEY", "URL", "PROTOCOL", "IDENTITY", "ENV", "AGENT", "AGE", "TTL", "STATE"}}
+	for _, e := range entries {
+		j := toJSONCacheEntry(e)
+		age := "-"
+		if !e.FetchedAt.IsZero() {
+			age = time.Since(e.FetchedAt).Round(time.Second).String()
+		}
+		state := j.State
+		if state != "fresh" {
+			state = StyleMuted.Render(state)
+		}
+		rows = append(rows, []string{shortCacheKey(j.Key), dash(j.URL), dash(j.Protocol), dash(j.Identity), dash(j.Env), dash(j.Agent), age, j.TTL, state})
+	}
+	printTable(os.Stdout, rows)
+	fmt.Printf("\n%d entries, default TTL %s\n", len(entries), cacheTTL)
+}
+
+func runCacheShow(_ *cobra.Command, args []string) {
+	matches := matchCacheEntries(mustListCache(), args[0])
+	var entry cacheEntry
+	switch len(matches) {
+	case 0:
+		fatalCode(ErrCodeNotFound, "no cached card", fmt.Errorf("%q", args[0]), "Run 'a2acli cache list' to see cached cards")
+	case 1:
+		entry = matches[0]
+	default:
+		current, _ := cacheFilePath(args[0])
+		for _, m := range matches {
+			if m.Path == current {
+				entry = m
+			}
+		}
+		if entry.cachedCard == nil {
+			keys := make([]string, len(matches))
+			for i, m := range matches {
+				keys[i] = fmt.Sprintf("%s (%s, %s)", shortCacheKey(m.Key()), dash(m.Protocol), dash(m.Identity))
+			}
+			fatalCode(ErrCodeInvalidArgument, "several cached cards match", errors.New(strings.Join(keys, ", ")), "Name one by its key")
+		}
+	}
+	if entry.Card == nil {
+		fatalCode(ErrCodeInvalidArgument, "cache entry is unreadable", errors.New(entry.Path), "Remove it with 'a2acli cache prune'")
+	}
+
+	if disableTUI {
+		b, _ := json.MarshalIndent(map[string]any{
+			"entry": toJSONCacheEntry(entry),
+			"card":  entry.Card,
+		}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	j := toJSONCacheEntry(entry)
+	fmt.Printf("%s %s\n", StyleMuted.Render("Key:      "), j.Key)
+	fmt.Printf("%s %s\n", StyleMuted.Render("URL:      "), j.URL)
+	fmt.Printf("%s %s\n", StyleMuted.Render("Protocol: "), dash(j.Protocol))
+	fmt.Printf("%s %s\n", StyleMuted.Render("Identity: "), dash(j.Identity))
+	fmt.Printf("%s %s (%s ago, %s)\n", StyleMuted.Render("Fetched:  "), j.FetchedAt.Format(time.RFC3339), time.Since(j.FetchedAt).Round(time.Second), j.State)
+	fmt.Printf("%s %s\n", StyleMuted.Render("TTL:      "), j.TTL)
+	for _, h := range [][2]string{{"ETag", j.ETag}, {"Modified", j.LastModified}, {"Caching", j.CacheControl}} {
+		if h[1] != "" {
+			fmt.Printf("%s %s\n", StyleMuted.Render(fmt.Sprintf("%-10s", h[0]+":")), h[1])
+		}
+	}
+	b, _ := json.MarshalIndent(entry.Card, "", "  ")
+	fmt.Println(string(b))
+}
+
+// removeCacheEntries deletes entries and returns how many were removed.
+func removeCacheEntries(entries []cacheEntry) int {
+	n := 0
+	for _, e := range entries {
+		if err := os.Remove(e.Path); err != nil {
+			verboseLog("failed to remove %s: %v", e.Path, err)
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+func printCacheRemoval(n int) {
+	if disableTUI {
+		b, _ := json.Marshal(map[string]int{"removed": n})
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Printf("Removed %d cached card(s).\n", n)
+}
+
+func runCacheClear(_ *cobra.Command, args []string) {
+	entries := mustListCache()
+	if len(args) > 0 {
+		var selected []cacheEntry
+		for _, arg := range args {
+			m := matchCacheEntries(entries, arg)
+			if len(m) == 0 {
+				fatalCode(ErrCodeNotFound, "no cached card", fmt.Errorf("%q", arg), "Run 'a2acli cache list' to see cached cards")
+			}
+			selected = append(selected, m...)
+		}
+		entries = selected
+	} else if dir, err := getJWKSCacheDir(); err == nil {
+		// Card signing keys are fetched again on next use.
+		if err := os.RemoveAll(dir); err != nil {
+			verboseLog("failed to remove %s: %v", dir, err)
+		}
+	}
+	printCacheRemoval(removeCacheEntries(entries))
+}
+
+func runCachePrune(_ *cobra.Command, _ []string) {
+	var prune []cacheEntry
+	for _, e := range mustListCache() {
+		state := cacheState(e)
+		if pruneOlderThan > 0 && state != "unreadable" && state != "legacy" {
+			if time.Since(e.FetchedAt) > pruneOlderThan {
+				prune = append(prune, e)
+			}
+			continue
+		}
+		if state != "fresh" {
+			prune = append(prune, e)
+		}
+	}
+	printCacheRemoval(removeCacheEntries(prune))
+}
+
+func runCacheRefresh(cmd *cobra.Command, args []string) {
+	urls := args
+	skipped := 0
+	if refreshAll {
+		seen := map[string]bool{}
+		for _, e := range mustListCache() {
+			if e.URL == "" {
+				continue
+			}
+			if current, err := cacheFilePath(e.URL); err != nil || current != e.Path {
+				skipped++
+				continue
+			}
+			if seen[e.URL] {
+				continue
+			}
+			seen[e.URL] = true
+			urls = append(urls, e.URL)
+		}
+	}
+	if len(urls) == 0 && !refreshAll {
+		urls = []string{serviceURL}
+	}
+
+	noCache = true
+	type refreshed struct {
+		URL     string `json:"url"`
+		Agent   string `json:"agent,omitempty"`
+		Version string `json:"version,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}
+	var results []refreshed
+	failed := false
+	for _, u := range urls {
+		card, err := resolveAgentCard(cmd.Context(), u)
+		if err != nil {
+			failed = true
+			results = append(results, refreshed{URL: u, Error: err.Error()})
+			continue
+		}
+		results = append(results, refreshed{URL: u, Agent: card.Name, Version: card.Version})
+	}
+
+	if disableTUI {
+		b, _ := json.MarshalIndent(map[string]any{"refreshed": results, "skipped": skipped}, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Printf("%s %s: %s\n", StyleFail.Render("✗"), r.URL, r.Error)
+				continue
+			}
+			fmt.Printf("%s %s: %s %s\n", StylePass.Render("✓"), r.URL, r.Agent, r.Version)
+		}
+		if skipped > 0 {
+			fmt.Printf("Skipped %d entries fetched with other credentials or protocol versions.\n", skipped)
+		}
+		if len(results) == 0 && skipped == 0 {
+			fmt.Println("Nothing to refresh.")
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/a2aproject/a2a-go/v2/a2acompat/a2av0"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maxCardSize bounds the AgentCards read from files, stdin and URLs.
+const maxCardSize = 4 << 20
+
+// setupCardCmd builds the `card` command group for working with AgentCards
+// outside a live session.
+func setupCardCmd() *cobra.Command {
+	cardCmd := &cobra.Command{
+		Use:     "card",
+		GroupID: GroupDiscovery,
+		Short:   "Work with AgentCards: create, lint, compare, sign and verify cards",
+		Long: `Commands for AgentCards as artifacts: create a card, lint a card, compare the
+cards of two releases, environments or files, and sign cards or verify their
+signatures.
+
+Wherever a card is expected, any of these sources can be named:
+
+  card.json            A card file (A2A 1.0 or 0.3 JSON); - reads stdin
+  https://agent        The live card of an agent, fetched with the current
+                       credentials (a URL ending in .json is fetched as-is)
+  env:<name>           The live card of a configured environment, fetched
+                       with that environment's URL, TLS, proxy and headers
+  cache:[url]          The cached card of a URL (default: the service URL)`,
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Show semantic changes between two AgentCards",
+		Long: `Compare two AgentCards and report what changed for clients: skills added or
+removed, security schemes and requirements, interfaces and transports,
+capability flags, extensions and input/output modes.
+
+A change is breaking when a working client could fail against the new card:
+a skill, interface, mode or security alternative was removed, a capability was
+turned off, a security scheme changed, or credentials or an extension became
+required. The command exits 1 when any breaking change is found, so it can
+gate releases:
+
+  a2acli card diff env:prod card.json -o json`,
+		Example: `  a2acli card diff old-card.json new-card.json
+  a2acli card diff env:prod env:staging
+  a2acli card diff cache:https://agent.example.com https://agent.example.com
+  curl -s https://agent/.well-known/agent-card.json | a2acli card diff released.json -`,
+		Args: cobra.ExactArgs(2),
+		Run:  runCardDiff,
+	}
+
+	cardCmd.AddCommand(diffCmd)
+	cardCmd.AddCommand(setupCardSignCmds()...)
+	cardCmd.AddCommand(setupCardLintCmd())
+	cardCmd.AddCommand(setupCardInitCmd())
+	return cardCmd
+}
+
+// parseAgentCard parses a card in A2A 1.0 or 0.3 JSON. 0.3 cards are told
+// apart by their top-level url and missing supportedInterfaces.
+func parseAgentCard(data []byte) (*a2a.AgentCard, error) {
+	var probe struct {
+		SupportedInterfaces json.RawMessage `json:"supportedInterfaces"`
+		URL                 string          `json:"url"`
+		ProtocolVersion     string          `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if probe.SupportedInterfaces == nil && (probe.URL != "" || probe.ProtocolVersion != "") {
+		return a2av0.NewAgentCardParser()(data)
+	}
+	var card a2a.AgentCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// cardDocument is a loaded AgentCard, the document it was parsed from, a
+// label describing where it came from and the file or URL it was read from.
+type cardDocument struct {
+	Card     *a2a.AgentCard
+	Raw      []byte
+	Label    string
+	Location string
+}
+
+// loadCardSource returns the card named by src (see the card command).
+func loadCardSource(ctx context.Context, src string) (*cardDocument, error) {
+	switch {
+	case strings.HasPrefix(src, "env:"):
+		name := strings.TrimPrefix(src, "env:")
+		if !viper.IsSet("envs." + name) {
+			return nil, fmt.Errorf("environment %q is not configured", name)
+		}
+		c := newEnvCheck(name)
+		if c.ServiceURL == "" {
+			return nil, fmt.Errorf("environment %q has no service_url", name)
+		}
+		timeout := requestTimeout
+		if timeout == 0 {
+			timeout = 30 * time.Second
+		}
+		checkEnv(ctx, c, timeout)
+		if c.card == nil {
+			return nil, fmt.Errorf("%s: %s", c.ServiceURL, c.Error)
+		}
+		return &cardDocument{c.card, c.raw, fmt.Sprintf("%s (%s)", src, c.ServiceURL), c.ServiceURL}, nil
+
+	case strings.HasPrefix(src, "cache:"):
+		target := strings.TrimPrefix(src, "cache:")
+		if target == "" {
+			target = serviceURL
+		}
+		cached, err := lookupCachedCard(target)
+		if err != nil {
+			return nil, fmt.Errorf("no cached card for %s with the current protocol and credentials", target)
+		}
+		return &cardDocument{cached.Card, cached.Raw, fmt.Sprintf("cache:%s (fetched %s)", target, cached.FetchedAt.Format(time.RFC3339)), target}, nil
+
+	case strings.HasPrefix(src, "unix://"):
+		serviceURL = src
+		if err := useUnixSocket(); err != nil {
+			return nil, err
+		}
+		fallthrough
+
+	case strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://"):
+		if strings.HasSuffix(src, ".json") {
+			data, err := fetchCardDocument(ctx, src)
+			if err != nil {
+				return nil, err
+			}
+			card, err := parseAgentCard(data)
+			if err != nil {
+				return nil, err
+			}
+			return &cardDocument{card, data, src, src}, nil
+		}
+		card, raw, err := loadAgentCard(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		return &cardDocument{card, raw, src, src}, nil
+
+	default:
+		var data []byte
+		var err error
+		if src == "-" {
+			data, err = io.ReadAll(io.LimitReader(os.Stdin, maxCardSize))
+		} else {
+			data, err = os.ReadFile(src)
+		}
+		if err != nil {
+			return nil, err
+		}
+		card, err := parseAgentCard(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
+		}
+		if src == "-" {
+			src = "stdin"
+		}
+		return &cardDocument{card, data, src, src}, nil
+	}
+}
+
+// fetchCardDocument downloads a card published at a URL of its own.
+func fetchCardDocument(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxCardSize))
+}
+
+func runCardDiff(cmd *cobra.Command, args []string) {
+	if args[0] == "-" && args[1] == "-" {
+		fatalCode(ErrCodeInvalidArgument, "invalid arguments", errors.New("only one card can be read from stdin"), "")
+	}
+	// Live cards are compared as they are now, not as cached. Cached cards
+	// are read first, before a live fetch of the same URL replaces them.
+	noCache = true
+	docs := make([]*cardDocument, 2)
+	order := []int{0, 1}
+	if strings.HasPrefix(args[1], "cache:") {
+		order = []int{1, 0}
+	}
+	for _, i := range order {
+		var err error
+		if docs[i], err = loadCardSource(cmd.Context(), args[i]); err != nil {
+			fatalf("failed to load card", err, "")
+		}
+	}
+	exitOnBreaking(printCardDiff(docs[0].Label, docs[1].Label, diffCards(docs[0].Card, docs[1].Card)))
+}
@@ -0,0 +1,442 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+// Areas of an AgentCard a change can affect.
+const (
+	areaCard       = "card"
+	areaModes      = "modes"
+	areaInterface  = "interface"
+	areaCapability = "capability"
+	areaExtension  = "extension"
+	areaSecurity   = "security"
+	areaSkill      = "skill"
+)
+
+// cardChange is one semantic difference between two AgentCards. Breaking
+// changes are those that can make a working client fail: something it may
+// rely on was removed or restricted, or a new requirement was imposed.
+type cardChange struct {
+	Area     string `json:"area"`
+	Kind     string `json:"kind"` // added, removed or changed
+	Subject  string `json:"subject"`
+	Detail   string `json:"detail,omitempty"`
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+// cardDiff collects the changes found between two cards.
+type cardDiff struct {
+	changes []cardChange
+}
+
+func (d *cardDiff) add(area, subject, detail, after string, breaking bool) {
+	d.changes = append(d.changes, cardChange{Area: area, Kind: "added", Subject: subject, Detail: detail, After: after, Breaking: breaking})
+}
+
+func (d *cardDiff) remove(area, subject, detail, before string, breaking bool) {
+	d.changes = append(d.changes, cardChange{Area: area, Kind: "removed", Subject: subject, Detail: detail, Before: before, Breaking: breaking})
+}
+
+func (d *cardDiff) change(area, subject, detail, before, after string, breaking bool) {
+	d.changes = append(d.changes, cardChange{Area: area, Kind: "changed", Subject: subject, Detail: detail, Before: before, After: after, Breaking: breaking})
+}
+
+// diffCards compares an old and a new AgentCard.
+func diffCards(a, b *a2a.AgentCard) []cardChange {
+	d := &cardDiff{}
+	diffIdentity(d, a, b)
+	diffModes(d, "", "default input mode", a.DefaultInputModes, b.DefaultInputModes)
+	diffModes(d, "", "default output mode", a.DefaultOutputModes, b.DefaultOutputModes)
+	diffInterfaces(d, a.SupportedInterfaces, b.SupportedInterfaces)
+	diffCapabilities(d, a.Capabilities, b.Capabilities)
+	diffExtensions(d, a.Capabilities.Extensions, b.Capabilities.Extensions)
+	diffSchemes(d, a.SecuritySchemes, b.SecuritySchemes)
+	diffRequirements(d, "", a.SecurityRequirements, b.SecurityRequirements)
+	diffSkills(d, a.Skills, b.Skills)
+	return d.changes
+}
+
+func diffIdentity(d *cardDiff, a, b *a2a.AgentCard) {
+	fields := []struct{ name, before, after string }{
+		{"name", a.Name, b.Name},
+		{"version", a.Version, b.Version},
+		{"description", a.Description, b.Description},
+		{"documentationUrl", a.DocumentationURL, b.DocumentationURL},
+		{"iconUrl", a.IconURL, b.IconURL},
+		{"provider", providerString(a.Provider), providerString(b.Provider)},
+	}
+	for _, f := range fields {
+		if f.before != f.after {
+			d.change(areaCard, f.name, "", f.before, f.after, false)
+		}
+	}
+}
+
+func providerString(p *a2a.AgentProvider) string {
+	if p == nil {
+		return ""
+	}
+	return strings.TrimSpace(p.Org + " " + p.URL)
+}
+
+// diffModes compares media type lists. Removing a mode breaks clients that
+// send or accept only that type.
+func diffModes(d *cardDiff, skill, what string, a, b []string) {
+	removed, added := setDiff(a, b)
+	area, subject := areaModes, what
+	if skill != "" {
+		area, subject = areaSkill, skill
+	}
+	for _, m := range removed {
+		d.remove(area, subject, what+" "+m, m, true)
+	}
+	for _, m := range added {
+		d.add(area, subject, what+" "+m, m, false)
+	}
+}
+
+// interfaceKey identifies an interface by what a client selects it by.
+func interfaceKey(i *a2a.AgentInterface) string {
+	key := string(i.ProtocolBinding) + " " + string(i.ProtocolVersion)
+	if i.Tenant != "" {
+		key += " tenant " + i.Tenant
+	}
+	return strings.TrimSpace(key)
+}
+
+func diffInterfaces(d *cardDiff, a, b []*a2a.AgentInterface) {
+	before, after := map[string]*a2a.AgentInterface{}, map[string]*a2a.AgentInterface{}
+	for _, i := range a {
+		if i != nil {
+			before[interfaceKey(i)] = i
+		}
+	}
+	for _, i := range b {
+		if i != nil {
+			after[interfaceKey(i)] = i
+		}
+	}
+	for _, key := range sortedKeys(before) {
+		cur, ok := after[key]
+		if !ok {
+			d.remove(areaInterface, key, "", before[key].URL, true)
+			continue
+		}
+		// Clients take the URL from the card, so a move is not breaking.
+		if cur.URL != before[key].URL {
+			d.change(areaInterface, key, "url", before[key].URL, cur.URL, false)
+		}
+	}
+	for _, key := range sortedKeys(after) {
+		if _, ok := before[key]; !ok {
+			d.add(areaInterface, key, "", after[key].URL, false)
+		}
+	}
+}
+
+func diffCapabilities(d *cardDiff, a, b a2a.AgentCapabilities) {
+	flags := []struct {
+		name          string
+		before, after bool
+	}{
+		{"streaming", a.Streaming, b.Streaming},
+		{"pushNotifications", a.PushNotifications, b.PushNotifications},
+		{"extendedAgentCard", a.ExtendedAgentCard, b.ExtendedAgentCard},
+	}
+	for _, f := range flags {
+		if f.before != f.after {
+			d.change(areaCapability, f.name, "", fmt.Sprint(f.before), fmt.Sprint(f.after), f.before)
+		}
+	}
+}
+
+// diffExtensions compares extensions by URI. Clients must cope without an
+// optional extension, so only new or newly required extensions break them.
+func diffExtensions(d *cardDiff, a, b []a2a.AgentExtension) {
+	before, after := map[string]a2a.AgentExtension{}, map[string]a2a.AgentExtension{}
+	for _, e := range a {
+		before[e.URI] = e
+	}
+	for _, e := range b {
+		after[e.URI] = e
+	}
+	for _, uri := range sortedKeys(before) {
+		e, ok := after[uri]
+		switch {
+		case !ok:
+			d.remove(areaExtension, uri, "", "", false)
+		case e.Required != before[uri].Required:
+			d.change(areaExtension, uri, "required", fmt.Sprint(before[uri].Required), fmt.Sprint(e.Required), e.Required)
+		case !reflect.DeepEqual(e.Params, before[uri].Params):
+			d.change(areaExtension, uri, "params", jsonString(before[uri].Params), jsonString(e.Params), false)
+		}
+	}
+	for _, uri := range sortedKeys(after) {
+		if _, ok := before[uri]; !ok {
+			detail := "optional"
+			if after[uri].Required {
+				detail = "required"
+			}
+			d.add(areaExtension, uri, detail, "", after[uri].Required)
+		}
+	}
+}
+
+// schemeDefinitions returns each security scheme as its JSON object, with
+// descriptions removed since they do not affect clients.
+func schemeDefinitions(s a2a.NamedSecuritySchemes) map[string]map[string]any {
+	out := map[string]map[string]any{}
+	if len(s) == 0 {
+		return out
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return out
+	}
+	_ = json.Unmarshal(data, &out)
+	for _, def := range out {
+		for _, v := range def {
+			if m, ok := v.(map[string]any); ok {
+				delete(m, "description")
+			}
+		}
+	}
+	return out
+}
+
+// schemeType names the kind of a scheme definition, e.g. oauth2.
+func schemeType(def map[string]any) string {
+	for k := range def {
+		return strings.TrimSuffix(k, "SecurityScheme")
+	}
+	return ""
+}
+
+func diffSchemes(d *cardDiff, a, b a2a.NamedSecuritySchemes) {
+	before, after := schemeDefinitions(a), schemeDefinitions(b)
+	for _, name := range sortedKeys(before) {
+		def, ok := after[name]
+		switch {
+		case !ok:
+			d.remove(areaSecurity, "scheme "+name, schemeType(before[name]), "", true)
+		case schemeType(def) != schemeType(before[name]):
+			d.change(areaSecurity, "scheme "+name, "type", schemeType(before[name]), schemeType(def), true)
+		case !reflect.DeepEqual(def, before[name]):
+			d.change(areaSecurity, "scheme "+name, "definition", jsonString(before[name]), jsonString(def), true)
+		}
+	}
+	for _, name := range sortedKeys(after) {
+		if _, ok := before[name]; !ok {
+			d.add(areaSecurity, "scheme "+name, schemeType(after[name]), "", false)
+		}
+	}
+}
+
+// requirementOptions renders each alternative of a requirement list, e.g.
+// "oauth(read,write) + apikey".
+func requirementOptions(opts a2a.SecurityRequirementsOptions) []string {
+	var out []string
+	for _, req := range opts {
+		var parts []string
+		for name, scopes := range req {
+			p := string(name)
+			if len(scopes) > 0 {
+				s := append([]string(nil), scopes...)
+				sort.Strings(s)
+				p += "(" + strings.Join(s, ",") + ")"
+			}
+			parts = append(parts, p)
+		}
+		sort.Strings(parts)
+		out = append(out, strings.Join(parts, " + "))
+	}
+	return out
+}
+
+// diffRequirements compares security requirements. Dropping an alternative
+// strands the clients that used it, and requiring credentials where none
+// were needed strands everyone.
+func diffRequirements(d *cardDiff, skill string, a, b a2a.SecurityRequirementsOptions) {
+	before, after := requirementOptions(a), requirementOptions(b)
+	removed, added := setDiff(before, after)
+	area, subject, prefix := areaSecurity, "requirement", ""
+	if skill != "" {
+		area, subject, prefix = areaSkill, skill, "security requirement "
+	}
+	for _, r := range removed {
+		d.remove(area, subject, prefix+r, r, true)
+	}
+	for _, r := range added {
+		d.add(area, subject, prefix+r, r, len(before) == 0)
+	}
+}
+
+func diffSkills(d *cardDiff, a, b []a2a.AgentSkill) {
+	before, after := map[string]a2a.AgentSkill{}, map[string]a2a.AgentSkill{}
+	for _, s := range a {
+		before[s.ID] = s
+	}
+	for _, s := range b {
+		after[s.ID] = s
+	}
+	for _, id := range sortedKeys(before) {
+		old := before[id]
+		s, ok := after[id]
+		if !ok {
+			d.remove(areaSkill, id, "", old.Name, true)
+			continue
+		}
+		if s.Name != old.Name {
+			d.change(areaSkill, id, "name", old.Name, s.Name, false)
+		}
+		if s.Description != old.Description {
+			d.change(areaSkill, id, "description", old.Description, s.Description, false)
+		}
+		if removed, added := setDiff(old.Tags, s.Tags); len(removed)+len(added) > 0 {
+			d.change(areaSkill, id, "tags", strings.Join(old.Tags, ", "), strings.Join(s.Tags, ", "), false)
+		}
+		if removed, added := setDiff(old.Examples, s.Examples); len(removed)+len(added) > 0 {
+			d.change(areaSkill, id, "examples", fmt.Sprint(len(old.Examples)), fmt.Sprint(len(s.Examples)), false)
+		}
+		diffModes(d, id, "input mode", old.InputModes, s.InputModes)
+		diffModes(d, id, "output mode", old.OutputModes, s.OutputModes)
+		diffRequirements(d, id, old.SecurityRequirements, s.SecurityRequirements)
+	}
+	for _, id := range sortedKeys(after) {
+		if _, ok := before[id]; !ok {
+			d.add(areaSkill, id, "", after[id].Name, false)
+		}
+	}
+}
+
+// setDiff returns the values only in a and only in b, sorted.
+func setDiff(a, b []string) (removed, added []string) {
+	inA, inB := map[string]bool{}, map[string]bool{}
+	for _, v := range a {
+		inA[v] = true
+	}
+	for _, v := range b {
+		inB[v] = true
+	}
+	for v := range inA {
+		if !inB[v] {
+			removed = append(removed, v)
+		}
+	}
+	for v := range inB {
+		if !inA[v] {
+			added = append(added, v)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return removed, added
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func jsonString(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func shorten(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
+
+// printCardDiff reports the changes from the card labelled from to the one
+// labelled to, and returns the number of breaking changes.
+func printCardDiff(from, to string, changes []cardChange) int {
+	breaking := 0
+	for _, c := range changes {
+		if c.Breaking {
+			breaking++
+		}
+	}
+	if disableTUI {
+		if changes == nil {
+			changes = []cardChange{}
+		}
+		b, _ := json.MarshalIndent(map[string]any{
+			"from":     from,
+			"to":       to,
+			"changes":  changes,
+			"breaking": breaking,
+		}, "", "  ")
+		fmt.Println(string(b))
+		return breaking
+	}
+
+	fmt.Printf("%s %s\n%s %s\n\n", StyleMuted.Render("---"), from, StyleMuted.Render("+++"), to)
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return 0
+	}
+	for _, c := range changes {
+		mark, style := "~", StyleWarn
+		switch c.Kind {
+		case "added":
+			mark, style = "+", StylePass
+		case "removed":
+			mark, style = "-", StyleMuted
+		}
+		if c.Breaking {
+			style = StyleFail
+		}
+		line := fmt.Sprintf("%s %-10s %s", mark, c.Area, c.Subject)
+		if c.Detail != "" {
+			line += ": " + c.Detail
+		}
+		if c.Kind == "changed" {
+			line += fmt.Sprintf(" (%s → %s)", dash(shorten(c.Before, 40)), dash(shorten(c.After, 40)))
+		}
+		if c.Breaking {
+			line += " [breaking]"
+		}
+		fmt.Println(style.Render(line))
+	}
+	fmt.Printf("\n%d change(s), %d breaking\n", len(changes), breaking)
+	return breaking
+}
+
+// exitOnBreaking exits non-zero when a diff found breaking changes.
+func exitOnBreaking(breaking int) {
+	if breaking > 0 {
+		os.Exit(1)
+	}
+}
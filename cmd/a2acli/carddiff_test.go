@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+func diffTestCard() *a2a.AgentCard {
+	return &a2a.AgentCard{
+		Name:    "Reports",
+		Version: "1.0.0",
+		SupportedInterfaces: []*a2a.AgentInterface{
+			{URL: "https://agent.example.com", ProtocolBinding: a2a.TransportProtocolJSONRPC, ProtocolVersion: "1.0"},
+			{URL: "https://agent.example.com:443", ProtocolBinding: a2a.TransportProtocolGRPC, ProtocolVersion: "1.0"},
+		},
+		Capabilities: a2a.AgentCapabilities{
+			Streaming:  true,
+			Extensions: []a2a.AgentExtension{{URI: "https://ext.example.com/trace"}},
+		},
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain", "application/pdf"},
+		SecuritySchemes: a2a.NamedSecuritySchemes{
+			"bearer": a2a.HTTPAuthSecurityScheme{Scheme: "Bearer"},
+		},
+		Skills: []a2a.AgentSkill{
+			{ID: "summarize", Name: "Summarize", Tags: []string{"text"}},
+			{ID: "chart", Name: "Chart", OutputModes: []string{"image/png"}},
+		},
+	}
+}
+
+func findChange(changes []cardChange, area, kind, subject string) *cardChange {
+	for i, c := range changes {
+		if c.Area == area && c.Kind == kind && c.Subject == subject {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestDiffCardsUnchanged(t *testing.T) {
+	if changes := diffCards(diffTestCard(), diffTestCard()); len(changes) != 0 {
+		t.Errorf("identical cards differ: %+v", changes)
+	}
+}
+
+func TestDiffCards(t *testing.T) {
+	a, b := diffTestCard(), diffTestCard()
+	b.Version = "1.1.0"
+	b.SupportedInterfaces = []*a2a.AgentInterface{
+		{URL: "https://api.example.com", ProtocolBinding: a2a.TransportProtocolJSONRPC, ProtocolVersion: "1.0"},
+		{URL: "https://api.example.com/rest", ProtocolBinding: a2a.TransportProtocolHTTPJSON, ProtocolVersion: "1.0"},
+	}
+	b.Capabilities.Streaming = false
+	b.Capabilities.PushNotifications = true
+	b.Capabilities.Extensions = []a2a.AgentExtension{{URI: "https://ext.example.com/trace", Required: true}}
+	b.DefaultOutputModes = []string{"text/plain"}
+	b.SecuritySchemes = a2a.NamedSecuritySchemes{
+		"bearer": a2a.HTTPAuthSecurityScheme{Scheme: "Bearer", Description: "now documented"},
+		"apikey": a2a.APIKeySecurityScheme{Name: "X-Api-Key", Location: a2a.APIKeySecuritySchemeLocationHeader},
+	}
+	b.SecurityRequirements = a2a.SecurityRequirementsOptions{{"bearer": {}}}
+	b.Skills = []a2a.AgentSkill{
+		{ID: "summarize", Name: "Summarize", Tags: []string{"text", "nlp"}, InputModes: []string{"text/plain"}},
+		{ID: "translate", Name: "Translate"},
+	}
+
+	changes := diffCards(a, b)
+	want := []struct {
+		area, kind, subject string
+		breaking            bool
+	}{
+		{areaCard, "changed", "version", false},
+		{areaInterface, "changed", "JSONRPC 1.0", false},
+		{areaInterface, "removed", "GRPC 1.0", true},
+		{areaInterface, "added", "HTTP+JSON 1.0", false},
+		{areaCapability, "changed", "streaming", true},
+		{areaCapability, "changed", "pushNotifications", false},
+		{areaExtension, "changed", "https://ext.example.com/trace", true},
+		{areaModes, "removed", "default output mode", true},
+		{areaSecurity, "added", "scheme apikey", false},
+		{areaSecurity, "added", "requirement", true},
+		{areaSkill, "changed", "summarize", false},
+		{areaSkill, "added", "summarize", false},
+		{areaSkill, "removed", "chart", true},
+		{areaSkill, "added", "translate", false},
+	}
+	for _, w := range want {
+		c := findChange(changes, w.area, w.kind, w.subject)
+		if c == nil {
+			t.Errorf("missing %s %s %s in %+v", w.area, w.kind, w.subject, changes)
+			continue
+		}
+		if c.Breaking != w.breaking {
+			t.Errorf("%s %s %s: breaking = %v, want %v", w.area, w.kind, w.subject, c.Breaking, w.breaking)
+		}
+	}
+	// A description does not change what clients must send.
+	if c := findChange(changes, areaSecurity, "changed", "scheme bearer"); c != nil {
+		t.Errorf("description-only scheme change reported: %+v", c)
+	}
+	if len(changes) != len(want) {
+		t.Errorf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+}
+
+func TestDiffRequirements(t *testing.T) {
+	a, b := diffTestCard(), diffTestCard()
+	a.SecurityRequirements = a2a.SecurityRequirementsOptions{{"bearer": {}}, {"oauth": {"write", "read"}}}
+	b.SecurityRequirements = a2a.SecurityRequirementsOptions{{"oauth": {"read", "write"}}, {"mtls": {}}}
+	changes := diffCards(a, b)
+	if c := findChange(changes, areaSecurity, "removed", "requirement"); c == nil || !c.Breaking || c.Before != "bearer" {
+		t.Errorf("removed alternative = %+v", c)
+	}
+	// Another alternative for clients that already authenticate is harmless.
+	if c := findChange(changes, areaSecurity, "added", "requirement"); c == nil || c.Breaking || c.After != "mtls" {
+		t.Errorf("added alternative = %+v", c)
+	}
+	if len(changes) != 2 {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestParseAgentCard(t *testing.T) {
+	v1, err := parseAgentCard([]byte(`{"name":"New","supportedInterfaces":[{"url":"http://a","protocolBinding":"JSONRPC","protocolVersion":"1.0"}]}`))
+	if err != nil || v1.Name != "New" || len(v1.SupportedInterfaces) != 1 {
+		t.Fatalf("1.0 card = %+v, %v", v1, err)
+	}
+	v0, err := parseAgentCard([]byte(`{"name":"Old","url":"http://b","protocolVersion":"0.3.0","preferredTransport":"JSONRPC","capabilities":{},"skills":[]}`))
+	if err != nil || v0.Name != "Old" || len(v0.SupportedInterfaces) == 0 || v0.SupportedInterfaces[0].URL != "http://b" {
+		t.Fatalf("0.3 card = %+v, %v", v0, err)
+	}
+	if _, err := parseAgentCard([]byte(`not json`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/ghchinoy/a2acli/internal/cardlint"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cardSpec is what `card init` and `init agent` ask for to build a card.
+type cardSpec struct {
+	Name        string
+	Description string
+	Version     string
+	// URL is the base URL of the HTTP interfaces.
+	URL string
+	// Bindings are jsonrpc, rest and grpc.
+	Bindings []string
+	GRPCAddr string
+	// Security is none or a key of securitySchemeKinds.
+	Security     string
+	APIKeyHeader string
+	TokenURL     string
+	Scopes       []string
+	OIDCURL      string
+	Streaming    bool
+	Push         bool
+	Extended     bool
+	InputModes   []string
+	OutputModes  []string
+	// Skills are the --skill values or the skills answered at the prompts.
+	// An empty name or description is filled in by buildCard.
+	Skills []a2a.AgentSkill
+}
+
+// securitySchemeKinds are the security schemes a card can be created with,
+// by the name they are asked for as.
+var securitySchemeKinds = []string{"none", "bearer", "apikey", "oauth2", "oidc", "mtls"}
+
+// defaultDescription describes agents and skills that were not described.
+const defaultDescription = "An A2A agent"
+
+var bindingNames = map[string]a2a.TransportProtocol{
+	"jsonrpc": a2a.TransportProtocolJSONRPC,
+	"rest":    a2a.TransportProtocolHTTPJSON,
+	"grpc":    a2a.TransportProtocolGRPC,
+}
+
+var (
+	initSpec     cardSpec
+	initDefaults bool
+	initFile     string
+	initForce    bool
+)
+
+// addCardSpecFlags adds the flags that answer the card questions ahead.
+func addCardSpecFlags(fs *pflag.FlagSet, s *cardSpec) {
+	fs.StringVar(&s.Name, "name", "", "Agent name")
+	fs.StringVar(&s.Description, "description", "", "What the agent does")
+	fs.StringVar(&s.Version, "agent-version", "1.0.0", "Version of the agent")
+	fs.StringVar(&s.URL, "url", "http://localhost:9001", "Base URL of the agent's HTTP interfaces")
+	fs.StringSliceVar(&s.Bindings, "binding", []string{"jsonrpc"}, "Protocol bindings: jsonrpc, rest, grpc (repeatable)")
+	fs.StringVar(&s.GRPCAddr, "grpc-addr", "", "host:port of the gRPC interface (default: the URL's host, port + 1)")
+	fs.StringVar(&s.Security, "security", "none", "Security scheme: "+strings.Join(securitySchemeKinds, ", "))
+	fs.StringVar(&s.APIKeyHeader, "api-key-header", "X-API-Key", "Header of --security apikey")
+	fs.StringVar(&s.TokenURL, "token-url", "", "Token endpoint of --security oauth2 (client credentials)")
+	fs.StringSliceVar(&s.Scopes, "scopes", nil, "Scopes of --security oauth2 the agent requires")
+	fs.StringVar(&s.OIDCURL, "oidc-url", "", "OpenID Connect discovery URL of --security oidc")
+	fs.BoolVar(&s.Streaming, "streaming", true, "The agent streams task updates")
+	fs.BoolVar(&s.Push, "push-notifications", false, "The agent sends push notifications")
+	fs.BoolVar(&s.Extended, "extended-card", false, "The agent serves an extended card to authenticated clients")
+	fs.StringSliceVar(&s.InputModes, "input-modes", []string{"text/plain"}, "Media types the agent accepts")
+	fs.StringSliceVar(&s.OutputModes, "output-modes", []string{"text/plain"}, "Media types the agent returns")
+	fs.Var(skillsFlag{&s.Skills}, "skill", "Skill, as id or id=description (repeatable)")
+}
+
+// skillsFlag parses --skill values, id or id=description, into skills.
+type skillsFlag struct{ skills *[]a2a.AgentSkill }
+
+func (f skillsFlag) Set(v string) error {
+	id, desc, _ := strings.Cut(v, "=")
+	if id = strings.TrimSpace(id); id == "" {
+		return fmt.Errorf("skill %q has no ID", v)
+	}
+	*f.skills = append(*f.skills, a2a.AgentSkill{ID: id, Description: strings.TrimSpace(desc)})
+	return nil
+}
+
+func (f skillsFlag) String() string {
+	if len(*f.skills) == 0 {
+		return ""
+	}
+	ids := make([]string, len(*f.skills))
+	for i, skill := range *f.skills {
+		ids[i] = skill.ID
+	}
+	return "[" + strings.Join(ids, ",") + "]"
+}
+
+func (skillsFlag) Type() string { return "stringArray" }
+
+// setupCardInitCmd builds `card init`.
+func setupCardInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create an A2A 1.0 AgentCard by answering a few questions",
+		Long: `Create an A2A 1.0 AgentCard. The command asks for the agent's name and
+description, its URL and protocol bindings, a security scheme, capabilities,
+media types and skills, and writes a card that passes 'a2acli card lint'.
+
+Each question shows its default in brackets; press Enter to accept it. Flags
+answer questions ahead of time and become their defaults. With --defaults
+nothing is asked, so the card can be created in scripts.
+
+JSON-RPC is served at <url>/invoke and HTTP+JSON at <url>/, the layout of
+'a2acli init agent'.`,
+		Example: `  a2acli card init
+  a2acli card init --name weather --binding jsonrpc,rest --security bearer -f weather-card.json
+  a2acli card init --defaults --name echo --skill "echo=Repeats the message" -f -`,
+		Args: cobra.NoArgs,
+		Run:  runCardInit,
+	}
+	addCardSpecFlags(cmd.Flags(), &initSpec)
+	cmd.Flags().BoolVarP(&initDefaults, "defaults", "y", false, "Ask nothing; use the flags and defaults")
+	cmd.Flags().StringVarP(&initFile, "file", "f", "agent-card.json", "File to write the card to (- for stdout)")
+	cmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing file")
+	return cmd
+}
+
+func runCardInit(cmd *cobra.Command, _ []string) {
+	if initFile != "-" && !initForce {
+		if _, err := os.Stat(initFile); err == nil {
+			fatalCode(ErrCodeInvalidArgument, "file exists", errors.New(initFile), "Pass --force to overwrite it, or -f to choose another file")
+		}
+	}
+	flags := cmd.Flags()
+	if !initDefaults {
+		askCardSpec(newPrompter(os.Stdin, os.Stderr), &initSpec, flags)
+	}
+	card, err := buildCard(&initSpec)
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid card", err, "")
+	}
+	raw, _ := json.MarshalIndent(card, "", "  ")
+	raw = append(raw, '\n')
+
+	out := os.Stdout
+	if initFile == "-" {
+		_, _ = os.Stdout.Write(raw)
+		out = os.Stderr
+	} else if err := os.WriteFile(initFile, raw, 0o644); err != nil {
+		fatalf("failed to write card", err, "")
+	}
+
+	findings := cardlint.Lint(cmd.Context(), raw, card, cardlint.Options{Offline: true})
+	if disableTUI {
+		if initFile == "-" {
+			return
+		}
+		if findings == nil {
+			findings = []cardlint.Finding{}
+		}
+		b, _ := json.MarshalIndent(map[string]any{"file": initFile, "agent": card.Name, "findings": findings}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	if initFile != "-" {
+		fmt.Fprintf(out, "Wrote %s\n", initFile)
+	}
+	printCardInitLint(out, initFile, card, findings)
+}
+
+// printCardInitLint reports what linting a new card found, and what to do
+// next.
+func printCardInitLint(out io.Writer, file string, card *a2a.AgentCard, findings []cardlint.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintf(out, "%s passes 'a2acli card lint'\n", StylePass.Render("✓"))
+	}
+	for _, f := range findings {
+		fmt.Fprintf(out, "%s %s %s\n", StyleWarn.Render(string(f.Severity)), f.Message, StyleMuted.Render("["+f.Rule+"]"))
+	}
+	for _, iface := range card.SupportedInterfaces {
+		if u, err := url.Parse(iface.URL); err == nil && u.Scheme == "http" && cardlint.IsLoopback(u.Hostname()) {
+			fmt.Fprintln(out, StyleMuted.Render("The interfaces point at this machine; change their URLs to the agent's HTTPS address before publishing the card."))
+			break
+		}
+	}
+	if file != "-" {
+		fmt.Fprintf(out, "Next: %s\n", StyleCommand.Render("a2acli init agent --lang go --card "+file))
+	}
+}
+
+// prompter asks questions on out and reads the answers from in. Once in is
+// exhausted, every question takes its default.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	eof bool
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask asks a question and returns the answer, or def for an empty answer.
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s %s: ", question, StyleMuted.Render("["+def+"]"))
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	if p.eof {
+		fmt.Fprintln(p.out)
+		return def
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil {
+		p.eof = true
+		if line == "" {
+			fmt.Fprintln(p.out)
+		}
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// askList asks for a comma-separated list.
+func (p *prompter) askList(question string, def []string) []string {
+	return splitList(p.ask(question, strings.Join(def, ", ")))
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		switch strings.ToLower(p.ask(question+" ("+hint+")", "")) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+		if p.eof {
+			return def
+		}
+		fmt.Fprintln(p.out, StyleWarn.Render("Answer y or n."))
+	}
+}
+
+// choose asks for one of options, and asks again until it gets one or the
+// input ends.
+func (p *prompter) choose(question string, options []string, def string) string {
+	for {
+		answer := strings.ToLower(p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), def))
+		if slices.Contains(options, answer) || p.eof {
+			return answer
+		}
+		fmt.Fprintf(p.out, "%s\n", StyleWarn.Render("Choose one of: "+strings.Join(options, ", ")))
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// askCardSpec fills s from the answers to p's questions. Flags that were set
+// are still asked, with their values as the defaults.
+func askCardSpec(p *prompter, s *cardSpec, flags *pflag.FlagSet) {
+	if s.Name == "" {
+		wd, _ := os.Getwd()
+		s.Name = slug(filepath.Base(wd))
+	}
+	s.Name = p.ask("Agent name", s.Name)
+	s.Description = p.ask("What does the agent do?", cmp.Or(s.Description, defaultDescription))
+	s.Version = p.ask("Agent version", s.Version)
+	s.URL = p.ask("Base URL of the agent", s.URL)
+
+	for {
+		bindings := p.askList("Protocol bindings (jsonrpc, rest, grpc)", s.Bindings)
+		err := checkBindings(bindings)
+		if err == nil {
+			s.Bindings = bindings
+			break
+		}
+		fmt.Fprintln(p.out, StyleWarn.Render(err.Error()))
+		if p.eof {
+			break
+		}
+	}
+	if slices.Contains(s.Bindings, "grpc") {
+		s.GRPCAddr = p.ask("gRPC address (host:port)", defaultGRPCAddr(s))
+	}
+
+	s.Security = p.choose("Security scheme", securitySchemeKinds, s.Security)
+	switch s.Security {
+	case "apikey":
+		s.APIKeyHeader = p.ask("API key header", s.APIKeyHeader)
+	case "oauth2":
+		s.TokenURL = p.ask("OAuth 2.0 token URL", s.TokenURL)
+		s.Scopes = p.askList("Scopes the agent requires", s.Scopes)
+	case "oidc":
+		s.OIDCURL = p.ask("OpenID Connect discovery URL", s.OIDCURL)
+	}
+
+	s.Streaming = p.confirm("Does the agent stream task updates?", s.Streaming)
+	s.Push = p.confirm("Does the agent send push notifications?", s.Push)
+	s.Extended = p.confirm("Does the agent serve an extended card to authenticated clients?", s.Extended)
+	s.InputModes = p.askList("Media types the agent accepts", s.InputModes)
+	s.OutputModes = p.askList("Media types the agent returns", s.OutputModes)
+
+	if flags.Changed("skill") {
+		return
+	}
+	fmt.Fprintln(p.out, StyleMuted.Render("Skills: what clients can ask the agent to do. Leave the ID empty when done."))
+	for i := 0; ; i++ {
+		def := ""
+		if i == 0 {
+			def = slug(s.Name)
+		}
+		id := p.ask(fmt.Sprintf("Skill %d ID", i+1), def)
+		if id == "" {
+			break
+		}
+		name := p.ask("  Name", titleCase(id))
+		desc := p.ask("  Description", "")
+		for desc == "" && !p.eof {
+			desc = p.ask("  Description (clients and LLMs choose skills by it)", "")
+		}
+		tags := p.askList("  Tags", nil)
+		examples := p.askList("  Example requests", nil)
+		s.Skills = append(s.Skills, a2a.AgentSkill{ID: id, Name: name, Description: desc, Tags: tags, Examples: examples})
+		if p.eof {
+			break
+		}
+	}
+}
+
+func checkBindings(bindings []string) error {
+	if len(bindings) == 0 {
+		return errors.New("choose at least one protocol binding: jsonrpc, rest or grpc")
+	}
+	for _, b := range bindings {
+		if _, ok := bindingNames[strings.ToLower(b)]; !ok {
+			return fmt.Errorf("unknown protocol binding %q; use jsonrpc, rest or grpc", b)
+		}
+	}
+	return nil
+}
+
+// defaultGRPCAddr is the URL's host with the next port.
+func defaultGRPCAddr(s *cardSpec) string {
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Hostname() == "" {
+		return "localhost:9002"
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		port = 443
+		if u.Scheme == "http" {
+			port = 80
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), strconv.Itoa(port+1))
+}
+
+// buildCard builds an A2A 1.0 card from s.
+func buildCard(s *cardSpec) (*a2a.AgentCard, error) {
+	if strings.TrimSpace(s.Name) == "" {
+		return nil, errors.New("the agent needs a name (--name)")
+	}
+	u, err := url.Parse(strings.TrimSuffix(s.URL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an http or https URL", s.URL)
+	}
+	if err := checkBindings(s.Bindings); err != nil {
+		return nil, err
+	}
+
+	card := &a2a.AgentCard{
+		Name:               s.Name,
+		Description:        cmp.Or(s.Description, defaultDescription),
+		Version:            s.Version,
+		DefaultInputModes:  s.InputModes,
+		DefaultOutputModes: s.OutputModes,
+		Capabilities: a2a.AgentCapabilities{
+			Streaming:         s.Streaming,
+			PushNotifications: s.Push,
+			ExtendedAgentCard: s.Extended,
+		},
+	}
+	for _, b := range s.Bindings {
+		binding := bindingNames[strings.ToLower(b)]
+		switch binding {
+		case a2a.TransportProtocolJSONRPC:
+			card.SupportedInterfaces = append(card.SupportedInterfaces, a2a.NewAgentInterface(u.String()+"/invoke", binding))
+		case a2a.TransportProtocolHTTPJSON:
+			card.SupportedInterfaces = append(card.SupportedInterfaces, a2a.NewAgentInterface(u.String()+"/", binding))
+		case a2a.TransportProtocolGRPC:
+			addr := s.GRPCAddr
+			if addr == "" {
+				addr = defaultGRPCAddr(s)
+			}
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return nil, fmt.Errorf("gRPC address %q is not host:port", addr)
+			}
+			card.SupportedInterfaces = append(card.SupportedInterfaces, a2a.NewAgentInterface(addr, binding))
+		}
+	}
+
+	scopes := a2a.SecuritySchemeScopes{}
+	var name a2a.SecuritySchemeName
+	var scheme a2a.SecurityScheme
+	switch s.Security {
+	case "", "none":
+	case "bearer":
+		name, scheme = "bearer", a2a.HTTPAuthSecurityScheme{Scheme: "Bearer", BearerFormat: "JWT"}
+	case "apikey":
+		name, scheme = "apiKey", a2a.APIKeySecurityScheme{Location: a2a.APIKeySecuritySchemeLocationHeader, Name: s.APIKeyHeader}
+	case "oauth2":
+		if !strings.HasPrefix(s.TokenURL, "https://") && !strings.HasPrefix(s.TokenURL, "http://") {
+			return nil, errors.New("--security oauth2 needs the token endpoint (--token-url)")
+		}
+		flow := a2a.ClientCredentialsOAuthFlow{TokenURL: s.TokenURL, Scopes: map[string]string{}}
+		for _, sc := range s.Scopes {
+			flow.Scopes[sc] = ""
+			scopes = append(scopes, sc)
+		}
+		name, scheme = "oauth2", a2a.OAuth2SecurityScheme{Flows: flow}
+	case "oidc":
+		if !strings.HasPrefix(s.OIDCURL, "https://") && !strings.HasPrefix(s.OIDCURL, "http://") {
+			return nil, errors.New("--security oidc needs the discovery URL (--oidc-url)")
+		}
+		name, scheme = "oidc", a2a.OpenIDConnectSecurityScheme{OpenIDConnectURL: s.OIDCURL}
+	case "mtls":
+		name, scheme = "mtls", a2a.MutualTLSSecurityScheme{}
+	default:
+		return nil, fmt.Errorf("unknown security scheme %q; use one of %s", s.Security, strings.Join(securitySchemeKinds, ", "))
+	}
+	if scheme != nil {
+		card.SecuritySchemes = a2a.NamedSecuritySchemes{name: scheme}
+		card.SecurityRequirements = a2a.SecurityRequirementsOptions{{name: scopes}}
+	}
+
+	skills := s.Skills
+	if len(skills) == 0 {
+		skills = []a2a.AgentSkill{{ID: slug(s.Name)}}
+	}
+	for i, skill := range skills {
+		if skill.ID == "" {
+			return nil, fmt.Errorf("skill %d has no ID", i+1)
+		}
+		skill.Name = cmp.Or(skill.Name, titleCase(skill.ID))
+		skill.Description = cmp.Or(skill.Description, s.Description, defaultDescription)
+		if skill.Tags == nil {
+			skill.Tags = []string{}
+		}
+		card.Skills = append(card.Skills, skill)
+	}
+	return card, nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug lower-cases s and joins its words with hyphens.
+func slug(s string) string {
+	s = strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if s == "" {
+		return "agent"
+	}
+	return s
+}
+
+// titleCase turns an ID such as get_forecast into Get Forecast.
+func titleCase(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool { return r == '-' || r == '_' || r == '.' || unicode.IsSpace(r) })
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/ghchinoy/a2acli/internal/cardlint"
+	"github.com/spf13/pflag"
+)
+
+// specWithDefaults returns a cardSpec holding the flag defaults.
+func specWithDefaults(t *testing.T) (*cardSpec, *pflag.FlagSet) {
+	t.Helper()
+	var s cardSpec
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	addCardSpecFlags(fs, &s)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	return &s, fs
+}
+
+func lintClean(t *testing.T, card *a2a.AgentCard) {
+	t.Helper()
+	raw, err := json.Marshal(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range cardlint.Lint(context.Background(), raw, card, cardlint.Options{Offline: true}) {
+		t.Errorf("lint: %s %s at %s: %s", f.Severity, f.Rule, f.Path, f.Message)
+	}
+}
+
+func TestBuildCardDefaults(t *testing.T) {
+	s, _ := specWithDefaults(t)
+	s.Name = "Echo Agent"
+	card, err := buildCard(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lintClean(t, card)
+	if len(card.SupportedInterfaces) != 1 || card.SupportedInterfaces[0].URL != "http://localhost:9001/invoke" {
+		t.Errorf("interfaces = %+v", card.SupportedInterfaces)
+	}
+	if len(card.Skills) != 1 || card.Skills[0].ID != "echo-agent" || card.Skills[0].Description == "" {
+		t.Errorf("skills = %+v", card.Skills)
+	}
+	if card.SecuritySchemes != nil || card.SecurityRequirements != nil {
+		t.Errorf("security = %+v %+v", card.SecuritySchemes, card.SecurityRequirements)
+	}
+}
+
+func TestBuildCardSecurity(t *testing.T) {
+	for _, sec := range securitySchemeKinds[1:] {
+		t.Run(sec, func(t *testing.T) {
+			s, _ := specWithDefaults(t)
+			s.Name = "secure"
+			s.Bindings = []string{"jsonrpc", "rest", "grpc"}
+			s.Security = sec
+			s.TokenURL = "https://auth.example.com/token"
+			s.Scopes = []string{"a2a"}
+			s.OIDCURL = "https://id.example.com/.well-known/openid-configuration"
+			card, err := buildCard(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lintClean(t, card)
+			if len(card.SecuritySchemes) != 1 || len(card.SecurityRequirements) != 1 {
+				t.Errorf("security = %+v %+v", card.SecuritySchemes, card.SecurityRequirements)
+			}
+			if got := card.SupportedInterfaces[2].URL; got != "localhost:9002" {
+				t.Errorf("gRPC address = %q", got)
+			}
+		})
+	}
+}
+
+func TestBuildCardErrors(t *testing.T) {
+	for name, edit := range map[string]func(*cardSpec){
+		"no name":        func(s *cardSpec) { s.Name = "" },
+		"bad url":        func(s *cardSpec) { s.URL = "localhost:9001" },
+		"no bindings":    func(s *cardSpec) { s.Bindings = nil },
+		"bad binding":    func(s *cardSpec) { s.Bindings = []string{"soap"} },
+		"bad security":   func(s *cardSpec) { s.Security = "kerberos" },
+		"oauth2 no url":  func(s *cardSpec) { s.Security = "oauth2" },
+		"bad grpc addr":  func(s *cardSpec) { s.Bindings, s.GRPCAddr = []string{"grpc"}, "nowhere" },
+		"empty skill id": func(s *cardSpec) { s.Skills = []a2a.AgentSkill{{Description: "does things"}} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s, _ := specWithDefaults(t)
+			s.Name = "x"
+			edit(s)
+			if _, err := buildCard(s); err == nil {
+				t.Error("err = nil")
+			}
+		})
+	}
+}
+
+func TestSkillFlag(t *testing.T) {
+	var s cardSpec
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	addCardSpecFlags(fs, &s)
+	if err := fs.Parse([]string{"--name", "echo", "--description", "Echoes", "--skill", "echo", "--skill", " shout = Repeats it loudly "}); err != nil {
+		t.Fatal(err)
+	}
+	card, err := buildCard(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []a2a.AgentSkill{
+		{ID: "echo", Name: "Echo", Description: "Echoes", Tags: []string{}},
+		{ID: "shout", Name: "Shout", Description: "Repeats it loudly", Tags: []string{}},
+	}
+	if len(card.Skills) != len(want) {
+		t.Fatalf("skills = %+v", card.Skills)
+	}
+	for i, w := range want {
+		if got := card.Skills[i]; got.ID != w.ID || got.Name != w.Name || got.Description != w.Description || got.Tags == nil {
+			t.Errorf("skill %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addCardSpecFlags(fs, &cardSpec{})
+	if err := fs.Parse([]string{"--skill", "=does things"}); err == nil {
+		t.Error("a skill without an ID was accepted")
+	}
+}
+
+func TestAskCardSpec(t *testing.T) {
+	s, fs := specWithDefaults(t)
+	answers := strings.Join([]string{
+		"Trip Planner", // name
+		"Plans trips",  // description
+		"",             // version
+		"https://trips.example.com",
+		"jsonrpc,soap",  // rejected
+		"jsonrpc, rest", // bindings
+		"kerberos",      // rejected
+		"apikey",        // security
+		"X-Key",         // header
+		"n",             // streaming
+		"maybe",         // rejected
+		"y",             // push
+		"",              // extended card
+		"",              // input modes
+		"text/plain, application/json",
+		"plan",           // skill ID
+		"",               // name
+		"",               // rejected: skills need a description
+		"Plans a trip",   // description
+		"travel, europe", // tags
+		"Plan a weekend in Rome",
+		"", // no more skills
+	}, "\n") + "\n"
+	askCardSpec(newPrompter(strings.NewReader(answers), io.Discard), s, fs)
+
+	card, err := buildCard(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lintClean(t, card)
+	if card.Name != "Trip Planner" || card.Description != "Plans trips" || card.Version != "1.0.0" {
+		t.Errorf("card = %s %q %s", card.Name, card.Description, card.Version)
+	}
+	if len(card.SupportedInterfaces) != 2 || card.SupportedInterfaces[1].URL != "https://trips.example.com/" {
+		t.Errorf("interfaces = %+v", card.SupportedInterfaces)
+	}
+	if k, ok := card.SecuritySchemes["apiKey"].(a2a.APIKeySecurityScheme); !ok || k.Name != "X-Key" {
+		t.Errorf("security schemes = %+v", card.SecuritySchemes)
+	}
+	if card.Capabilities.Streaming || !card.Capabilities.PushNotifications || card.Capabilities.ExtendedAgentCard {
+		t.Errorf("capabilities = %+v", card.Capabilities)
+	}
+	if len(card.DefaultOutputModes) != 2 {
+		t.Errorf("output modes = %v", card.DefaultOutputModes)
+	}
+	want := a2a.AgentSkill{ID: "plan", Name: "Plan", Description: "Plans a trip", Tags: []string{"travel", "europe"}, Examples: []string{"Plan a weekend in Rome"}}
+	if len(card.Skills) != 1 || card.Skills[0].ID != want.ID || card.Skills[0].Name != want.Name ||
+		card.Skills[0].Description != want.Description || strings.Join(card.Skills[0].Tags, ",") != "travel,europe" ||
+		len(card.Skills[0].Examples) != 1 {
+		t.Errorf("skills = %+v, want %+v", card.Skills, want)
+	}
+}
+
+func TestAskCardSpecEOF(t *testing.T) {
+	// Once the input ends every question takes its default, including the
+	// ones whose default is not a valid answer.
+	s, fs := specWithDefaults(t)
+	s.Security = "kerberos"
+	askCardSpec(newPrompter(strings.NewReader("only-a-name"), io.Discard), s, fs)
+	if s.Name != "only-a-name" || s.Security != "kerberos" || len(s.Skills) != 1 {
+		t.Errorf("spec = %+v", s)
+	}
+}
+
+func TestSlugAndTitle(t *testing.T) {
+	for in, want := range map[string]string{"Weather Agent!": "weather-agent", "--": "agent", "a2a_v1": "a2a-v1"} {
+		if got := slug(in); got != want {
+			t.Errorf("slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := titleCase("get_forecast-now"); got != "Get Forecast Now" {
+		t.Errorf("titleCase = %q", got)
+	}
+}
+
+func TestCardInitLoopbackNote(t *testing.T) {
+	// card init's note about local URLs follows the loopback rule of
+	// card lint's interface-https check.
+	for url, local := range map[string]bool{
+		"http://localhost:9001":       true,
+		"http://agent.localhost:9001": true,
+		"http://127.0.0.1:9001":       true,
+		"http://agent.example.com":    false,
+	} {
+		s, _ := specWithDefaults(t)
+		s.Name, s.URL = "local", url
+		card, err := buildCard(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := json.Marshal(card)
+		findings := cardlint.Lint(context.Background(), raw, card, cardlint.Options{Offline: true})
+		var out strings.Builder
+		printCardInitLint(&out, "-", card, findings)
+		if got := strings.Contains(out.String(), "point at this machine"); got != local {
+			t.Errorf("%s: local note = %v, want %v", url, got, local)
+		}
+		insecure := false
+		for _, f := range findings {
+			insecure = insecure || f.Rule == "interface-https"
+		}
+		if insecure == local {
+			t.Errorf("%s: interface-https finding = %v, want %v", url, insecure, !local)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ghchinoy/a2acli/internal/cardlint"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	lintOffline   bool
+	lintSuppress  []string
+	lintMaxSize   int
+	lintFormat    string
+	lintListRules bool
+)
+
+// setupCardLintCmd builds `card lint`.
+func setupCardLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [source]",
+		Short: "Check an AgentCard for problems clients will hit",
+		Long: `Check an AgentCard against a set of rules: required fields, media-type
+modes, HTTPS interface URLs, security requirements that name undefined
+schemes, duplicate skill IDs, protocol versions, card size, and (unless
+--offline) whether the interface URLs accept connections and the
+documentation, icon and provider URLs resolve.
+
+The source is any card source (see 'a2acli card'); it defaults to the service
+URL. Run --list-rules for the rules, their IDs and severities.
+
+A finding is suppressed with --suppress <rule>, or --suppress <rule>:<path>
+to suppress it only at a path and below, e.g.
+interface-https:supportedInterfaces[1]. Suppressions can also be listed under
+card_lint.suppress in the config, e.g. in a project's .a2acli.yaml.
+
+The command exits 1 when an unsuppressed error is found, or with --strict a
+warning. --format sarif writes SARIF 2.1.0 for code scanning services.`,
+		Example: `  a2acli card lint agent-card.json
+  a2acli card lint https://agent.example.com --offline
+  a2acli card lint env:prod --suppress unused-security-scheme
+  a2acli card lint build/agent-card.json --format sarif > card-lint.sarif`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runCardLint,
+	}
+	cmd.Flags().BoolVar(&lintOffline, "offline", false, "Skip the rules that make network requests")
+	cmd.Flags().StringSliceVar(&lintSuppress, "suppress", nil, "Suppress a rule, or rule:path (repeatable)")
+	cmd.Flags().IntVar(&lintMaxSize, "max-size", cardlint.DefaultMaxSize>>10, "Card size in KiB above which oversized-card reports it")
+	cmd.Flags().StringVar(&lintFormat, "format", "", "Output format: text, json or sarif (default: json with -o json, else text)")
+	cmd.Flags().BoolVar(&lintListRules, "list-rules", false, "List the rules and exit")
+	return cmd
+}
+
+func runCardLint(cmd *cobra.Command, args []string) {
+	format := lintFormat
+	if format == "" {
+		format = "text"
+		if disableTUI {
+			format = "json"
+		}
+	}
+	switch format {
+	case "text", "json", "sarif":
+	default:
+		fatalCode(ErrCodeInvalidArgument, "invalid --format", fmt.Errorf("%q", format), "Use text, json or sarif")
+	}
+
+	if lintListRules {
+		printLintRules(format)
+		return
+	}
+
+	suppress := append(viper.GetStringSlice("card_lint.suppress"), lintSuppress...)
+	for _, s := range suppress {
+		id, _, _ := strings.Cut(s, ":")
+		if _, ok := cardlint.Lookup(id); !ok {
+			fatalCode(ErrCodeInvalidArgument, "unknown lint rule", fmt.Errorf("%q", id), "Run 'a2acli card lint --list-rules' for the rule IDs")
+		}
+	}
+
+	src := serviceURL
+	if len(args) > 0 {
+		src = args[0]
+	}
+	// Lint the card as served now.
+	noCache = true
+	doc, err := loadCardSource(cmd.Context(), src)
+	if err != nil {
+		fatalf("failed to load card", err, "")
+	}
+	if doc.Raw == nil {
+		fatalf("failed to load card", fmt.Errorf("the document of %s is not available", doc.Label), "Run with --no-cache to fetch it again")
+	}
+
+	findings := cardlint.Lint(cmd.Context(), doc.Raw, doc.Card, cardlint.Options{
+		Client:   newHTTPClient(0),
+		Offline:  lintOffline,
+		Protocol: protocol,
+		MaxSize:  lintMaxSize << 10,
+		Suppress: suppress,
+	})
+	failed := cardlint.Failed(findings, strictMode)
+
+	switch format {
+	case "sarif":
+		v, _, _ := getVersionInfo()
+		b, _ := json.MarshalIndent(cardlint.SARIF(findings, doc.Location, "a2acli", v), "", "  ")
+		fmt.Println(string(b))
+	case "json":
+		if findings == nil {
+			findings = []cardlint.Finding{}
+		}
+		b, _ := json.MarshalIndent(map[string]any{
+			"source":   doc.Label,
+			"agent":    doc.Card.Name,
+			"passed":   !failed,
+			"findings": findings,
+		}, "", "  ")
+		fmt.Println(string(b))
+	default:
+		printLintFindings(doc, findings)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printLintFindings(doc *cardDocument, findings []cardlint.Finding) {
+	name := doc.Label
+	if doc.Card.Name != "" {
+		name += " " + StyleMuted.Render("("+doc.Card.Name+")")
+	}
+	counts := map[cardlint.Severity]int{}
+	suppressed := 0
+	for _, f := range findings {
+		if f.Suppressed {
+			suppressed++
+		} else {
+			counts[f.Severity]++
+		}
+	}
+	if len(findings) == suppressed {
+		fmt.Printf("%s %s passes every rule", StylePass.Render("✓"), name)
+		if suppressed > 0 {
+			fmt.Printf(" (%d suppressed)", suppressed)
+		}
+		fmt.Println()
+		return
+	}
+
+	fmt.Println(name)
+	for _, f := range findings {
+		if f.Suppressed {
+			continue
+		}
+		var label string
+		switch f.Severity {
+		case cardlint.Error:
+			label = StyleFail.Render("error  ")
+		case cardlint.Warning:
+			label = StyleWarn.Render("warning")
+		default:
+			label = StyleMuted.Render("note   ")
+		}
+		loc := "        "
+		if f.Line > 0 {
+			loc = fmt.Sprintf("line %-3d", f.Line)
+		}
+		fmt.Printf("  %s %s %s %s\n", label, StyleMuted.Render(loc), f.Message, StyleMuted.Render("["+f.Rule+"]"))
+		if r, ok := cardlint.Lookup(f.Rule); ok {
+			fmt.Printf("  %s %s\n", strings.Repeat(" ", 16), StyleMuted.Render(r.Help))
+		}
+	}
+	fmt.Printf("%d error(s), %d warning(s), %d note(s)", counts[cardlint.Error], counts[cardlint.Warning], counts[cardlint.Note])
+	if suppressed > 0 {
+		fmt.Printf(", %d suppressed", suppressed)
+	}
+	fmt.Println()
+}
+
+func printLintRules(format string) {
+	rules := cardlint.Rules()
+	if format != "text" {
+		b, _ := json.MarshalIndent(rules, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	for _, r := range rules {
+		network := ""
+		if r.Network {
+			network = StyleMuted.Render(" (network)")
+		}
+		fmt.Printf("%s %-8s %s%s\n", StyleCommand.Render(fmt.Sprintf("%-27s", r.ID)), r.Severity, r.Summary, network)
+	}
+}
@@ -0,0 +1,477 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/ghchinoy/a2acli/internal/jose"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Card signature statuses.
+const (
+	// cardVerified: a signature verifies with a trusted key.
+	cardVerified = "verified"
+	// cardInvalid: a signature is malformed, or fails to verify with a
+	// trusted key that matches its kid and algorithm.
+	cardInvalid = "invalid"
+	// cardUntrusted: the card is signed, but by no key a2acli trusts.
+	cardUntrusted = "untrusted"
+	// cardUnsigned: the card carries no signatures.
+	cardUnsigned = "unsigned"
+)
+
+var (
+	// requireSignedCard refuses agents whose card does not verify
+	// (--require-signed-card or envs.<name>.card_signature.required).
+	requireSignedCard bool
+	// cardJWKSURL and cardKeyFile are the active environment's card signing
+	// keys (--card-jwks-url, --card-key or envs.<name>.card_signature).
+	cardJWKSURL string
+	cardKeyFile string
+)
+
+// cardKeyConfig is an entry of trusted_card_keys: a JWKS URL, or a local
+// PEM public key or JWK/JWKS file. Kid names a PEM key, which has none.
+type cardKeyConfig struct {
+	JWKSURL string `mapstructure:"jwks_url"`
+	Key     string `mapstructure:"key"`
+	Kid     string `mapstructure:"kid"`
+}
+
+// cardTrustSource is a set of keys trusted to sign AgentCards and where it
+// came from.
+type cardTrustSource struct {
+	Name string
+	Keys *jose.JWKS
+}
+
+// cardVerification is the outcome of checking a card's signatures.
+type cardVerification struct {
+	Status     string `json:"status"`
+	Signatures int    `json:"signatures"`
+	// KeyID, Alg and KeySource describe the signature that verified, or
+	// the first one that did not.
+	KeyID     string `json:"kid,omitempty"`
+	Alg       string `json:"alg,omitempty"`
+	KeySource string `json:"key_source,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// String describes v for the discover and card verify output.
+func (v *cardVerification) String() string {
+	var details []string
+	if v.KeyID != "" {
+		details = append(details, fmt.Sprintf("kid %q", v.KeyID))
+	}
+	if v.Alg != "" {
+		details = append(details, v.Alg)
+	}
+	if v.KeySource != "" {
+		details = append(details, "key from "+v.KeySource)
+	}
+	s := v.Status
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+	if v.Error != "" {
+		s += ": " + v.Error
+	}
+	return s
+}
+
+// cardTrustConfigured reports whether any card signing key is trusted.
+func cardTrustConfigured() bool {
+	return cardJWKSURL != "" || cardKeyFile != "" || viper.IsSet("trusted_card_keys")
+}
+
+// loadCardTrust loads the trusted card signing keys: the active
+// environment's, then trusted_card_keys. Sources that fail to load are
+// skipped and reported in the returned error.
+func loadCardTrust(ctx context.Context) ([]cardTrustSource, error) {
+	keys := []cardKeyConfig{{JWKSURL: cardJWKSURL}, {Key: cardKeyFile}}
+	var trusted []cardKeyConfig
+	if err := viper.UnmarshalKey("trusted_card_keys", &trusted); err != nil {
+		return nil, fmt.Errorf("trusted_card_keys: %w", err)
+	}
+	keys = append(keys, trusted...)
+
+	var sources []cardTrustSource
+	var errs []error
+	for _, k := range keys {
+		switch {
+		case k.JWKSURL != "":
+			set, err := fetchCardJWKS(ctx, k.JWKSURL)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			sources = append(sources, cardTrustSource{Name: k.JWKSURL, Keys: set})
+		case k.Key != "":
+			set, err := loadCardKey(k.Key, k.Kid)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", k.Key, err))
+				continue
+			}
+			sources = append(sources, cardTrustSource{Name: k.Key, Keys: set})
+		}
+	}
+	return sources, errors.Join(errs...)
+}
+
+// loadCardKey reads a JWK or JWK Set file, or a PEM key. A private key is
+// accepted for convenience; only its public half is used.
+func loadCardKey(path, kid string) (*jose.JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return jose.ParseJWKS(data)
+	}
+	pub, _, err := jose.ParseKey(data)
+	if err != nil {
+		return nil, err
+	}
+	// No alg, so the key verifies any algorithm of its type.
+	jwk, err := jose.NewJWK(pub, kid, "")
+	if err != nil {
+		return nil, err
+	}
+	return &jose.JWKS{Keys: []jose.JWK{jwk}}, nil
+}
+
+// cardSigningPayload returns what an AgentCard signature covers: the RFC 8785
+// canonical form of the card document without its signatures. It also
+// returns the signatures.
+func cardSigningPayload(raw []byte) ([]byte, []a2a.AgentCardSignature, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, fmt.Errorf("card is not a JSON object: %w", err)
+	}
+	var sigs []a2a.AgentCardSignature
+	if s, ok := doc["signatures"]; ok {
+		if err := json.Unmarshal(s, &sigs); err != nil {
+			return nil, nil, fmt.Errorf("malformed signatures: %w", err)
+		}
+		delete(doc, "signatures")
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := jose.Canonicalize(b)
+	return payload, sigs, err
+}
+
+// verifyCardSignatures checks the signatures of the card document raw, as
+// served, against the trusted keys. The card is verified when any signature
+// verifies.
+func verifyCardSignatures(raw []byte, trust []cardTrustSource) *cardVerification {
+	payload, sigs, err := cardSigningPayload(raw)
+	if err != nil {
+		return &cardVerification{Status: cardInvalid, Error: err.Error()}
+	}
+	v := &cardVerification{Status: cardUnsigned, Signatures: len(sigs)}
+	if len(sigs) == 0 {
+		return v
+	}
+
+	var invalid, untrusted *cardVerification
+	for _, sig := range sigs {
+		j, err := jose.ParseDetached(sig.Protected, payload, sig.Signature)
+		if err != nil {
+			if invalid == nil {
+				invalid = &cardVerification{Error: err.Error()}
+			}
+			continue
+		}
+		// The kid may also be given in the unprotected header.
+		if kid, ok := sig.Header["kid"].(string); ok && j.Header.Kid == "" {
+			j.Header.Kid = kid
+		}
+		for _, src := range trust {
+			key, err := j.VerifyJWKS(src.Keys)
+			if err == nil {
+				v.Status, v.Alg, v.KeySource = cardVerified, j.Header.Alg, src.Name
+				if v.KeyID = key.Kid; v.KeyID == "" {
+					v.KeyID = j.Header.Kid
+				}
+				return v
+			}
+			if invalid == nil && len(src.Keys.Candidates(j.Header.Kid, j.Header.Alg)) > 0 {
+				invalid = &cardVerification{KeyID: j.Header.Kid, Alg: j.Header.Alg, KeySource: src.Name,
+					Error: "signature does not match the card"}
+			}
+		}
+		if untrusted == nil {
+			untrusted = &cardVerification{KeyID: j.Header.Kid, Alg: j.Header.Alg}
+		}
+	}
+
+	switch {
+	case invalid != nil:
+		v.Status, v.KeyID, v.Alg, v.KeySource, v.Error = cardInvalid, invalid.KeyID, invalid.Alg, invalid.KeySource, invalid.Error
+	default:
+		v.Status, v.KeyID, v.Alg = cardUntrusted, untrusted.KeyID, untrusted.Alg
+		v.Error = "no trusted key matches the signature"
+		if len(trust) == 0 {
+			v.Error = "no trusted card keys are configured"
+		}
+	}
+	return v
+}
+
+// checkCardSignature verifies a fetched card document. Keys are only loaded
+// when some are configured or a signature is required. With
+// --require-signed-card anything but a verified card is an error; otherwise
+// an invalid signature is a warning.
+func checkCardSignature(ctx context.Context, targetURL string, raw []byte) (*cardVerification, error) {
+	var trust []cardTrustSource
+	if requireSignedCard || cardTrustConfigured() {
+		var err error
+		if trust, err = loadCardTrust(ctx); err != nil {
+			if requireSignedCard && len(trust) == 0 {
+				return nil, fmt.Errorf("load trusted card keys: %w", err)
+			}
+			verboseLog("trusted card keys: %v", err)
+		}
+	}
+	v := verifyCardSignatures(raw, trust)
+	verboseLog("AgentCard signature for %s: %s", targetURL, v)
+
+	switch {
+	case v.Status == cardVerified:
+	case requireSignedCard:
+		return v, fmt.Errorf("AgentCard of %s is not verified (--require-signed-card): %s", targetURL, v)
+	case v.Status == cardInvalid:
+		fmt.Fprintf(os.Stderr, "%s AgentCard signature of %s is invalid: %s\n", StyleWarn.Render("Warning:"), targetURL, v.Error)
+	}
+	return v, nil
+}
+
+// printCardSignature prints the signature line of discover.
+func printCardSignature(v *cardVerification) {
+	style := StyleMuted
+	switch v.Status {
+	case cardVerified:
+		style = StylePass
+	case cardInvalid:
+		style = StyleFail
+	case cardUntrusted:
+		style = StyleWarn
+	}
+	fmt.Printf("Signature: %s\n", style.Render(v.String()))
+}
+
+var (
+	signKeyFile  string
+	signKeyID    string
+	signAlg      string
+	signJKU      string
+	signReplace  bool
+	signOutFile  string
+	signJWKSFile string
+)
+
+// setupCardSignCmds builds `card sign` and `card verify`.
+func setupCardSignCmds() []*cobra.Command {
+	signCmd := &cobra.Command{
+		Use:   "sign <card.json|->",
+		Short: "Sign an AgentCard with a private key",
+		Long: `Add a JWS signature to an AgentCard, as the A2A specification defines: a
+detached signature over the RFC 8785 canonical form of the card without its
+signatures field. Existing signatures are kept unless --replace is given, so a
+card can be signed by several keys during a key rotation.
+
+The key is a PEM private key (RSA, ECDSA P-256/384/521 or Ed25519). The
+algorithm defaults to RS256, ES256/ES384/ES512 by curve, or EdDSA.
+--write-jwks writes the matching public key as a JWK Set, to publish at the
+URL given with --jku and configure as a trusted key for clients.`,
+		Example: `  a2acli card sign agent-card.json --key card-key.pem --kid 2026-10 -f signed-card.json
+  a2acli card sign agent-card.json --key card-key.pem --kid 2026-10 \
+      --jku https://agent.example.com/.well-known/jwks.json --write-jwks jwks.json`,
+		Args: cobra.ExactArgs(1),
+		Run:  runCardSign,
+	}
+	signCmd.Flags().StringVar(&signKeyFile, "key", "", "PEM private key to sign with (required)")
+	signCmd.Flags().StringVar(&signKeyID, "kid", "", "Key ID to put in the signature header")
+	signCmd.Flags().StringVar(&signAlg, "alg", "", "JWS algorithm (default: inferred from the key)")
+	signCmd.Flags().StringVar(&signJKU, "jku", "", "URL of the JWK Set that publishes the public key")
+	signCmd.Flags().BoolVar(&signReplace, "replace", false, "Replace existing signatures instead of adding one")
+	signCmd.Flags().StringVarP(&signOutFile, "file", "f", "", "Write the signed card to this file instead of stdout")
+	signCmd.Flags().StringVar(&signJWKSFile, "write-jwks", "", "Also write the public key as a JWK Set to this file")
+	_ = signCmd.MarkFlagRequired("key")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify [source]",
+		Short: "Verify the signatures of an AgentCard",
+		Long: `Check an AgentCard's JWS signatures against the trusted card keys: those of
+--card-jwks-url and --card-key, the environment's card_signature settings, and
+the top-level trusted_card_keys. The source is any card source (see 'a2acli
+card'); it defaults to the service URL.
+
+The status is verified, invalid (a signature is malformed or does not match
+the card), untrusted (signed by no trusted key) or unsigned. The command exits
+1 unless the card is verified.`,
+		Example: `  a2acli card verify https://agent.example.com --card-jwks-url https://agent.example.com/.well-known/jwks.json
+  a2acli card verify signed-card.json --card-key card-key.pub.pem
+  a2acli card verify env:prod -o json`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runCardVerify,
+	}
+
+	return []*cobra.Command{signCmd, verifyCmd}
+}
+
+func runCardSign(_ *cobra.Command, args []string) {
+	var raw []byte
+	var err error
+	if args[0] == "-" {
+		raw, err = io.ReadAll(io.LimitReader(os.Stdin, maxCardSize))
+	} else {
+		raw, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		fatalf("failed to read card", err, "")
+	}
+	if _, err := parseAgentCard(raw); err != nil {
+		fatalCode(ErrCodeInvalidArgument, "not an AgentCard", err, "")
+	}
+
+	material, err := os.ReadFile(signKeyFile)
+	if err != nil {
+		fatalf("failed to read signing key", err, "")
+	}
+	pub, signer, err := jose.ParseKey(material)
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid signing key", err, "Use a PEM private key (PKCS#8, PKCS#1 or SEC 1)")
+	}
+	if signer == nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid signing key", errors.New(signKeyFile+" is a public key"), "Sign with the private key")
+	}
+	alg := signAlg
+	if alg == "" {
+		if alg, err = jose.DefaultAlg(pub); err != nil {
+			fatalCode(ErrCodeInvalidArgument, "invalid signing key", err, "")
+		}
+	}
+
+	signed, err := signCard(raw, jose.Header{Alg: alg, Kid: signKeyID, Typ: "JOSE", Jku: signJKU}, signer, signReplace)
+	if err != nil {
+		fatalf("failed to sign card", err, "")
+	}
+	if signOutFile == "" {
+		_, _ = os.Stdout.Write(signed)
+	} else if err := os.WriteFile(signOutFile, signed, 0o644); err != nil {
+		fatalf("failed to write signed card", err, "")
+	}
+
+	if signJWKSFile != "" {
+		jwk, err := jose.NewJWK(pub, signKeyID, alg)
+		if err != nil {
+			fatalf("failed to export public key", err, "")
+		}
+		b, _ := json.MarshalIndent(jose.JWKS{Keys: []jose.JWK{jwk}}, "", "  ")
+		if err := os.WriteFile(signJWKSFile, append(b, '\n'), 0o644); err != nil {
+			fatalf("failed to write JWK Set", err, "")
+		}
+		fmt.Fprintf(os.Stderr, "Public key written to %s\n", signJWKSFile)
+	}
+	if signOutFile != "" {
+		fmt.Fprintf(os.Stderr, "Signed card written to %s (%s)\n", signOutFile, alg)
+	}
+}
+
+// signCard signs the card document raw under the protected header h and
+// returns it, indented, with the signature added to its signatures or, with
+// replace, as its only signature.
+func signCard(raw []byte, h jose.Header, key crypto.Signer, replace bool) ([]byte, error) {
+	payload, sigs, err := cardSigningPayload(raw)
+	if err != nil {
+		return nil, err
+	}
+	protected, signature, err := jose.SignDetached(h, payload, key)
+	if err != nil {
+		return nil, err
+	}
+	if replace {
+		sigs = nil
+	}
+	sigs = append(sigs, a2a.AgentCardSignature{Protected: protected, Signature: signature})
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if doc["signatures"], err = json.Marshal(sigs); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func runCardVerify(cmd *cobra.Command, args []string) {
+	src := serviceURL
+	if len(args) > 0 {
+		src = args[0]
+	}
+	doc, err := loadCardSource(cmd.Context(), src)
+	if err != nil {
+		fatalf("failed to load card", err, "")
+	}
+	trust, err := loadCardTrust(cmd.Context())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", StyleWarn.Render("Warning:"), err)
+	}
+	v := verifyCardSignatures(doc.Raw, trust)
+
+	if disableTUI {
+		b, _ := json.MarshalIndent(struct {
+			Source string `json:"source"`
+			Agent  string `json:"agent"`
+			*cardVerification
+		}{doc.Label, doc.Card.Name, v}, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		fmt.Printf("Card: %s (%s)\n", doc.Card.Name, doc.Label)
+		fmt.Printf("Signatures: %d\n", v.Signatures)
+		style := StyleWarn
+		if v.Status == cardVerified {
+			style = StylePass
+		}
+		fmt.Printf("Status: %s\n", style.Render(v.String()))
+	}
+	if v.Status != cardVerified {
+		os.Exit(1)
+	}
+}
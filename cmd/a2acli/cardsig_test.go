@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghchinoy/a2acli/internal/jose"
+)
+
+const testCard = `{
+  "name": "Signed Agent",
+  "description": "Numbers like 1.0 and 1e2 and <html> must survive",
+  "version": "1.0.0",
+  "supportedInterfaces": [{"url": "http://127.0.0.1:9001", "protocolBinding": "JSONRPC", "protocolVersion": "1.0"}],
+  "capabilities": {"streaming": true},
+  "defaultInputModes": ["text/plain"],
+  "defaultOutputModes": ["text/plain"],
+  "skills": [{"id": "echo", "name": "Echo", "description": "Echoes", "tags": ["test"]}],
+  "extra": {"price": 1.50}
+}`
+
+func testSigningKey(t *testing.T, kid string) (*ecdsa.PrivateKey, *jose.JWKS) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := jose.NewJWK(key.Public(), kid, "ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, &jose.JWKS{Keys: []jose.JWK{jwk}}
+}
+
+func TestCardSignatures(t *testing.T) {
+	key, set := testSigningKey(t, "k1")
+	other, otherSet := testSigningKey(t, "k2")
+	trust := []cardTrustSource{{Name: "test", Keys: set}}
+
+	signed, err := signCard([]byte(testCard), jose.Header{Alg: "ES256", Kid: "k1"}, key, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseAgentCard(signed); err != nil {
+		t.Fatalf("signed card does not parse: %v", err)
+	}
+
+	// Whitespace and member order are not covered by the signature.
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(signed))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	compact, _ := json.Marshal(doc)
+
+	tampered := strings.Replace(string(signed), "Signed Agent", "Evil Agent", 1)
+	cosigned, err := signCard(signed, jose.Header{Alg: "ES256", Kid: "k2"}, other, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		raw   []byte
+		trust []cardTrustSource
+		want  string
+	}{
+		{"verified", signed, trust, cardVerified},
+		{"reformatted", compact, trust, cardVerified},
+		{"tampered", []byte(tampered), trust, cardInvalid},
+		{"unknown key", signed, []cardTrustSource{{Name: "other", Keys: otherSet}}, cardUntrusted},
+		{"no keys", signed, nil, cardUntrusted},
+		{"unsigned", []byte(testCard), trust, cardUnsigned},
+		{"either signer", cosigned, []cardTrustSource{{Name: "other", Keys: otherSet}}, cardVerified},
+		{"not an object", []byte(`[]`), trust, cardInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := verifyCardSignatures(tt.raw, tt.trust)
+			if v.Status != tt.want {
+				t.Errorf("status = %s, want %s", v, tt.want)
+			}
+		})
+	}
+
+	if v := verifyCardSignatures(cosigned, trust); v.Signatures != 2 || v.KeyID != "k1" || v.KeySource != "test" {
+		t.Errorf("cosigned = %+v", v)
+	}
+	replaced, _ := signCard(cosigned, jose.Header{Alg: "ES256", Kid: "k2"}, other, true)
+	if v := verifyCardSignatures(replaced, trust); v.Signatures != 1 || v.Status != cardUntrusted {
+		t.Errorf("--replace kept the old signature: %+v", v)
+	}
+}
+
+func TestLoadCardKey(t *testing.T) {
+	key, _ := testSigningKey(t, "")
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "card.pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	set, err := loadCardKey(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A PEM key has no kid, so it verifies signatures under any kid.
+	signed, _ := signCard([]byte(testCard), jose.Header{Alg: "ES256", Kid: "rotated"}, key, false)
+	if v := verifyCardSignatures(signed, []cardTrustSource{{Name: path, Keys: set}}); v.Status != cardVerified || v.KeyID != "rotated" {
+		t.Errorf("pinned PEM key: %+v", v)
+	}
+}
+
+func TestRequireSignedCard(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	key, set := testSigningKey(t, "k1")
+	signed, err := signCard([]byte(testCard), jose.Header{Alg: "ES256", Kid: "k1"}, key, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(set)
+
+	card := signed
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks.json" {
+			_, _ = w.Write(jwks)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(card)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	requireSignedCard, noCache = true, true
+	t.Cleanup(func() { requireSignedCard, noCache, cardJWKSURL = false, false, "" })
+
+	if _, err := resolveAgentCard(ctx, srv.URL); err == nil {
+		t.Error("a card was accepted with no trusted keys")
+	}
+	cardJWKSURL = srv.URL + "/jwks.json"
+	if c, err := resolveAgentCard(ctx, srv.URL); err != nil || c.Name != "Signed Agent" {
+		t.Errorf("signed card: %v, %v", c, err)
+	}
+	card = []byte(testCard)
+	if _, err := resolveAgentCard(ctx, srv.URL); err == nil {
+		t.Error("an unsigned card was accepted")
+	}
+	requireSignedCard = false
+	if _, err := resolveAgentCard(ctx, srv.URL); err != nil {
+		t.Errorf("unsigned card without --require-signed-card: %v", err)
+	}
+}
+
+func TestCardJWKSCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	_, set := testSigningKey(t, "k1")
+	jwks, _ := json.Marshal(set)
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		_, _ = w.Write(jwks)
+	}))
+	ctx := context.Background()
+	jwksURL := srv.URL + "/jwks.json"
+	cardJWKSURL = jwksURL
+	t.Cleanup(func() { noCache, cardJWKSURL = false, "" })
+
+	for range 2 {
+		trust, err := loadCardTrust(ctx)
+		if err != nil || len(trust) != 1 || trust[0].Keys.Keys[0].Kid != "k1" {
+			t.Fatalf("loadCardTrust = %+v, %v", trust, err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("JWKS fetched %d times, want once", fetches)
+	}
+
+	// Past its TTL the cached copy is still used while the URL is down.
+	path, err := jwksCachePath(jwksURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * jwksCacheTTL)
+	cached := readCachedJWKS(path, jwksURL)
+	cached.FetchedAt = old
+	data, _ := json.Marshal(cached)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	if trust, err := loadCardTrust(ctx); err != nil || len(trust) != 1 {
+		t.Errorf("offline with a stale JWKS: %+v, %v", trust, err)
+	}
+
+	noCache = true
+	if _, err := loadCardTrust(ctx); err == nil {
+		t.Error("--no-cache used the cached JWKS")
+	}
+}
@@ -0,0 +1,741 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ghchinoy/a2acli/internal/cardlint"
+	"github.com/ghchinoy/a2acli/internal/configschema"
+)
+
+var (
+	configSetForce  bool
+	validateOffline bool
+)
+
+// reachTimeout bounds each service_url reachability probe in config validate.
+const reachTimeout = 5 * time.Second
+
+// addConfigKeyCommands adds get, set, unset, edit and validate to the config
+// command group.
+func addConfigKeyCommands(configCmd *cobra.Command) {
+	getCmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a configuration value",
+		Long: `Print the value of a dotted configuration key, as a2acli sees it (including
+A2ACLI_* environment variables). Maps and lists are printed as YAML, or as JSON
+with --output json.`,
+		Example: `  a2acli config get default_env
+  a2acli config get envs.prod.service_url
+  a2acli config get envs.prod -o json`,
+		Args: cobra.ExactArgs(1),
+		Run:  runConfigGet,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a configuration value",
+		Long: `Set a dotted configuration key in config.yaml. The value is parsed as YAML, so
+3 is a number, true a boolean and [a, b] a list; a value the schema expects to
+be a string is kept as one. The change is checked against the config schema
+and refused if it makes the edited key invalid, unless --force is given.`,
+		Example: `  a2acli config set default_env prod
+  a2acli config set envs.prod.transport jsonrpc
+  a2acli config set envs.prod.retries 3
+  a2acli config set envs.prod.svc_params '[tenant=acme]'`,
+		Args: cobra.ExactArgs(2),
+		Run:  runConfigSet,
+	}
+	setCmd.Flags().BoolVar(&configSetForce, "force", false, "Write the value even if it fails validation")
+
+	unsetCmd := &cobra.Command{
+		Use:     "unset <key>",
+		Short:   "Remove a configuration value",
+		Example: `  a2acli config unset envs.prod.transport`,
+		Args:    cobra.ExactArgs(1),
+		Run:     runConfigUnset,
+	}
+
+	editCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit config.yaml in $EDITOR and validate it",
+		Long: `Open a copy of config.yaml in $VISUAL or $EDITOR (default vi). When the
+editor exits, the copy is validated against the config schema. Valid changes
+replace config.yaml; otherwise the problems are listed and you can edit again
+or discard the changes.`,
+		Example: `  a2acli config edit
+  EDITOR="code --wait" a2acli config edit`,
+		Args: cobra.NoArgs,
+		Run:  runConfigEdit,
+	}
+
+	validateCmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check config.yaml against the config schema",
+		Long: `Check a config file against a2acli's published JSON Schema. Without a file,
+every layer of the active configuration is checked: the user config.yaml, and
+the project .a2acli.yaml with the files it includes.
+
+The checks cover unknown keys such as a misspelled servce_url, invalid
+transports, protocols, durations and token stores, a default_env that names
+no environment, and missing certificate or key files. Each service_url is also
+probed for reachability unless --offline is given; an unreachable agent is a
+warning.
+
+Exits non-zero when errors are found, or warnings too with --strict.
+
+The schema is published at:
+  ` + configschema.URL,
+		Example: `  a2acli config validate
+  a2acli config validate team-config.yaml --offline
+  a2acli config validate -o json`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runConfigValidate,
+	}
+	validateCmd.Flags().BoolVar(&validateOffline, "offline", false, "Skip the service_url reachability checks")
+
+	configCmd.AddCommand(getCmd, setCmd, unsetCmd, editCmd, validateCmd)
+}
+
+// configFilePath returns the config file a2acli reads and writes.
+func configFilePath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	return defaultConfigPath()
+}
+
+// splitKey splits a dotted key into its path segments.
+func splitKey(key string) ([]string, error) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("invalid key %q", key)
+		}
+	}
+	return parts, nil
+}
+
+// readConfigDoc reads the config file as a generic document. A missing file
+// is an empty document.
+func readConfigDoc(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	return doc, nil
+}
+
+// writeConfigFile atomically replaces the config file. It is written 0600
+// since it may hold tokens and client secrets.
+func writeConfigFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// setPath stores value at path in doc, creating intermediate maps.
+func setPath(doc map[string]any, path []string, value any) error {
+	m := doc
+	for i, seg := range path[:len(path)-1] {
+		next, ok := m[seg]
+		if !ok || next == nil {
+			child := map[string]any{}
+			m[seg] = child
+			m = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is a %T, not a map", strings.Join(path[:i+1], "."), next)
+		}
+		m = child
+	}
+	m[path[len(path)-1]] = value
+	return nil
+}
+
+// deletePath removes the key at path from doc, reporting whether it existed.
+func deletePath(doc map[string]any, path []string) bool {
+	m := doc
+	for _, seg := range path[:len(path)-1] {
+		child, ok := m[seg].(map[string]any)
+		if !ok {
+			return false
+		}
+		m = child
+	}
+	if _, ok := m[path[len(path)-1]]; !ok {
+		return false
+	}
+	delete(m, path[len(path)-1])
+	return true
+}
+
+// issuesAt returns the schema issues that concern path: the key itself, its
+// children, or an object it belongs to (e.g. a now-missing service_url).
+func issuesAt(issues []configschema.Issue, path []string) []configschema.Issue {
+	key := strings.Join(path, ".")
+	var out []configschema.Issue
+	for _, i := range issues {
+		if i.Path == key || strings.HasPrefix(i.Path, key+".") || (i.Path != "" && strings.HasPrefix(key, i.Path+".")) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func runConfigGet(_ *cobra.Command, args []string) {
+	if _, err := splitKey(args[0]); err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid key", err, "Use a dotted key such as envs.prod.service_url")
+	}
+	value := viper.Get(args[0])
+	if value == nil {
+		fatalCode(ErrCodeNotFound, "key not set", fmt.Errorf("%q", args[0]), "Run 'a2acli config validate' to check for misspelled keys")
+	}
+	switch v := value.(type) {
+	case map[string]any, []any:
+		if disableTUI {
+			b, _ := json.MarshalIndent(v, "", "  ")
+			fmt.Println(string(b))
+			return
+		}
+		b, _ := yaml.Marshal(v)
+		fmt.Print(string(b))
+	default:
+		if disableTUI {
+			b, _ := json.Marshal(v)
+			fmt.Println(string(b))
+			return
+		}
+		fmt.Println(v)
+	}
+}
+
+func runConfigSet(_ *cobra.Command, args []string) {
+	path, err := splitKey(args[0])
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid key", err, "Use a dotted key such as envs.prod.service_url")
+	}
+	file, err := configFilePath()
+	if err != nil {
+		fatalf("failed to locate config file", err, "")
+	}
+	doc, err := readConfigDoc(file)
+	if err != nil {
+		fatalf("failed to read config", err, "Fix the file with 'a2acli config edit'")
+	}
+
+	// Try the value as YAML first, then as a plain string, and keep the
+	// first one the schema accepts.
+	candidates := []any{args[1]}
+	var typed any
+	if err := yaml.Unmarshal([]byte(args[1]), &typed); err == nil && typed != nil && typed != args[1] {
+		candidates = []any{typed, args[1]}
+	}
+	var data []byte
+	var problems []configschema.Issue
+	for _, candidate := range candidates {
+		if err := setPath(doc, path, candidate); err != nil {
+			fatalCode(ErrCodeInvalidArgument, "cannot set "+args[0], err, "")
+		}
+		data, err = yaml.Marshal(doc)
+		if err != nil {
+			fatalf("failed to encode config", err, "")
+		}
+		issues, err := configschema.ValidateYAML(data)
+		if err != nil {
+			fatalf("failed to validate config", err, "")
+		}
+		if problems = issuesAt(issues, path); len(problems) == 0 {
+			break
+		}
+	}
+	if len(problems) > 0 && !configSetForce {
+		msgs := make([]string, len(problems))
+		for i, p := range problems {
+			msgs[i] = p.String()
+		}
+		fatalCode(ErrCodeInvalidArgument, "invalid value for "+args[0], errors.New(strings.Join(msgs, "; ")),
+			"Run 'a2acli config validate' for details, or pass --force to write it anyway")
+	}
+
+	if err := writeConfigFile(file, data); err != nil {
+		fatalf("failed to save config", err, "")
+	}
+	if disableTUI {
+		b, _ := json.Marshal(map[string]any{"key": args[0], "file": file})
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Printf("Set %s in %s\n", StyleAccent.Render(args[0]), file)
+	if l := configSource(args[0]); l != nil && l.Kind != layerUser {
+		fmt.Printf("%s %s is overridden here by %s config %s\n", StyleWarn.Render("Note:"), args[0], l.Kind, displayPath(l.File))
+	}
+}
+
+func runConfigUnset(_ *cobra.Command, args []string) {
+	path, err := splitKey(args[0])
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid key", err, "Use a dotted key such as envs.prod.transport")
+	}
+	file, err := configFilePath()
+	if err != nil {
+		fatalf("failed to locate config file", err, "")
+	}
+	doc, err := readConfigDoc(file)
+	if err != nil {
+		fatalf("failed to read config", err, "Fix the file with 'a2acli config edit'")
+	}
+	if !deletePath(doc, path) {
+		fatalCode(ErrCodeNotFound, "key not set in "+file, fmt.Errorf("%q", args[0]), "")
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		fatalf("failed to encode config", err, "")
+	}
+	if err := writeConfigFile(file, data); err != nil {
+		fatalf("failed to save config", err, "")
+	}
+	if disableTUI {
+		b, _ := json.Marshal(map[string]any{"key": args[0], "file": file})
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Printf("Removed %s from %s\n", StyleAccent.Render(args[0]), file)
+}
+
+// editorCommand returns the command that opens path in the user's editor.
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if runtime.GOOS == "windows" {
+		if editor == "" {
+			editor = "notepad"
+		}
+		return exec.Command("cmd", "/C", editor+` "`+path+`"`)
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Through the shell, so EDITOR may carry arguments ("code --wait").
+	return exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+}
+
+func runConfigEdit(_ *cobra.Command, _ []string) {
+	file, err := configFilePath()
+	if err != nil {
+		fatalf("failed to locate config file", err, "")
+	}
+	original, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fatalf("failed to read config", err, "")
+	}
+
+	tmp, err := os.CreateTemp("", "a2acli-config-*.yaml")
+	if err != nil {
+		fatalf("failed to create temporary file", err, "")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if err := tmp.Chmod(0o600); err == nil {
+		_, err = tmp.Write(original)
+	}
+	_ = tmp.Close()
+	if err != nil {
+		fatalf("failed to create temporary file", err, "")
+	}
+
+	interactive := isTTY() && !isStdinPiped()
+	for {
+		cmd := editorCommand(tmp.Name())
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fatalf("editor failed", err, "Set $EDITOR to your editor, e.g. EDITOR=nano")
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			fatalf("failed to read edited config", err, "")
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes.")
+			return
+		}
+
+		issues, err := validateConfigData(edited, "", true)
+		if !hasErrors(issues) && err == nil {
+			if err := writeConfigFile(file, edited); err != nil {
+				fatalf("failed to save config", err, "")
+			}
+			printValidation([]validatedFile{{File: file, Valid: !hasErrors(issues), Issues: issues}})
+			fmt.Printf("Saved %s\n", file)
+			return
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", StyleFail.Render("Invalid YAML:"), err)
+		} else {
+			printValidation([]validatedFile{{File: file, Valid: !hasErrors(issues), Issues: issues}})
+		}
+		if !interactive {
+			fatalCode(ErrCodeInvalidArgument, "edited config is invalid; changes discarded", nil, "")
+		}
+		fmt.Fprint(os.Stderr, "Edit again? [Y/n] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+			fmt.Fprintln(os.Stderr, "Changes discarded.")
+			os.Exit(1)
+		}
+	}
+}
+
+// configIssue is a validation finding with its severity.
+type configIssue struct {
+	configschema.Issue
+	// Severity is "error" or "warning".
+	Severity string `json:"severity"`
+}
+
+func hasErrors(issues []configIssue) bool {
+	for _, i := range issues {
+		if i.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConfigData runs the schema and consistency checks on a config
+// document, and probes each service_url unless offline is set. Relative file
+// paths are checked against dir, or the current directory when dir is empty.
+func validateConfigData(data []byte, dir string, offline bool) ([]configIssue, error) {
+	schemaIssues, err := configschema.ValidateYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	var issues []configIssue
+	for _, i := range schemaIssues {
+		issues = append(issues, configIssue{Issue: i, Severity: "error"})
+	}
+
+	var doc struct {
+		DefaultEnv string `yaml:"default_env"`
+		Envs       map[string]struct {
+			ServiceURL string `yaml:"service_url"`
+			TLS        struct {
+				Cert     string `yaml:"cert"`
+				Key      string `yaml:"key"`
+				CACert   string `yaml:"cacert"`
+				Insecure bool   `yaml:"insecure_skip_verify"`
+			} `yaml:"tls"`
+			Proxy struct {
+				URL     string   `yaml:"url"`
+				NoProxy []string `yaml:"no_proxy"`
+			} `yaml:"proxy"`
+			Signing struct {
+				Key string `yaml:"key"`
+			} `yaml:"signing"`
+			CardSignature struct {
+				Key string `yaml:"key"`
+			} `yaml:"card_signature"`
+		} `yaml:"envs"`
+		CardLint struct {
+			Suppress []string `yaml:"suppress"`
+		} `yaml:"card_lint"`
+	}
+	// Shape errors are already reported by the schema.
+	_ = yaml.Unmarshal(data, &doc)
+
+	if doc.DefaultEnv != "" && doc.DefaultEnv != "default" {
+		if _, ok := doc.Envs[doc.DefaultEnv]; !ok {
+			issues = append(issues, configIssue{Severity: "error", Issue: configschema.Issue{
+				Path: "default_env", Message: fmt.Sprintf("names environment %q, which is not defined under envs", doc.DefaultEnv)}})
+		}
+	}
+
+	for i, s := range doc.CardLint.Suppress {
+		id, _, _ := strings.Cut(s, ":")
+		if _, ok := cardlint.Lookup(id); !ok {
+			issues = append(issues, configIssue{Severity: "error", Issue: configschema.Issue{
+				Path: fmt.Sprintf("card_lint.suppress.%d", i), Message: fmt.Sprintf("unknown lint rule %q", id)}})
+		}
+	}
+
+	names := make([]string, 0, len(doc.Envs))
+	for name := range doc.Envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env := doc.Envs[name]
+		for key, file := range map[string]string{"tls.cert": env.TLS.Cert, "tls.key": env.TLS.Key, "tls.cacert": env.TLS.CACert, "signing.key": env.Signing.Key, "card_signature.key": env.CardSignature.Key} {
+			if file == "" || strings.HasPrefix(file, "helper:") {
+				continue
+			}
+			if dir != "" && !filepath.IsAbs(file) {
+				file = filepath.Join(dir, file)
+			}
+			if _, err := os.Stat(file); err != nil {
+				issues = append(issues, configIssue{Severity: "error", Issue: configschema.Issue{
+					Path: "envs." + name + "." + key, Message: fmt.Sprintf("file %s: %v", file, errors.Unwrap(err))}})
+			}
+		}
+	}
+
+	if !offline {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, name := range names {
+			env := doc.Envs[name]
+			u := env.ServiceURL
+			if !strings.Contains(u, "://") {
+				continue
+			}
+			// Probe through the environment's own CA, client certificate
+			// and proxy, as 'config env check' does.
+			resolve := func(file string) string {
+				if file != "" && dir != "" && !filepath.IsAbs(file) {
+					return filepath.Join(dir, file)
+				}
+				return file
+			}
+			s := envSettings{
+				Cert:     resolve(env.TLS.Cert),
+				Key:      resolve(env.TLS.Key),
+				CA:       resolve(env.TLS.CACert),
+				Insecure: env.TLS.Insecure,
+				Proxy:    env.Proxy.URL,
+				NoProxy:  env.Proxy.NoProxy,
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := probeReachable(u, s); err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					issues = append(issues, configIssue{Severity: "warning", Issue: configschema.Issue{
+						Path: "envs." + name + ".service_url", Message: "unreachable: " + err.Error()}})
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// Attach line numbers to the consistency findings.
+	for i := range issues {
+		if issues[i].Line == 0 {
+			issues[i].Line = configschema.Line(data, issues[i].Path)
+		}
+	}
+	sort.SliceStable(issues, func(a, b int) bool {
+		if issues[a].Line != issues[b].Line {
+			return issues[a].Line < issues[b].Line
+		}
+		return issues[a].Path < issues[b].Path
+	})
+	return issues, nil
+}
+
+// probeReachable reports whether the agent at serviceURL answers at all,
+// connecting with the TLS and proxy settings s: any HTTP response counts,
+// since the card may need credentials this check does not send.
+func probeReachable(serviceURL string, s envSettings) error {
+	t, target, err := envTransport(s, serviceURL)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), reachTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(target, "/")+"/.well-known/agent-card.json", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: t}).Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// validatedFile is the validation result for one config file.
+type validatedFile struct {
+	File   string        `json:"file"`
+	Layer  string        `json:"layer,omitempty"`
+	Valid  bool          `json:"valid"`
+	Issues []configIssue `json:"issues"`
+}
+
+func printValidation(results []validatedFile) {
+	if disableTUI {
+		valid := true
+		for _, r := range results {
+			valid = valid && r.Valid
+		}
+		b, _ := json.MarshalIndent(map[string]any{
+			"valid": valid,
+			"files": results,
+		}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	for _, r := range results {
+		name := r.File
+		if r.Layer != "" {
+			name = fmt.Sprintf("%s %s", name, StyleMuted.Render("("+r.Layer+")"))
+		}
+		if len(r.Issues) == 0 {
+			fmt.Printf("%s %s is valid\n", StylePass.Render("✓"), name)
+			continue
+		}
+		fmt.Println(name)
+		errs, warns := 0, 0
+		for _, i := range r.Issues {
+			label := StyleFail.Render("error  ")
+			if i.Severity == "warning" {
+				label = StyleWarn.Render("warning")
+				warns++
+			} else {
+				errs++
+			}
+			loc := "        "
+			if i.Line > 0 {
+				loc = fmt.Sprintf("line %-3d", i.Line)
+			}
+			fmt.Printf("  %s %s %s\n", label, StyleMuted.Render(loc), i.String())
+		}
+		fmt.Printf("%d error(s), %d warning(s)\n", errs, warns)
+	}
+}
+
+// validateLayer checks one file of the layered configuration. Environment
+// references in project and included files are expanded first. A layer may
+// rely on the others, so a default_env naming an environment defined
+// elsewhere, or an environment whose service_url comes from another layer,
+// is accepted.
+func validateLayer(l configLayer, offline bool) (validatedFile, error) {
+	data, err := os.ReadFile(l.File)
+	if err != nil {
+		return validatedFile{}, err
+	}
+	if l.Kind != layerUser {
+		data = []byte(expandEnvRefs(string(data), l.File))
+	}
+	issues, err := validateConfigData(data, filepath.Dir(l.File), offline)
+	if err != nil {
+		return validatedFile{}, err
+	}
+	kept := issues[:0]
+	for _, i := range issues {
+		if i.Path == "default_env" && i.Severity == "error" && viper.IsSet("envs."+viper.GetString("default_env")) {
+			continue
+		}
+		if strings.HasPrefix(i.Path, "envs.") && strings.Count(i.Path, ".") == 1 &&
+			strings.Contains(i.Message, "'service_url'") && viper.GetString(i.Path+".service_url") != "" {
+			continue
+		}
+		kept = append(kept, i)
+	}
+	return validatedFile{File: l.File, Layer: l.Kind, Valid: !hasErrors(kept), Issues: kept}, nil
+}
+
+func runConfigValidate(_ *cobra.Command, args []string) {
+	var results []validatedFile
+	switch {
+	case len(args) == 1:
+		file := args[0]
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fatalCode(ErrCodeNotFound, "failed to read config", err, "Pass the file to validate, or create one with 'a2acli config env add'")
+		}
+		issues, err := validateConfigData(data, "", validateOffline)
+		if err != nil {
+			fatalCode(ErrCodeInvalidArgument, "invalid YAML in "+file, err, "")
+		}
+		results = append(results, validatedFile{File: file, Valid: !hasErrors(issues), Issues: issues})
+	case len(configLayers) == 0:
+		file, err := configFilePath()
+		if err != nil {
+			fatalf("failed to locate config file", err, "")
+		}
+		fatalCode(ErrCodeNotFound, "failed to read config", fmt.Errorf("%s does not exist", file), "Pass the file to validate, or create one with 'a2acli config env add'")
+	default:
+		for _, l := range configLayers {
+			r, err := validateLayer(l, validateOffline)
+			if err != nil {
+				fatalCode(ErrCodeInvalidArgument, "invalid YAML in "+l.File, err, "")
+			}
+			results = append(results, r)
+		}
+	}
+	printValidation(results)
+	for _, r := range results {
+		if !r.Valid || (strictMode && len(r.Issues) > 0) {
+			os.Exit(1)
+		}
+	}
+}
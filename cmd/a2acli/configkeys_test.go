@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSetDeletePath(t *testing.T) {
+	doc := map[string]any{"default_env": "prod"}
+	if err := setPath(doc, []string{"envs", "prod", "retries"}, 3); err != nil {
+		t.Fatal(err)
+	}
+	env := doc["envs"].(map[string]any)["prod"].(map[string]any)
+	if env["retries"] != 3 {
+		t.Errorf("retries = %v", env["retries"])
+	}
+	if err := setPath(doc, []string{"default_env", "x"}, 1); err == nil {
+		t.Error("expected an error setting a key below a string")
+	}
+	if !deletePath(doc, []string{"envs", "prod", "retries"}) {
+		t.Error("deletePath did not find envs.prod.retries")
+	}
+	if deletePath(doc, []string{"envs", "prod", "retries"}) {
+		t.Error("deletePath removed a missing key")
+	}
+}
+
+func TestValidateConfigData(t *testing.T) {
+	up := httptest.NewServer(http.NotFoundHandler())
+	defer up.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := "http://" + l.Addr().String()
+	_ = l.Close()
+
+	cfg := fmt.Sprintf(`default_env: staging
+envs:
+  up:
+    service_url: %s
+  down:
+    service_url: %s
+    tls:
+      cacert: /nonexistent/ca.pem
+    transport: jsonrpc
+card_lint:
+  suppress:
+    - interface-https
+    - bogus:name
+`, up.URL, down)
+
+	issues, err := validateConfigData([]byte(cfg), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]configIssue{}
+	for _, i := range issues {
+		got[i.Path] = i
+	}
+	if i := got["default_env"]; i.Severity != "error" || !strings.Contains(i.Message, "staging") || i.Line != 1 {
+		t.Errorf("default_env issue = %+v", i)
+	}
+	if i := got["envs.down.tls.cacert"]; i.Severity != "error" || i.Line != 8 {
+		t.Errorf("cacert issue = %+v", i)
+	}
+	if i := got["card_lint.suppress.1"]; i.Severity != "error" || !strings.Contains(i.Message, "bogus") || i.Line != 13 {
+		t.Errorf("suppress issue = %+v", i)
+	}
+	if i, ok := got["card_lint.suppress.0"]; ok {
+		t.Errorf("known rule reported: %+v", i)
+	}
+	if i := got["envs.down.service_url"]; i.Severity != "warning" || !strings.Contains(i.Message, "unreachable") {
+		t.Errorf("unreachable issue = %+v", i)
+	}
+	// A 404 still proves the agent is reachable.
+	if i, ok := got["envs.up.service_url"]; ok {
+		t.Errorf("reachable agent reported: %+v", i)
+	}
+	if !hasErrors(issues) {
+		t.Error("hasErrors = false")
+	}
+
+	offline, err := validateConfigData([]byte(cfg), "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range offline {
+		if i.Severity == "warning" {
+			t.Errorf("offline validation probed the network: %+v", i)
+		}
+	}
+}
+
+func TestValidateConfigReachUsesEnvNetwork(t *testing.T) {
+	// An agent behind a private CA, trusted through the env's tls.cacert.
+	private := httptest.NewUnstartedServer(http.NotFoundHandler())
+	private.Config.ErrorLog = log.New(io.Discard, "", 0)
+	private.StartTLS()
+	defer private.Close()
+	dir := t.TempDir()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: private.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), ca, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An agent only reachable through the env's proxy.
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer proxy.Close()
+
+	cfg := fmt.Sprintf(`envs:
+  private:
+    service_url: %s
+    tls:
+      cacert: ca.pem
+  untrusted:
+    service_url: %s
+  corporate:
+    service_url: http://agent.invalid
+    proxy:
+      url: %s
+`, private.URL, private.URL, proxy.URL)
+
+	issues, err := validateConfigData([]byte(cfg), dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]configIssue{}
+	for _, i := range issues {
+		got[i.Path] = i
+	}
+	if i, ok := got["envs.private.service_url"]; ok {
+		t.Errorf("agent behind the env's CA reported: %+v", i)
+	}
+	if i, ok := got["envs.corporate.service_url"]; ok {
+		t.Errorf("agent behind the env's proxy reported: %+v", i)
+	}
+	if u, _ := proxied.Load().(string); u != "http://agent.invalid/.well-known/agent-card.json" {
+		t.Errorf("proxy saw %q", u)
+	}
+	// Without the CA the same agent is untrusted.
+	if i := got["envs.untrusted.service_url"]; i.Severity != "warning" || !strings.Contains(i.Message, "certificate") {
+		t.Errorf("untrusted issue = %+v", i)
+	}
+}
@@ -20,12 +20,15 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/a2aproject/a2a-go/v2/a2a"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ghchinoy/a2acli/internal/cardlint"
 	"github.com/ghchinoy/a2acli/internal/oauth"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // conformanceResult holds the outcome of a single conformance check.
@@ -44,7 +47,8 @@ func setupConformanceCmd() *cobra.Command {
 		Short:   "Run A2A conformance smoke checks against a live server",
 		Long: `Run a quick sequence of conformance checks against a live A2A server:
 
-  1. AgentCard — fetch and validate the card is well-formed
+  1. AgentCard — fetch the card and run the offline 'card lint' rules;
+     lint errors fail the check
   2. Auth gating — if the card declares security requirements, verify that
      a request without credentials is rejected (and with credentials accepted)
   3. Round-trip — send a test message and assert a valid response is received
@@ -80,30 +84,43 @@ func runConformance(cmd *cobra.Command, _ []string) {
 	ctx := context.Background()
 
 	// ── Check 1: AgentCard well-formed ──────────────────────────────────────
-	card, err := resolveAgentCard(ctx, serviceURL)
+	card, raw, err := loadAgentCard(ctx, serviceURL)
+	if err == nil {
+		_, err = checkCardSignature(ctx, serviceURL, raw)
+	}
 	if err != nil {
 		results = append(results, fail("AgentCard fetch", fmt.Sprintf("could not fetch card: %v", err)))
 		printConformanceResults(results, overallPass)
 		os.Exit(1)
 	}
 
-	cardIssues := []string{}
-	if card.Name == "" {
-		cardIssues = append(cardIssues, "missing name")
-	}
-	if len(card.SupportedInterfaces) == 0 {
-		cardIssues = append(cardIssues, "no supported interfaces")
-	}
-	if len(card.Skills) == 0 {
-		cardIssues = append(cardIssues, "no skills declared")
+	// The offline lint rules; 'a2acli card lint' also runs the network ones.
+	findings := cardlint.Lint(ctx, raw, card, cardlint.Options{
+		Offline:  true,
+		Protocol: protocol,
+		Suppress: viper.GetStringSlice("card_lint.suppress"),
+	})
+	var cardIssues []string
+	warnings := 0
+	for _, f := range findings {
+		switch {
+		case f.Suppressed:
+		case f.Severity == cardlint.Error:
+			cardIssues = append(cardIssues, fmt.Sprintf("%s [%s]", f.Message, f.Rule))
+		case f.Severity == cardlint.Warning:
+			warnings++
+		}
 	}
 
 	if len(cardIssues) > 0 {
 		results = append(results, fail("AgentCard well-formed",
-			fmt.Sprintf("card has issues: %v", cardIssues)))
+			fmt.Sprintf("card has issues: %s", strings.Join(cardIssues, "; "))))
 	} else {
-		results = append(results, pass("AgentCard well-formed",
-			fmt.Sprintf("name=%q skills=%d interfaces=%d", card.Name, len(card.Skills), len(card.SupportedInterfaces))))
+		msg := fmt.Sprintf("name=%q skills=%d interfaces=%d", card.Name, len(card.Skills), len(card.SupportedInterfaces))
+		if warnings > 0 {
+			msg += fmt.Sprintf(" (%d lint warning(s), see 'a2acli card lint')", warnings)
+		}
+		results = append(results, pass("AgentCard well-formed", msg))
 	}
 
 	// ── Check 2: Auth gating ────────────────────────────────────────────────
@@ -118,9 +135,16 @@ func runConformance(cmd *cobra.Command, _ []string) {
 		}
 	}
 
-	// Resolve effective token: explicit --token flag, then the token store.
+	// Resolve effective token: explicit --token flag, then token_command,
+	// then the token store.
 	effectiveToken := authToken
-	if effectiveToken == "" {
+	if effectiveToken == "" && commandTokens != nil {
+		if t, err := commandTokens.Token(cmd.Context()); err == nil {
+			effectiveToken = t
+		} else {
+			verboseLog("conformance: %v", err)
+		}
+	} else if effectiveToken == "" {
 		if stored, err := oauth.LoadValidToken(cmd.Context(), serviceURL); err == nil && stored != nil && !stored.IsExpired() {
 			effectiveToken = stored.AccessToken
 			verboseLog("conformance: using stored token for %s", serviceURL)
@@ -136,7 +160,7 @@ func runConformance(cmd *cobra.Command, _ []string) {
 		// Test that the well-known endpoint exists (card fetch already passed).
 		// Test that an unauthenticated direct HTTP request is rejected.
 		wellKnown := serviceURL + "/.well-known/agent-card.json"
-		httpClient := &http.Client{Timeout: 10 * time.Second}
+		httpClient := newHTTPClient(10 * time.Second)
 		resp, err := httpClient.Get(wellKnown)
 		if err != nil {
 			results = append(results, skip("Auth gating", fmt.Sprintf("HTTP probe failed: %v", err)))
@@ -0,0 +1,562 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/pflag"
+)
+
+// partialSuffix is appended to the target path while a download is in
+// flight. An interrupted download leaves the partial file behind so the next
+// run can resume it with an HTTP Range request.
+const partialSuffix = ".part"
+
+// validatorSuffix is appended to the partial file's path for the file holding
+// the ETag or Last-Modified of the response it came from. A resume sends it
+// as If-Range, so bytes of a changed or different file are never appended.
+const validatorSuffix = partialSuffix + ".validator"
+
+var (
+	// maxDownloadSize is the raw --max-download-size value, e.g. "500MB".
+	maxDownloadSize string
+	// maxDownloadBytes is maxDownloadSize parsed by validateDownloadFlags.
+	// Zero means no limit.
+	maxDownloadBytes int64
+	// parallelDownloads bounds how many artifacts are saved concurrently.
+	parallelDownloads = 4
+	// credentialHosts lists the hosts that may receive the configured bearer
+	// token when downloading URL artifacts (envs.<name>.credential_hosts).
+	// Entries are host names, host:port pairs, or "*.example.com" wildcards.
+	// When empty, only the host of --service-url receives credentials.
+	credentialHosts []string
+)
+
+// errDownloadTooLarge is returned when a download exceeds --max-download-size.
+var errDownloadTooLarge = errors.New("download exceeds --max-download-size")
+
+// addDownloadFlags registers the URL artifact download flags on a command
+// that saves artifacts.
+func addDownloadFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&maxDownloadSize, "max-download-size", "", "Maximum size of a downloaded URL artifact, e.g. 500MB, 2GiB (default: no limit)")
+	fs.IntVar(&parallelDownloads, "parallel-downloads", 4, "Number of artifacts to download concurrently")
+}
+
+// validateDownloadFlags parses --max-download-size and checks
+// --parallel-downloads.
+func validateDownloadFlags() error {
+	n, err := parseByteSize(maxDownloadSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-download-size: %w", err)
+	}
+	maxDownloadBytes = n
+	if parallelDownloads < 1 {
+		return fmt.Errorf("--parallel-downloads must be at least 1, got %d", parallelDownloads)
+	}
+	return nil
+}
+
+// parseByteSize parses sizes such as "1024", "10KB", "500MB" or "2GiB".
+// Decimal suffixes (KB, MB, GB) are powers of 1000 and binary suffixes
+// (KiB, MiB, GiB) are powers of 1024. An empty string or "0" means no limit.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+		{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+		{"k", 1e3}, {"m", 1e6}, {"g", 1e9}, {"t", 1e12},
+		{"b", 1},
+	}
+	lower := strings.ToLower(s)
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(lower, u.suffix) {
+			lower = strings.TrimSpace(strings.TrimSuffix(lower, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(lower, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%q is not a size (examples: 500MB, 2GiB)", s)
+	}
+	return int64(v * float64(mult)), nil
+}
+
+// formatByteSize renders n using decimal units, e.g. 1.5 MB.
+func formatByteSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
+
+// shouldSendCredentials reports whether the bearer token may be forwarded to
+// the host serving rawURL. Pre-signed object storage URLs reject (and third
+// parties should never see) an Authorization header, so credentials are only
+// sent to the agent's own host or to hosts on the env's credential_hosts
+// allow-list.
+func shouldSendCredentials(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	hostPort := strings.ToLower(u.Host)
+
+	allowed := credentialHosts
+	if len(allowed) == 0 {
+		if svc, err := url.Parse(serviceURL); err == nil && svc.Host != "" {
+			allowed = []string{svc.Host}
+		}
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "":
+			continue
+		case strings.HasPrefix(pattern, "*."):
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+		case strings.Contains(pattern, ":"):
+			if hostPort == pattern {
+				return true
+			}
+		case host == pattern:
+			return true
+		}
+	}
+	return false
+}
+
+// downloadResult describes a file written by downloadToFile.
+type downloadResult struct {
+	Size   int64
+	SHA256 string
+}
+
+// downloadToFile streams rawURL into dest. The body is written to
+// dest+".part" first; if a partial file already exists the download resumes
+// from its end with a Range request, conditional on the ETag or Last-Modified
+// recorded for it. A partial file without one is discarded. The partial file
+// is renamed to dest only after the transfer completes and its length
+// matches what the server announced.
+func downloadToFile(ctx context.Context, rawURL, dest string) (downloadResult, error) {
+	partial := dest + partialSuffix
+	validatorFile := dest + validatorSuffix
+	var offset int64
+	var validator string
+	if fi, err := os.Stat(partial); err == nil && fi.Mode().IsRegular() && fi.Size() > 0 {
+		if b, err := os.ReadFile(validatorFile); err == nil && strings.TrimSpace(string(b)) != "" {
+			offset, validator = fi.Size(), strings.TrimSpace(string(b))
+		} else {
+			verboseLog("download: discarding %s, which has no ETag or Last-Modified to resume against", partial)
+		}
+	}
+
+	resp, err := startDownload(ctx, rawURL, offset, validator)
+	if err != nil {
+		return downloadResult{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, _, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			return downloadResult{}, fmt.Errorf("server sent unexpected range %q for resume at byte %d", resp.Header.Get("Content-Range"), offset)
+		}
+		verboseLog("download: resuming %s at byte %d", rawURL, offset)
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		if offset > 0 {
+			verboseLog("download: %s changed or the server ignored the Range request, restarting", rawURL)
+		}
+		offset = 0
+		flags |= os.O_TRUNC
+	default:
+		return downloadResult{}, fmt.Errorf("download returned HTTP %d", resp.StatusCode)
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	if maxDownloadBytes > 0 && total > maxDownloadBytes {
+		return downloadResult{}, fmt.Errorf("%w: %s is %s (limit %s)", errDownloadTooLarge,
+			rawURL, formatByteSize(total), formatByteSize(maxDownloadBytes))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return downloadResult{}, err
+	}
+	// Record what the bytes about to be written belong to before writing
+	// them, so an interrupted transfer can be resumed safely.
+	if v := resumeValidator(resp.Header); v != "" {
+		if err := os.WriteFile(validatorFile, []byte(v+"\n"), 0644); err != nil {
+			return downloadResult{}, err
+		}
+	} else {
+		_ = os.Remove(validatorFile)
+	}
+	f, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return downloadResult{}, err
+	}
+
+	name := filepath.Base(dest)
+	activeProgress.update(name, offset, total)
+	defer activeProgress.finish(name)
+
+	var body io.Reader = resp.Body
+	if maxDownloadBytes > 0 {
+		// Read one byte past the limit so an oversized body without a
+		// Content-Length is detected rather than silently truncated.
+		body = io.LimitReader(resp.Body, maxDownloadBytes-offset+1)
+	}
+	written, err := io.Copy(f, &progressReader{r: body, name: name, done: offset, total: total})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	size := offset + written
+	if err != nil {
+		return downloadResult{}, fmt.Errorf("download interrupted after %s (rerun to resume): %w", formatByteSize(size), err)
+	}
+	if maxDownloadBytes > 0 && size > maxDownloadBytes {
+		_ = os.Remove(partial)
+		_ = os.Remove(validatorFile)
+		return downloadResult{}, fmt.Errorf("%w: %s (limit %s)", errDownloadTooLarge, rawURL, formatByteSize(maxDownloadBytes))
+	}
+	if total >= 0 && size != total {
+		return downloadResult{}, fmt.Errorf("download incomplete: got %d of %d bytes (rerun to resume)", size, total)
+	}
+
+	sum, err := fileSHA256(partial)
+	if err != nil {
+		return downloadResult{}, err
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		return downloadResult{}, err
+	}
+	_ = os.Remove(validatorFile)
+	return downloadResult{Size: size, SHA256: sum}, nil
+}
+
+// resumeValidator returns the value a resume of the response with header h
+// sends as If-Range: its strong ETag, or else its Last-Modified date. Weak
+// ETags cannot be used with If-Range.
+func resumeValidator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return h.Get("Last-Modified")
+}
+
+// startDownload issues the GET for rawURL, asking for the bytes after offset
+// when resuming, provided the file still matches validator (If-Range); the
+// server answers 200 with the whole file otherwise. A 416 response means the
+// partial file is unusable, so the request is retried from the beginning.
+func startDownload(ctx context.Context, rawURL string, offset int64, validator string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if shouldSendCredentials(rawURL) {
+		token, err := bearerToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		headers, err := envHeaders()
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+	} else if authToken != "" || commandTokens != nil || len(headersFromEnv) > 0 {
+		verboseLog("download: not forwarding credentials to %s (not in credential_hosts)", req.URL.Host)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+	resp, err := downloadClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 {
+		_ = resp.Body.Close()
+		return startDownload(ctx, rawURL, 0, "")
+	}
+	return resp, nil
+}
+
+// downloadClient has no overall timeout so large artifacts can stream for as
+// long as data keeps arriving; --timeout still bounds the request context.
+// It is built on first use so it picks up the TLS and proxy settings.
+var downloadClient = sync.OnceValue(func() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 httpProxy(mustProxyFunc()),
+			DialContext:           dialUnixAware(&net.Dialer{Timeout: 30 * time.Second}),
+			TLSClientConfig:       mustClientTLSConfig(),
+			TLSHandshakeTimeout:   30 * time.Second,
+			ResponseHeaderTimeout: 2 * time.Minute,
+		},
+	}
+})
+
+// parseContentRange parses a "bytes start-end/total" header. total is -1
+// when the server reports it as "*".
+func parseContentRange(h string) (start, total int64, err error) {
+	spec, ok := strings.CutPrefix(h, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported Content-Range %q", h)
+	}
+	rng, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed Content-Range %q", h)
+	}
+	first, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed Content-Range %q", h)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed Content-Range %q", h)
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("malformed Content-Range %q", h)
+		}
+	}
+	return start, total, nil
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// artifactSaveResult is the outcome of saving one artifact of a task.
+type artifactSaveResult struct {
+	Parts []savedPart
+	Err   error
+}
+
+// saveArtifacts saves every artifact, downloading up to --parallel-downloads
+// of them concurrently. Results are returned in artifact order.
+func saveArtifacts(outDir, outFile string, arts []*a2a.Artifact) []artifactSaveResult {
+	results := make([]artifactSaveResult, len(arts))
+	sem := make(chan struct{}, max(parallelDownloads, 1))
+	var wg sync.WaitGroup
+	for i, art := range arts {
+		if art == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			parts, err := saveArtifact(outDir, outFile, *art, i)
+			results[i] = artifactSaveResult{Parts: parts, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// hasURLParts reports whether any artifact contains a URL part, i.e. whether
+// saving it involves a network download.
+func hasURLParts(arts ...*a2a.Artifact) bool {
+	for _, art := range arts {
+		if art == nil {
+			continue
+		}
+		for _, p := range art.Parts {
+			if _, ok := p.Content.(a2a.URL); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// progressReader reports bytes read to the active progress tracker.
+type progressReader struct {
+	r     io.Reader
+	name  string
+	done  int64
+	total int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	activeProgress.update(p.name, p.done, p.total)
+	return n, err
+}
+
+// activeProgress aggregates in-flight downloads for display. It is nil (and
+// all of its methods are no-ops) unless the TUI is rendering progress.
+var activeProgress *progressTracker
+
+type progressItem struct {
+	done, total int64
+}
+
+// progressTracker records per-file download progress. It is safe for
+// concurrent use and its methods accept a nil receiver.
+type progressTracker struct {
+	mu    sync.Mutex
+	items map[string]progressItem
+	bar   progress.Model
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{
+		items: map[string]progressItem{},
+		bar:   progress.New(progress.WithDefaultGradient(), progress.WithWidth(30)),
+	}
+}
+
+func (t *progressTracker) update(name string, done, total int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.items[name] = progressItem{done: done, total: total}
+}
+
+func (t *progressTracker) finish(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.items, name)
+}
+
+// View renders a single progress line covering every in-flight download, or
+// "" when nothing is downloading.
+func (t *progressTracker) View() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.items) == 0 {
+		return ""
+	}
+	var done, total int64
+	var name string
+	known := true
+	for n, it := range t.items {
+		name = n
+		done += it.done
+		if it.total < 0 {
+			known = false
+		}
+		total += it.total
+	}
+	label := name
+	if len(t.items) > 1 {
+		label = fmt.Sprintf("%d files", len(t.items))
+	}
+	if !known || total == 0 {
+		return fmt.Sprintf("%s %s %s", StyleMuted.Render("Downloading"), label, formatByteSize(done))
+	}
+	return fmt.Sprintf("%s %s %s %s / %s", StyleMuted.Render("Downloading"), label,
+		t.bar.ViewAs(float64(done)/float64(total)), formatByteSize(done), formatByteSize(total))
+}
+
+// withDownloadProgress runs fn, drawing a progress line on stderr while it
+// downloads URL artifacts. Progress is only shown in TUI output mode when
+// stderr is a terminal, so redirected output and JSON mode stay clean.
+func withDownloadProgress(fn func()) {
+	if outputMode != "tui" || !isatty.IsTerminal(os.Stderr.Fd()) {
+		fn()
+		return
+	}
+	activeProgress = newProgressTracker()
+	stop := make(chan struct{})
+	drawn := make(chan struct{})
+	go func() {
+		defer close(drawn)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+				if line := activeProgress.View(); line != "" {
+					fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+				}
+			}
+		}
+	}()
+	fn()
+	close(stop)
+	<-drawn
+	activeProgress = nil
+}
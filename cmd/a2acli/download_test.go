@@ -0,0 +1,260 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1024", 1024, false},
+		{"10KB", 10_000, false},
+		{"500MB", 500_000_000, false},
+		{"2GiB", 2 << 30, false},
+		{"1.5 mb", 1_500_000, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestShouldSendCredentials(t *testing.T) {
+	origSvc, origHosts := serviceURL, credentialHosts
+	defer func() { serviceURL, credentialHosts = origSvc, origHosts }()
+
+	serviceURL = "https://agent.example.com"
+	credentialHosts = nil
+	if !shouldSendCredentials("https://agent.example.com/files/a.bin") {
+		t.Error("expected credentials for the service host by default")
+	}
+	if shouldSendCredentials("https://storage.googleapis.com/bucket/a.bin?X-Goog-Signature=x") {
+		t.Error("credentials must not go to hosts outside the allow-list")
+	}
+
+	credentialHosts = []string{"*.files.example.com", "cdn.example.net:8443"}
+	tests := map[string]bool{
+		"https://eu.files.example.com/a":   true,
+		"https://files.example.com/a":      false,
+		"https://cdn.example.net:8443/a":   true,
+		"https://cdn.example.net/a":        false,
+		"https://agent.example.com/a":      false,
+		"https://evilfiles.example.com/a":  false,
+		"https://x.eu.files.example.com/a": true,
+	}
+	for u, want := range tests {
+		if got := shouldSendCredentials(u); got != want {
+			t.Errorf("shouldSendCredentials(%q) = %v, want %v", u, got, want)
+		}
+	}
+}
+
+func TestDownloadToFileResumes(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var gotRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "blob.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "blob.bin")
+	if err := os.WriteFile(dest+partialSuffix, content[:4000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest+validatorSuffix, []byte(`"v1"`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := downloadToFile(context.Background(), srv.URL, dest)
+	if err != nil {
+		t.Fatalf("downloadToFile failed: %v", err)
+	}
+	if gotRange != "bytes=4000-" {
+		t.Errorf("expected resume Range header, got %q", gotRange)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, content) || res.Size != int64(len(content)) || len(res.SHA256) != 64 {
+		t.Errorf("resumed file mismatch: %d bytes, result %+v", len(got), res)
+	}
+	for _, f := range []string{dest + partialSuffix, dest + validatorSuffix} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s should be gone on completion", filepath.Base(f))
+		}
+	}
+}
+
+func TestDownloadToFileRestartsChangedFile(t *testing.T) {
+	v1 := bytes.Repeat([]byte("a"), 10000)
+	v2 := bytes.Repeat([]byte("b"), 12000)
+	var (
+		mu      sync.Mutex
+		content = v1
+		etag    = `"v1"`
+		ranges  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body, tag := content, etag
+		ranges = append(ranges, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		mu.Unlock()
+		if tag == `"v1"` {
+			// Announce the whole file but drop the connection part way.
+			w.Header().Set("ETag", tag)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			_, _ = w.Write(body[:4000])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set("ETag", tag)
+		http.ServeContent(w, r, "blob.bin", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "blob.bin")
+	if _, err := downloadToFile(context.Background(), srv.URL, dest); err == nil {
+		t.Fatal("interrupted download succeeded")
+	}
+	if b, _ := os.ReadFile(dest + validatorSuffix); strings.TrimSpace(string(b)) != `"v1"` {
+		t.Fatalf("validator = %q, want the first response's ETag", b)
+	}
+
+	mu.Lock()
+	content, etag = v2, `"v2"`
+	mu.Unlock()
+	res, err := downloadToFile(context.Background(), srv.URL, dest)
+	if err != nil {
+		t.Fatalf("downloadToFile failed: %v", err)
+	}
+	if len(ranges) != 2 || ranges[1] != `bytes=4000- "v1"` {
+		t.Errorf("requests = %q, want a resume conditional on the first ETag", ranges)
+	}
+	got, _ := os.ReadFile(dest)
+	sum := sha256.Sum256(v2)
+	if !bytes.Equal(got, v2) || res.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("got %d bytes (%s), want the new file only", len(got), res.SHA256)
+	}
+}
+
+func TestDownloadToFileDiscardsUnvalidatedPartial(t *testing.T) {
+	content := bytes.Repeat([]byte("new"), 1000)
+	var gotRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		http.ServeContent(w, r, "blob.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	// A partial file left by another artifact or URL, with nothing to
+	// check it against, is not resumed.
+	dest := filepath.Join(t.TempDir(), "blob.bin")
+	if err := os.WriteFile(dest+partialSuffix, []byte("old bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadToFile(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("downloadToFile failed: %v", err)
+	}
+	if gotRange != "" {
+		t.Errorf("Range = %q, want a full download", gotRange)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, content) {
+		t.Errorf("got %q..., want the new file only", got[:12])
+	}
+}
+
+func TestDownloadToFileMaxSize(t *testing.T) {
+	origMax := maxDownloadBytes
+	defer func() { maxDownloadBytes = origMax }()
+	maxDownloadBytes = 100
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Flush before writing so no Content-Length is sent and the limit
+		// has to be enforced while streaming.
+		w.(http.Flusher).Flush()
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 500))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "big.bin")
+	_, err := downloadToFile(context.Background(), srv.URL, dest)
+	if !errors.Is(err, errDownloadTooLarge) {
+		t.Fatalf("expected errDownloadTooLarge, got %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("oversized download must not produce a file")
+	}
+}
+
+func TestSaveArtifactsParallelURLParts(t *testing.T) {
+	origSvc, origToken, origHosts := serviceURL, authToken, credentialHosts
+	defer func() { serviceURL, authToken, credentialHosts = origSvc, origToken, origHosts }()
+
+	var sawAuth bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			sawAuth = true
+		}
+		_, _ = w.Write([]byte("body of " + r.URL.Path))
+	}))
+	defer srv.Close()
+	serviceURL, authToken, credentialHosts = "https://agent.example.com", "secret", nil
+
+	dir := t.TempDir()
+	var arts []*a2a.Artifact
+	for _, name := range []string{"a", "b", "c"} {
+		p := a2a.NewFileURLPart(a2a.URL(srv.URL+"/"+name), "text/plain")
+		arts = append(arts, &a2a.Artifact{ID: a2a.ArtifactID("dl-" + name), Name: name, Parts: []*a2a.Part{p}})
+	}
+
+	results := saveArtifacts(dir, "", arts)
+	for i, r := range results {
+		if r.Err != nil || len(r.Parts) != 1 || r.Parts[0].Err != nil || r.Parts[0].IsURL {
+			t.Fatalf("artifact %d not downloaded: %+v", i, r)
+		}
+		b, _ := os.ReadFile(r.Parts[0].Path)
+		if !strings.HasSuffix(string(b), "/"+arts[i].Name) {
+			t.Errorf("artifact %d has wrong content %q", i, b)
+		}
+	}
+	if sawAuth {
+		t.Error("token was forwarded to a host outside the allow-list")
+	}
+}
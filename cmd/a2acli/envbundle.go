@@ -0,0 +1,513 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ghchinoy/a2acli/internal/oauth"
+)
+
+var (
+	exportWithSecrets bool
+	exportAll         bool
+	exportFile        string
+	importForce       bool
+	importCommands    bool
+)
+
+// maxBundleSize bounds an environment bundle fetched by config env import.
+const maxBundleSize = 1 << 20
+
+// addEnvBundleCommands adds export and import to the config env command
+// group.
+func addEnvBundleCommands(envCmd *cobra.Command) {
+	exportCmd := &cobra.Command{
+		Use:   "export [name...]",
+		Short: "Export environments as a shareable bundle",
+		Long: `Write one or more environments as a portable YAML bundle (JSON with
+--output json, or when --file ends in .json) that a teammate can load with
+'a2acli config env import'. The bundle has the same shape as config.yaml, so it
+can also be listed under include: in a project .a2acli.yaml.
+
+Secrets are left out unless --with-secrets is given:
+  - a static token becomes token_command: printenv A2A_TOKEN_<ENV>
+  - headers that look like credentials (Authorization, *-Key, *Token*, Cookie,
+    ...) move to headers_from_env, read from A2A_<ENV>_<HEADER>
+  - OAuth client secrets and proxy passwords are dropped
+  - certificate, key and signing-key paths, and token stores, which only make
+    sense on this machine, are dropped
+OAuth settings are kept, so the importer only needs 'a2acli auth login'.`,
+		Example: `  a2acli config env export prod
+  a2acli config env export staging prod --file team-envs.yaml
+  a2acli config env export --all -o json
+  a2acli config env export dev --with-secrets > dev-with-token.yaml`,
+		Run: runConfigEnvExport,
+	}
+	exportCmd.Flags().BoolVar(&exportWithSecrets, "with-secrets", false, "Include tokens, secret headers, client secrets and local file paths verbatim")
+	exportCmd.Flags().BoolVar(&exportAll, "all", false, "Export every environment")
+	exportCmd.Flags().StringVarP(&exportFile, "file", "f", "", "Write the bundle to this file instead of stdout")
+
+	importCmd := &cobra.Command{
+		Use:   "import <file|url|-> [name...]",
+		Short: "Import environments from a bundle",
+		Long: `Add the environments of a bundle written by 'a2acli config env export' (or any
+file with an envs: section) to config.yaml. The bundle may be a file, an
+http(s) URL, or - for stdin. Name environments after the source to import only
+those.
+
+The bundle is checked against the config schema first. An environment that
+already exists is only replaced with --force. Settings that run programs on
+this machine (token_command, helper: token stores and signing keys) are listed
+and left out unless --allow-commands is given, so review them first. The
+environment variables and logins the environments need are listed too.`,
+		Example: `  a2acli config env import team-envs.yaml
+  a2acli config env import https://wiki.example.com/a2a/envs.yaml staging
+  a2acli config env export prod | ssh build-host a2acli config env import -
+  a2acli config env import team-envs.yaml --force
+  a2acli config env import team-envs.yaml --allow-commands`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  runConfigEnvImport,
+	}
+	importCmd.Flags().BoolVar(&importForce, "force", false, "Replace environments that already exist")
+	importCmd.Flags().BoolVar(&importCommands, "allow-commands", false, "Import token_command and helper: settings, which run programs on this machine")
+
+	envCmd.AddCommand(exportCmd, importCmd)
+}
+
+// secretHeaderWords mark a header name as carrying a credential.
+var secretHeaderWords = []string{"authorization", "token", "key", "secret", "password", "cookie", "session", "signature", "credential"}
+
+func secretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, w := range secretHeaderWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// envVarName builds an environment variable name from parts, e.g.
+// A2A_PROD_X_API_KEY.
+func envVarName(parts ...string) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteByte('_')
+		}
+		for _, r := range strings.ToUpper(p) {
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+	}
+	return b.String()
+}
+
+// redactEnv removes the secrets and machine-specific settings from an
+// exported environment, replacing what it can with references to environment
+// variables. It returns one note per change.
+func redactEnv(name string, env map[string]any) []string {
+	var notes []string
+	if tok, _ := env["token"].(string); tok != "" {
+		delete(env, "token")
+		if _, ok := env["token_command"]; !ok {
+			v := envVarName("A2A_TOKEN", name)
+			env["token_command"] = "printenv " + v
+			notes = append(notes, fmt.Sprintf("token replaced by token_command reading $%s", v))
+		} else {
+			notes = append(notes, "static token removed")
+		}
+	}
+	if headers, ok := env["headers"].(map[string]any); ok {
+		fromEnv, _ := env["headers_from_env"].(map[string]any)
+		for h := range headers {
+			if !secretHeader(h) {
+				continue
+			}
+			if fromEnv == nil {
+				fromEnv = map[string]any{}
+				env["headers_from_env"] = fromEnv
+			}
+			v := envVarName("A2A", name, h)
+			fromEnv[h] = v
+			delete(headers, h)
+			notes = append(notes, fmt.Sprintf("header %s now read from $%s", h, v))
+		}
+		if len(headers) == 0 {
+			delete(env, "headers")
+		}
+	}
+	if o, ok := env["oauth"].(map[string]any); ok {
+		if s, _ := o["client_secret"].(string); s != "" {
+			delete(o, "client_secret")
+			notes = append(notes, "OAuth client secret removed")
+		}
+	}
+	if p, ok := env["proxy"].(map[string]any); ok {
+		if raw, _ := p["url"].(string); raw != "" {
+			if u, err := url.Parse(raw); err == nil && u.User != nil {
+				u.User = nil
+				p["url"] = u.String()
+				notes = append(notes, "proxy credentials removed")
+			}
+		}
+	}
+	if tls, ok := env["tls"].(map[string]any); ok {
+		for _, k := range []string{"cert", "key", "cacert"} {
+			if _, ok := tls[k]; ok {
+				delete(tls, k)
+				notes = append(notes, "tls."+k+" path removed")
+			}
+		}
+		if len(tls) == 0 {
+			delete(env, "tls")
+		}
+	}
+	if s, ok := env["signing"].(map[string]any); ok {
+		if _, ok := s["key"]; ok {
+			delete(s, "key")
+			notes = append(notes, "signing.key removed")
+		}
+	}
+	if _, ok := env["token_store"]; ok {
+		delete(env, "token_store")
+		notes = append(notes, "token_store removed")
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// exportEnv returns a copy of the named environment from the effective
+// configuration, with a static token from the token store filled in when
+// secrets are wanted.
+func exportEnv(name string) (map[string]any, error) {
+	raw, ok := viper.Get("envs." + name).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%q", name)
+	}
+	// Deep copy through YAML so redaction does not touch viper's maps.
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	env := map[string]any{}
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if exportWithSecrets && env["token"] == nil {
+		if err := useTokenStore(name); err == nil {
+			su, _ := env["service_url"].(string)
+			if tok, err := oauth.LoadToken(su); err == nil && tok != nil && tok.GrantType == oauth.GrantStatic {
+				env["token"] = tok.AccessToken
+			}
+		}
+	}
+	return env, nil
+}
+
+func runConfigEnvExport(_ *cobra.Command, args []string) {
+	names := args
+	if exportAll {
+		for name := range viper.GetStringMap("envs") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		fatalCode(ErrCodeInvalidArgument, "no environments to export", nil, "Name the environments to export, or pass --all")
+	}
+	sort.Strings(names)
+
+	envs := map[string]any{}
+	var header []string
+	for _, name := range names {
+		env, err := exportEnv(name)
+		if err != nil {
+			fatalCode(ErrCodeNotFound, "environment not found", err, "Run 'a2acli config env list' to see available environments")
+		}
+		if !exportWithSecrets {
+			for _, n := range redactEnv(name, env) {
+				header = append(header, name+": "+n)
+			}
+		}
+		envs[name] = env
+	}
+	bundle := map[string]any{"envs": envs}
+
+	asJSON := disableTUI || strings.HasSuffix(strings.ToLower(exportFile), ".json")
+	var data []byte
+	if asJSON {
+		b, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			fatalf("failed to encode bundle", err, "")
+		}
+		data = append(b, '\n')
+	} else {
+		b, err := yaml.Marshal(bundle)
+		if err != nil {
+			fatalf("failed to encode bundle", err, "")
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "# a2acli environments, exported %s\n", time.Now().Format("2006-01-02"))
+		fmt.Fprintf(&sb, "# Import with: a2acli config env import <this file>\n")
+		if exportWithSecrets {
+			sb.WriteString("# WARNING: contains secrets.\n")
+		}
+		for _, h := range header {
+			sb.WriteString("#   " + h + "\n")
+		}
+		sb.Write(b)
+		data = []byte(sb.String())
+	}
+
+	if exportWithSecrets {
+		fmt.Fprintf(os.Stderr, "%s the bundle contains secrets; share it only over a secure channel.\n", StyleWarn.Render("Warning:"))
+	}
+	if exportFile == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	mode := os.FileMode(0o644)
+	if exportWithSecrets {
+		mode = 0o600
+	}
+	if err := os.WriteFile(exportFile, data, mode); err != nil {
+		fatalf("failed to write bundle", err, "")
+	}
+	fmt.Fprintf(os.Stderr, "Exported %s to %s\n", strings.Join(names, ", "), exportFile)
+}
+
+// readBundle reads an environment bundle from a file, an http(s) URL, or
+// stdin when src is "-".
+func readBundle(ctx context.Context, src string) ([]byte, error) {
+	if src == "-" {
+		return io.ReadAll(io.LimitReader(os.Stdin, maxBundleSize))
+	}
+	if strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://") {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := newHTTPClient(30 * time.Second).Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", src, resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxBundleSize))
+	}
+	return os.ReadFile(src)
+}
+
+// bundleEnvs parses a bundle and returns the environments named in only, or
+// all of them when only is empty.
+func bundleEnvs(data []byte, only []string) (map[string]any, error) {
+	var bundle struct {
+		Envs map[string]map[string]any `yaml:"envs"`
+	}
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+	if len(bundle.Envs) == 0 {
+		return nil, errors.New("no envs: section found")
+	}
+	envs := map[string]any{}
+	for name, env := range bundle.Envs {
+		envs[name] = env
+	}
+	if len(only) == 0 {
+		return envs, nil
+	}
+	selected := map[string]any{}
+	for _, name := range only {
+		env, ok := envs[name]
+		if !ok {
+			return nil, fmt.Errorf("the bundle has no environment %q", name)
+		}
+		selected[name] = env
+	}
+	return selected, nil
+}
+
+// envCommands returns the settings of envs that run programs on this machine
+// (token_command, helper: token stores and signing keys) as "env: key value"
+// lines, and removes them from envs unless keep is set.
+func envCommands(envs map[string]any, keep bool) []string {
+	var commands []string
+	for name, e := range envs {
+		env, _ := e.(map[string]any)
+		found := func(m map[string]any, key, path string, helperOnly bool) {
+			v, _ := m[key].(string)
+			if v == "" || helperOnly && !strings.HasPrefix(v, "helper:") {
+				return
+			}
+			commands = append(commands, fmt.Sprintf("%s: %s %s", name, path, v))
+			if !keep {
+				delete(m, key)
+			}
+		}
+		found(env, "token_command", "token_command", false)
+		found(env, "token_store", "token_store", true)
+		if signing, ok := env["signing"].(map[string]any); ok {
+			found(signing, "key", "signing.key", true)
+		}
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// importNotes lists what the imported environments need before they work:
+// environment variables and logins.
+func importNotes(envs map[string]any) []string {
+	var needs []string
+	for name, e := range envs {
+		env, _ := e.(map[string]any)
+		if cmd, _ := env["token_command"].(string); cmd != "" {
+			if v, ok := strings.CutPrefix(cmd, "printenv "); ok {
+				needs = append(needs, fmt.Sprintf("%s: export %s=<token>", name, v))
+			}
+		}
+		if fromEnv, ok := env["headers_from_env"].(map[string]any); ok {
+			for h, v := range fromEnv {
+				needs = append(needs, fmt.Sprintf("%s: export %v=<%s value>", name, v, h))
+			}
+		}
+		if _, ok := env["oauth"]; ok {
+			needs = append(needs, fmt.Sprintf("%s: a2acli auth login --env %s", name, name))
+		}
+	}
+	sort.Strings(needs)
+	return needs
+}
+
+func runConfigEnvImport(cmd *cobra.Command, args []string) {
+	src := args[0]
+	data, err := readBundle(cmd.Context(), src)
+	if err != nil {
+		fatalCode(ErrCodeNotFound, "failed to read bundle", err, "Pass a file, an http(s) URL, or - for stdin")
+	}
+	envs, err := bundleEnvs(data, args[1:])
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid bundle "+src, err, "Create one with 'a2acli config env export'")
+	}
+
+	// Check the environments on their own, as they will be written.
+	checked, err := yaml.Marshal(map[string]any{"envs": envs})
+	if err != nil {
+		fatalf("failed to encode bundle", err, "")
+	}
+	dir := ""
+	if src != "-" && !strings.Contains(src, "://") {
+		dir = filepath.Dir(src)
+	}
+	issues, err := validateConfigData(checked, dir, true)
+	if err != nil {
+		fatalf("failed to validate bundle", err, "")
+	}
+	if hasErrors(issues) {
+		printValidation([]validatedFile{{File: src, Valid: false, Issues: issues}})
+		fatalCode(ErrCodeInvalidArgument, "bundle is invalid; nothing imported", nil, "")
+	}
+
+	// A bundle from elsewhere must not get to run programs unreviewed.
+	commands := envCommands(envs, importCommands)
+	if len(commands) > 0 && !disableTUI {
+		w, heading := io.Writer(os.Stderr), StyleWarn.Render("Warning:")+" not importing settings that run programs on this machine:"
+		if importCommands {
+			w, heading = os.Stdout, StyleWarn.Render("Note:")+" these environments run programs on this machine:"
+		}
+		fmt.Fprintln(w, heading)
+		for _, c := range commands {
+			fmt.Fprintf(w, "  %s\n", c)
+		}
+		if !importCommands {
+			fmt.Fprintln(w, "  Review them, then import again with --allow-commands --force to keep them")
+		}
+		fmt.Fprintln(w)
+	}
+
+	file, err := configFilePath()
+	if err != nil {
+		fatalf("failed to locate config file", err, "")
+	}
+	doc, err := readConfigDoc(file)
+	if err != nil {
+		fatalf("failed to read config", err, "Fix the file with 'a2acli config edit'")
+	}
+	existing, _ := doc["envs"].(map[string]any)
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		if _, ok := existing[name]; ok && !importForce {
+			fatalCode(ErrCodeInvalidArgument, "environment already exists", fmt.Errorf("%q", name),
+				"Pass --force to replace it, or import only the other environments by name")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := setPath(doc, []string{"envs", name}, envs[name]); err != nil {
+			fatalf("cannot import "+name, err, "")
+		}
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		fatalf("failed to encode config", err, "")
+	}
+	if err := writeConfigFile(file, out); err != nil {
+		fatalf("failed to save config", err, "")
+	}
+
+	needs := importNotes(envs)
+	if disableTUI {
+		b, _ := json.MarshalIndent(map[string]any{
+			"file":             file,
+			"imported":         names,
+			"commands":         commands,
+			"commands_skipped": len(commands) > 0 && !importCommands,
+			"next_steps":       needs,
+		}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Printf("Imported %s into %s\n", strings.Join(names, ", "), file)
+	if len(needs) > 0 {
+		fmt.Println("\nBefore using them:")
+		for _, n := range needs {
+			fmt.Printf("  %s\n", n)
+		}
+	}
+}
@@ -0,0 +1,543 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/a2aproject/a2a-go/v2/a2aclient/agentcard"
+	"github.com/a2aproject/a2a-go/v2/a2acompat/a2av0"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ghchinoy/a2acli/internal/oauth"
+)
+
+var envCheckAll bool
+
+// envCheckTimeout bounds each environment's card fetch unless --timeout is
+// given.
+const envCheckTimeout = 10 * time.Second
+
+// tlsExpiryWarning is how close to expiry a server certificate is flagged.
+const tlsExpiryWarning = 14 * 24 * time.Hour
+
+// Environment check statuses.
+const (
+	checkUp           = "up"
+	checkUnauthorized = "unauthorized"
+	checkTLSError     = "tls-error"
+	checkDown         = "down"
+	checkError        = "error"
+)
+
+// envCheck is the health of one environment.
+type envCheck struct {
+	Name       string `json:"name"`
+	ServiceURL string `json:"service_url"`
+	IsDefault  bool   `json:"is_default"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	LatencyMS  int64  `json:"latency_ms"`
+	// TLS is "valid", "expiring", "insecure" or empty for plain HTTP.
+	TLS        string     `json:"tls,omitempty"`
+	TLSExpires *time.Time `json:"tls_expires,omitempty"`
+	Transport  string     `json:"transport,omitempty"`
+	Protocol   string     `json:"protocol,omitempty"`
+	// Token is none, static, command, valid, expired or refreshable.
+	Token        string     `json:"token"`
+	TokenExpires *time.Time `json:"token_expires,omitempty"`
+	Agent        string     `json:"agent,omitempty"`
+	CardVersion  string     `json:"card_version,omitempty"`
+	// CardChanged reports a card that differs from the last check;
+	// PreviousVersion is the version it had then.
+	CardChanged     bool   `json:"card_changed"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+
+	// Settings used by the network check, and the card seen, the document
+	// it was parsed from and its hash.
+	settings envSettings
+	bearer   string
+	card     *a2a.AgentCard
+	raw      []byte
+	hash     string
+}
+
+// envSettings are the per-environment values the check needs, read from the
+// config without touching the globals of the active environment.
+type envSettings struct {
+	Transport      string
+	Protocol       string
+	Cert, Key, CA  string
+	Insecure       bool
+	Proxy          string
+	NoProxy        []string
+	Headers        map[string]string
+	HeadersFromEnv map[string]string
+	Signing        bool
+}
+
+func loadEnvSettings(name string) envSettings {
+	p := "envs." + name + "."
+	return envSettings{
+		Transport:      viper.GetString(p + "transport"),
+		Protocol:       viper.GetString(p + "protocol"),
+		Cert:           viper.GetString(p + "tls.cert"),
+		Key:            viper.GetString(p + "tls.key"),
+		CA:             viper.GetString(p + "tls.cacert"),
+		Insecure:       viper.GetBool(p + "tls.insecure_skip_verify"),
+		Proxy:          viper.GetString(p + "proxy.url"),
+		NoProxy:        viper.GetStringSlice(p + "proxy.no_proxy"),
+		Headers:        viper.GetStringMapString(p + "headers"),
+		HeadersFromEnv: viper.GetStringMapString(p + "headers_from_env"),
+		Signing:        viper.GetString(p+"signing.key") != "",
+	}
+}
+
+// checkState is what the previous check saw of an environment's card.
+type checkState struct {
+	ServiceURL string    `json:"service_url"`
+	Version    string    `json:"version"`
+	Hash       string    `json:"hash"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+func checkStatePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "a2acli", "env-check.json"), nil
+}
+
+func loadCheckState() map[string]checkState {
+	state := map[string]checkState{}
+	path, err := checkStatePath()
+	if err != nil {
+		return state
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func saveCheckState(state map[string]checkState) error {
+	path, err := checkStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// cardHash identifies a card's content, so changes that keep the version
+// are noticed too.
+func cardHash(card *a2a.AgentCard) string {
+	data, _ := json.Marshal(card)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkTokenState reports the credential an environment would use, without
+// running token commands or refreshing tokens. It returns the bearer token
+// to present when fetching the card, if one is usable.
+func checkTokenState(c *envCheck) string {
+	p := "envs." + c.Name + "."
+	if tok := viper.GetString(p + "token"); tok != "" {
+		c.Token = "static"
+		return tok
+	}
+	if cmd := viper.GetString(p + "token_command"); cmd != "" {
+		c.Token = "command"
+		if tok, err := oauth.LoadCommandToken(c.ServiceURL, cmd); err == nil && tok != nil && !tok.IsExpired() {
+			c.Token = "valid"
+			c.TokenExpires = &tok.ExpiresAt
+			return tok.AccessToken
+		}
+		return ""
+	}
+	c.Token = "none"
+	if err := useTokenStore(c.Name); err != nil {
+		return ""
+	}
+	stored, err := oauth.LoadToken(c.ServiceURL)
+	if err != nil || stored == nil {
+		return ""
+	}
+	switch {
+	case stored.GrantType == oauth.GrantStatic:
+		c.Token = "static"
+		return stored.AccessToken
+	case !stored.IsExpired():
+		c.Token = "valid"
+	case stored.RefreshToken != "" || stored.GrantType == oauth.GrantClientCredentials:
+		c.Token = "refreshable"
+	default:
+		c.Token = "expired"
+	}
+	if !stored.ExpiresAt.IsZero() {
+		c.TokenExpires = &stored.ExpiresAt
+	}
+	if c.Token == "valid" {
+		return stored.AccessToken
+	}
+	return ""
+}
+
+// tlsRecorder remembers the TLS state of the last response it carried.
+type tlsRecorder struct {
+	base http.RoundTripper
+	mu   sync.Mutex
+	last *tls.ConnectionState
+}
+
+func (t *tlsRecorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if resp != nil && resp.TLS != nil {
+		t.mu.Lock()
+		t.last = resp.TLS
+		t.mu.Unlock()
+	}
+	return resp, err
+}
+
+// envTransport returns a transport with the TLS and proxy settings s that
+// reaches serviceURL, and the URL to request in its place: a unix:// service
+// URL is requested as http://<host> over its socket.
+func envTransport(s envSettings, serviceURL string) (*http.Transport, string, error) {
+	target := serviceURL
+	path, host, err := unixSocketTarget(target)
+	if err != nil {
+		return nil, "", err
+	}
+	if path != "" {
+		target = "http://" + host
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if s.Cert != "" || s.Key != "" || s.CA != "" || s.Insecure {
+		if t.TLSClientConfig, err = buildTLSConfig(s.Cert, s.Key, s.CA, s.Insecure); err != nil {
+			return nil, "", err
+		}
+	}
+	proxy, err := buildProxyFunc(s.Proxy, s.NoProxy, host)
+	if err != nil {
+		return nil, "", err
+	}
+	t.Proxy = httpProxy(proxy)
+	if path != "" {
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+	}
+	return t, target, nil
+}
+
+// checkEnv fetches the environment's AgentCard with its own TLS, proxy and
+// header settings and fills in c.
+func checkEnv(ctx context.Context, c *envCheck, timeout time.Duration) {
+	s := c.settings
+	t, target, err := envTransport(s, c.ServiceURL)
+	if err != nil {
+		c.Status, c.Error = checkError, err.Error()
+		return
+	}
+	rec := &tlsRecorder{base: t}
+	resolver := &agentcard.Resolver{Client: &http.Client{Timeout: timeout, Transport: rec}}
+	if strings.HasPrefix(s.Protocol, "0.3") {
+		resolver.CardParser = a2av0.NewAgentCardParser()
+	}
+	resp := recordCardResponse(resolver)
+
+	var opts []agentcard.ResolveOption
+	if c.bearer != "" {
+		opts = append(opts, agentcard.WithRequestHeader("Authorization", "Bearer "+c.bearer))
+	}
+	for name, value := range s.Headers {
+		opts = append(opts, agentcard.WithRequestHeader(name, value))
+	}
+	for name, variable := range s.HeadersFromEnv {
+		if value := os.Getenv(variable); value != "" {
+			opts = append(opts, agentcard.WithRequestHeader(name, value))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	card, err := resolver.Resolve(ctx, target, opts...)
+	c.LatencyMS = time.Since(start).Milliseconds()
+
+	if st := rec.last; st != nil && len(st.PeerCertificates) > 0 {
+		exp := st.PeerCertificates[0].NotAfter
+		c.TLSExpires = &exp
+		switch {
+		case s.Insecure:
+			c.TLS = "insecure"
+		case time.Until(exp) < tlsExpiryWarning:
+			c.TLS = "expiring"
+		default:
+			c.TLS = "valid"
+		}
+	}
+
+	if err != nil {
+		c.Status, c.Error = classifyCheckError(err), err.Error()
+		return
+	}
+	c.Status = checkUp
+	c.card = card
+	c.raw = resp.body
+	c.hash = cardHash(card)
+	c.Agent = card.Name
+	c.CardVersion = card.Version
+	if tr, err := selectTransport(card, s.Transport, s.Signing); err == nil {
+		c.Transport = string(tr)
+		for _, iface := range card.SupportedInterfaces {
+			if iface.ProtocolBinding == tr && iface.ProtocolVersion != "" {
+				c.Protocol = string(iface.ProtocolVersion)
+				break
+			}
+		}
+	}
+	if c.Protocol == "" {
+		c.Protocol = s.Protocol
+	}
+}
+
+// classifyCheckError maps a card fetch error to a check status.
+func classifyCheckError(err error) string {
+	var status *agentcard.ErrStatusNotOK
+	if errors.As(err, &status) {
+		if status.StatusCode == http.StatusUnauthorized || status.StatusCode == http.StatusForbidden {
+			return checkUnauthorized
+		}
+		return checkError
+	}
+	var verr *tls.CertificateVerificationError
+	var unknown x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &verr) || errors.As(err, &unknown) || errors.As(err, &hostname) || errors.As(err, &invalid) {
+		return checkTLSError
+	}
+	var netErr net.Error
+	var opErr *net.OpError
+	if errors.As(err, &netErr) || errors.As(err, &opErr) || errors.Is(err, context.DeadlineExceeded) {
+		return checkDown
+	}
+	return checkError
+}
+
+// newEnvCheck reads an environment's settings and looks up the credential
+// its card request will present.
+func newEnvCheck(name string) *envCheck {
+	c := &envCheck{
+		Name:       name,
+		ServiceURL: viper.GetString("envs." + name + ".service_url"),
+		settings:   loadEnvSettings(name),
+	}
+	if path, host, err := unixSocketTarget(c.ServiceURL); err == nil && path != "" {
+		// Tokens for unix socket agents are stored under the placeholder
+		// URL, as in useUnixSocket.
+		url := c.ServiceURL
+		c.ServiceURL = "http://" + host
+		c.bearer = checkTokenState(c)
+		c.ServiceURL = url
+	} else {
+		c.bearer = checkTokenState(c)
+	}
+	return c
+}
+
+// runEnvChecks checks the named environments concurrently and records the
+// cards seen, so the next run can report changes.
+func runEnvChecks(ctx context.Context, names []string, timeout time.Duration) []*envCheck {
+	defaultEnv := viper.GetString("default_env")
+	if defaultEnv == "" {
+		defaultEnv = "default"
+	}
+	checks := make([]*envCheck, len(names))
+	// Token stores are selected process-wide, so credentials are looked up
+	// one environment at a time before the concurrent network checks.
+	for i, name := range names {
+		checks[i] = newEnvCheck(name)
+		checks[i].IsDefault = name == defaultEnv
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		if c.ServiceURL == "" {
+			c.Status, c.Error = checkError, "no service_url"
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkEnv(ctx, c, timeout)
+		}()
+	}
+	wg.Wait()
+
+	state := loadCheckState()
+	for _, c := range checks {
+		if c.Status != checkUp {
+			continue
+		}
+		prev, seen := state[c.Name]
+		if seen && prev.ServiceURL == c.ServiceURL && prev.Hash != c.hash {
+			c.CardChanged = true
+			c.PreviousVersion = prev.Version
+		}
+		state[c.Name] = checkState{ServiceURL: c.ServiceURL, Version: c.CardVersion, Hash: c.hash, CheckedAt: time.Now()}
+	}
+	if err := saveCheckState(state); err != nil {
+		verboseLog("failed to save env check state: %v", err)
+	}
+	return checks
+}
+
+func runConfigEnvCheck(cmd *cobra.Command, args []string) {
+	names := args
+	if envCheckAll {
+		names = nil
+		for name := range viper.GetStringMap("envs") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		name := envName
+		if name == "" {
+			name = viper.GetString("default_env")
+		}
+		if name == "" {
+			fatalCode(ErrCodeInvalidArgument, "no environment to check", nil, "Name one, pass --all, or set one with 'a2acli config env use'")
+		}
+		names = []string{name}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !viper.IsSet("envs." + name) {
+			fatalCode(ErrCodeNotFound, "environment not found", fmt.Errorf("%q", name), "Run 'a2acli config env list' to see available environments")
+		}
+	}
+
+	timeout := envCheckTimeout
+	if rootCmd.Flag("timeout").Changed && requestTimeout > 0 {
+		timeout = requestTimeout
+	}
+	checks := runEnvChecks(cmd.Context(), names, timeout)
+
+	failed := false
+	for _, c := range checks {
+		failed = failed || c.Status != checkUp
+	}
+	if disableTUI {
+		b, _ := json.MarshalIndent(checks, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		printEnvChecks(checks)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printEnvChecks(checks []*envCheck) {
+	rows := [][]string{{"ENV", "STATUS", "LATENCY", "TLS", "TRANSPORT", "PROTOCOL", "TOKEN", "CARD"}}
+	for _, c := range checks {
+		name := c.Name
+		if c.IsDefault {
+			name += " *"
+		}
+		status := StylePass.Render(c.Status)
+		if c.Status != checkUp {
+			status = StyleFail.Render(c.Status)
+		}
+		latency := "-"
+		if c.Status == checkUp {
+			latency = fmt.Sprintf("%dms", max(c.LatencyMS, 1))
+		}
+		tlsInfo := "-"
+		if c.TLSExpires != nil {
+			tlsInfo = fmt.Sprintf("%s (%s)", c.TLS, c.TLSExpires.Format("2006-01-02"))
+			if c.TLS != "valid" {
+				tlsInfo = StyleWarn.Render(tlsInfo)
+			}
+		}
+		token := c.Token
+		switch c.Token {
+		case "valid":
+			token = StylePass.Render(token)
+		case "expired":
+			token = StyleFail.Render(token)
+		case "refreshable":
+			token = StyleWarn.Render(token)
+		}
+		card := "-"
+		if c.Status == checkUp {
+			card = c.Agent
+			if c.CardVersion != "" {
+				card += " " + c.CardVersion
+			}
+			if c.CardChanged {
+				from := c.PreviousVersion
+				if from == "" || from == c.CardVersion {
+					from = "previous card"
+				}
+				card += StyleWarn.Render(" (changed since " + from + ")")
+			}
+		}
+		rows = append(rows, []string{name, status, latency, tlsInfo, dash(c.Transport), dash(c.Protocol), token, card})
+	}
+	printTable(os.Stdout, rows)
+	for _, c := range checks {
+		if c.Error != "" {
+			fmt.Printf("%s %s: %s\n", StyleFail.Render("✗"), c.Name, c.Error)
+		}
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/spf13/viper"
+)
+
+// cardServer serves an AgentCard whose version can be changed, and requires
+// the bearer token when token is set.
+func cardServer(version *atomic.Value, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		card := a2a.AgentCard{
+			Name:    "checked",
+			Version: version.Load().(string),
+			SupportedInterfaces: []*a2a.AgentInterface{
+				{URL: "http://" + r.Host, ProtocolBinding: a2a.TransportProtocolJSONRPC, ProtocolVersion: "1.0"},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(card)
+	})
+}
+
+func TestRunEnvChecks(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	var version atomic.Value
+	version.Store("1.0.0")
+	plain := httptest.NewServer(cardServer(&version, ""))
+	defer plain.Close()
+	secured := httptest.NewServer(cardServer(&version, "good"))
+	defer secured.Close()
+	tlsSrv := httptest.NewTLSServer(cardServer(&version, ""))
+	defer tlsSrv.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := "http://" + l.Addr().String()
+	_ = l.Close()
+
+	viper.Set("default_env", "plain")
+	viper.Set("envs", map[string]any{
+		"plain":     map[string]any{"service_url": plain.URL},
+		"authed":    map[string]any{"service_url": secured.URL, "token": "good"},
+		"denied":    map[string]any{"service_url": secured.URL, "token": "bad"},
+		"insecure":  map[string]any{"service_url": tlsSrv.URL, "tls": map[string]any{"insecure_skip_verify": true}},
+		"untrusted": map[string]any{"service_url": tlsSrv.URL},
+		"down":      map[string]any{"service_url": down},
+	})
+
+	names := []string{"authed", "denied", "down", "insecure", "plain", "untrusted"}
+	byName := func(checks []*envCheck) map[string]*envCheck {
+		m := map[string]*envCheck{}
+		for _, c := range checks {
+			m[c.Name] = c
+		}
+		return m
+	}
+	got := byName(runEnvChecks(context.Background(), names, 5*time.Second))
+
+	want := map[string]string{
+		"plain":     checkUp,
+		"authed":    checkUp,
+		"denied":    checkUnauthorized,
+		"insecure":  checkUp,
+		"untrusted": checkTLSError,
+		"down":      checkDown,
+	}
+	for name, status := range want {
+		if c := got[name]; c.Status != status {
+			t.Errorf("%s: status %s (%s), want %s", name, c.Status, c.Error, status)
+		}
+	}
+	if c := got["plain"]; !c.IsDefault || c.Transport != string(a2a.TransportProtocolJSONRPC) || c.Protocol != "1.0" ||
+		c.Agent != "checked" || c.CardVersion != "1.0.0" || c.Token != "none" || c.CardChanged {
+		t.Errorf("plain = %+v", c)
+	}
+	if c := got["authed"]; c.Token != "static" {
+		t.Errorf("authed token = %s", c.Token)
+	}
+	if c := got["insecure"]; c.TLS != "insecure" || c.TLSExpires == nil {
+		t.Errorf("insecure TLS = %q, expires %v", c.TLS, c.TLSExpires)
+	}
+
+	version.Store("1.1.0")
+	got = byName(runEnvChecks(context.Background(), []string{"plain"}, 5*time.Second))
+	if c := got["plain"]; !c.CardChanged || c.PreviousVersion != "1.0.0" || c.CardVersion != "1.1.0" {
+		t.Errorf("after a version change: %+v", c)
+	}
+	got = byName(runEnvChecks(context.Background(), []string{"plain"}, 5*time.Second))
+	if c := got["plain"]; c.CardChanged {
+		t.Errorf("unchanged card reported as changed: %+v", c)
+	}
+}
+
+func TestClassifyCheckError(t *testing.T) {
+	if s := classifyCheckError(fmt.Errorf("card parsing failed: %w", json.Unmarshal([]byte("x"), new(any)))); s != checkError {
+		t.Errorf("parse error: %s", s)
+	}
+	if s := classifyCheckError(context.DeadlineExceeded); s != checkDown {
+		t.Errorf("timeout: %s", s)
+	}
+}
+
+func TestPrintTableStyledCells(t *testing.T) {
+	// Escape codes of styled cells take no room on the terminal.
+	bold := func(s string) string { return "\x1b[1m" + s + "\x1b[0m" }
+	var out strings.Builder
+	printTable(&out, [][]string{
+		{"ENV", "STATUS", "TOKEN"},
+		{"local", bold("up"), "valid"},
+		{"prod", bold("unreachable"), "-"},
+	})
+	var plain []string
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		plain = append(plain, strings.NewReplacer("\x1b[1m", "", "\x1b[0m", "").Replace(line))
+	}
+	want := []string{
+		"ENV    STATUS       TOKEN",
+		"local  up           valid",
+		"prod   unreachable  -",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Errorf("table:\n%s\nwant:\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/ghchinoy/a2acli/internal/cardlint"
+	"github.com/ghchinoy/a2acli/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentSpec     cardSpec
+	agentDefaults bool
+	agentLang     string
+	agentModule   string
+	agentCard     string
+	agentForce    bool
+)
+
+// setupInitCmd builds the `init` command group, which starts new projects.
+func setupInitCmd() *cobra.Command {
+	initCmd := &cobra.Command{
+		Use:     "init",
+		GroupID: GroupServer,
+		Short:   "Start a new A2A agent project",
+		Long: `Start new projects. 'init agent' generates a runnable agent from an
+AgentCard; use 'a2acli card init' to create just the card.`,
+	}
+
+	agentCmd := &cobra.Command{
+		Use:   "agent [dir]",
+		Short: "Generate a runnable agent server from an AgentCard",
+		Long: `Generate an agent project that builds, runs and passes its tests as is:
+
+  main.go            Serves the card at /.well-known/agent-card.json and the
+                     card's JSON-RPC, HTTP+JSON and gRPC interfaces
+  executor.go        The agent's logic: one case per skill, to fill in
+  auth.go            Rejects requests without the credentials the card
+                     requires (presence only; add real verification)
+  *_test.go          Tests of the card, each interface and each skill
+  agent-card.json    The card, embedded in the binary
+
+The card comes from --card (a file, URL, env:<name> or cache:<url>, as in the
+card commands), or from the same questions and flags as 'a2acli card init'.
+The interfaces are served at the paths and ports of the card's URLs.
+
+The project is written to dir, by default a directory named after the agent.
+Existing files are not overwritten unless --force is given.`,
+		Example: `  a2acli init agent
+  a2acli init agent weather --card weather-card.json --module github.com/me/weather
+  a2acli init agent echo --defaults --name echo --binding jsonrpc,rest,grpc`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runInitAgent,
+	}
+	addCardSpecFlags(agentCmd.Flags(), &agentSpec)
+	agentCmd.Flags().BoolVarP(&agentDefaults, "defaults", "y", false, "Ask nothing; use the flags and defaults")
+	agentCmd.Flags().StringVar(&agentLang, "lang", "go", "Language of the project: "+strings.Join(scaffold.Languages(), ", "))
+	agentCmd.Flags().StringVar(&agentModule, "module", "", "Go module path (default example.com/<agent name>)")
+	agentCmd.Flags().StringVar(&agentCard, "card", "", "Generate the agent of an existing card instead of asking")
+	agentCmd.Flags().BoolVar(&agentForce, "force", false, "Overwrite existing files")
+
+	initCmd.AddCommand(agentCmd)
+	return initCmd
+}
+
+func runInitAgent(cmd *cobra.Command, args []string) {
+	lang := strings.ToLower(agentLang)
+	if !slices.Contains(scaffold.Languages(), lang) {
+		fatalCode(ErrCodeInvalidArgument, "unsupported language", errors.New(agentLang), "Supported: "+strings.Join(scaffold.Languages(), ", "))
+	}
+
+	var card *a2a.AgentCard
+	if agentCard != "" {
+		noCache = true
+		doc, err := loadCardSource(cmd.Context(), agentCard)
+		if err != nil {
+			fatalCode(ErrCodeInvalidArgument, "failed to load card", err, "")
+		}
+		card = doc.Card
+	} else {
+		if agentSpec.Name == "" && len(args) > 0 {
+			agentSpec.Name = filepath.Base(args[0])
+		}
+		if !agentDefaults {
+			askCardSpec(newPrompter(os.Stdin, os.Stderr), &agentSpec, cmd.Flags())
+		}
+		var err error
+		if card, err = buildCard(&agentSpec); err != nil {
+			fatalCode(ErrCodeInvalidArgument, "invalid card", err, "")
+		}
+	}
+	// A 0.3 card is written out as the 1.0 card it was converted to.
+	raw, _ := json.MarshalIndent(card, "", "  ")
+
+	dir := slug(card.Name)
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	module := agentModule
+	if module == "" {
+		module = "example.com/" + slug(card.Name)
+	}
+	files, err := scaffold.Render(lang, scaffold.Project{Module: module, Card: card, CardJSON: raw, A2AVersion: a2aModuleVersion()})
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "failed to generate the project", err, "")
+	}
+	if !agentForce {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(dir, f.Path)); err == nil {
+				fatalCode(ErrCodeInvalidArgument, "file exists", errors.New(filepath.Join(dir, f.Path)), "Pass --force to overwrite the project's files, or choose another directory")
+			}
+		}
+	}
+	var paths []string
+	for _, f := range files {
+		name := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			fatalf("failed to create the project", err, "")
+		}
+		if err := os.WriteFile(name, f.Data, 0o644); err != nil {
+			fatalf("failed to create the project", err, "")
+		}
+		verboseLog("wrote %s", name)
+		paths = append(paths, f.Path)
+	}
+
+	findings := cardlint.Lint(cmd.Context(), raw, card, cardlint.Options{Offline: true})
+	if disableTUI {
+		if findings == nil {
+			findings = []cardlint.Finding{}
+		}
+		b, _ := json.MarshalIndent(map[string]any{"dir": dir, "module": module, "lang": lang, "files": paths, "findings": findings}, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+
+	fmt.Printf("%s Generated %s in %s\n", StylePass.Render("✓"), StyleAccent.Render(card.Name), dir)
+	for _, p := range paths {
+		fmt.Printf("  %s\n", p)
+	}
+	for _, f := range findings {
+		fmt.Printf("%s %s %s\n", StyleWarn.Render(string(f.Severity)), f.Message, StyleMuted.Render("["+f.Rule+"]"))
+	}
+	fmt.Println()
+	fmt.Println("Next steps:")
+	for _, step := range []string{
+		"cd " + dir,
+		"go mod tidy",
+		"go test ./...",
+		"go run .",
+		"a2acli discover -u " + scaffold.BaseURL(card),
+	} {
+		fmt.Printf("  %s\n", StyleCommand.Render(step))
+	}
+	fmt.Println(StyleMuted.Render("Then fill in the skills in executor.go and the credential checks in auth.go."))
+}
+
+// a2aModuleVersion is the a2a-go version this binary was built with, which
+// generated projects are known to work with.
+func a2aModuleVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range bi.Deps {
+			if dep.Path == "github.com/a2aproject/a2a-go/v2" && strings.HasPrefix(dep.Version, "v") {
+				return dep.Version
+			}
+		}
+	}
+	return scaffold.DefaultA2AVersion
+}
@@ -0,0 +1,403 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// projectConfigName is the project-level config file, looked up from the
+// current directory towards the home directory.
+const projectConfigName = ".a2acli.yaml"
+
+// Config layer kinds, lowest precedence first.
+const (
+	layerUser    = "user"
+	layerInclude = "include"
+	layerProject = "project"
+)
+
+// configLayer is one config file that contributes to the effective
+// configuration.
+type configLayer struct {
+	Kind string
+	File string
+	// keys holds the file's settings as flattened, lower-cased dotted keys.
+	keys map[string]bool
+}
+
+var (
+	// configLayers lists the files merged into viper, lowest precedence
+	// first: the user config, then the project file's includes in order,
+	// then the project file itself.
+	configLayers []configLayer
+
+	// userConfig holds only the user's own config file. The config
+	// commands write to it, so values from project layers are never copied
+	// into the user file.
+	userConfig = viper.New()
+)
+
+// setConfig sets key in the user config and in the effective configuration.
+func setConfig(key string, value any) {
+	userConfig.Set(key, value)
+	viper.Set(key, value)
+}
+
+// findProjectConfig returns the nearest .a2acli.yaml in dir or its parents.
+// The search stops below the home directory, where ~/.a2acli.yaml is the
+// legacy user config rather than a project file.
+func findProjectConfig(dir, home string) string {
+	for {
+		if home != "" && dir == home {
+			return ""
+		}
+		path := filepath.Join(dir, projectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// envRef matches ${NAME} and ${NAME:-default}.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnvRefs replaces ${NAME} and ${NAME:-default} references in s with
+// environment variables. An unset variable without a default expands to an
+// empty string.
+func expandEnvRefs(s, file string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRef.FindStringSubmatch(ref)
+		if val := os.Getenv(m[1]); val != "" {
+			return val
+		}
+		if !strings.Contains(ref, ":-") {
+			verboseLog("%s: ${%s} is not set", file, m[1])
+		}
+		return m[2]
+	})
+}
+
+// interpolate expands environment references in the string values of v.
+func interpolate(v any, file string) any {
+	switch t := v.(type) {
+	case string:
+		return expandEnvRefs(t, file)
+	case map[string]any:
+		for k, e := range t {
+			t[k] = interpolate(e, file)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = interpolate(e, file)
+		}
+	}
+	return v
+}
+
+// fileKeys are settings holding file paths, which are resolved relative to
+// the config file that sets them.
+var fileKeys = []string{"cert", "key", "cacert"}
+
+// resolvePaths makes the relative tls and signing key paths of each
+// environment in doc relative to dir.
+func resolvePaths(doc map[string]any, dir string) {
+	envs, _ := doc["envs"].(map[string]any)
+	for _, e := range envs {
+		env, _ := e.(map[string]any)
+		for _, section := range []string{"tls", "signing", "card_signature"} {
+			m, _ := env[section].(map[string]any)
+			for _, k := range fileKeys {
+				p, ok := m[k].(string)
+				if !ok || p == "" || strings.HasPrefix(p, "helper:") || filepath.IsAbs(p) || strings.HasPrefix(p, "~") {
+					continue
+				}
+				m[k] = filepath.Join(dir, p)
+			}
+		}
+	}
+	trusted, _ := doc["trusted_card_keys"].([]any)
+	for _, t := range trusted {
+		m, _ := t.(map[string]any)
+		if p, ok := m["key"].(string); ok && p != "" && !filepath.IsAbs(p) && !strings.HasPrefix(p, "~") {
+			m["key"] = filepath.Join(dir, p)
+		}
+	}
+}
+
+// loadLayerFile reads a project or included file, and recursively the files
+// it includes. It returns the layers in merge order, each with its document.
+// Environment references are only expanded when expand is set.
+func loadLayerFile(path, kind string, seen map[string]bool, expand bool) ([]configLayer, []map[string]any, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if seen[abs] {
+		return nil, nil, fmt.Errorf("%s is included more than once (include cycle?)", abs)
+	}
+	seen[abs] = true
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", abs, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	if expand {
+		doc = interpolate(doc, abs).(map[string]any)
+	}
+	dir := filepath.Dir(abs)
+	resolvePaths(doc, dir)
+
+	var layers []configLayer
+	var docs []map[string]any
+	includes, _ := doc["include"].([]any)
+	delete(doc, "include")
+	for _, inc := range includes {
+		p, ok := inc.(string)
+		if !ok || p == "" {
+			return nil, nil, fmt.Errorf("%s: include entries must be file paths", abs)
+		}
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				p = filepath.Join(home, rest)
+			}
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		l, d, err := loadLayerFile(p, layerInclude, seen, expand)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: include: %w", abs, err)
+		}
+		layers = append(layers, l...)
+		docs = append(docs, d...)
+	}
+	layers = append(layers, configLayer{Kind: kind, File: abs, keys: flattenKeys(doc, "")})
+	docs = append(docs, doc)
+	return layers, docs, nil
+}
+
+// untrustedEnvKeys are the environment settings that decide where
+// credentials are sent, which servers and agents are trusted, or which
+// environment variables are read into requests.
+var untrustedEnvKeys = []string{"headers_from_env", "proxy", "credential_hosts", "card_signature"}
+
+// untrustedKeys removes the settings of doc that make a2acli run local
+// programs (token_command, helper: token stores and signing keys), that
+// trust AgentCard signing keys, or that redirect or weaken connections,
+// returning their dotted paths. It also removes every environment the user
+// config defines, so a project cannot change where an existing environment's
+// credentials go. A project file can only set them once the project is
+// listed in the user config's trusted_projects.
+func untrustedKeys(doc map[string]any) []string {
+	var dropped []string
+	isHelper := func(v any) bool {
+		s, _ := v.(string)
+		return strings.HasPrefix(s, "helper:")
+	}
+	if isHelper(doc["token_store"]) {
+		delete(doc, "token_store")
+		dropped = append(dropped, "token_store")
+	}
+	// Trusted card keys vouch for every agent, not just the project's own.
+	if _, ok := doc["trusted_card_keys"]; ok {
+		delete(doc, "trusted_card_keys")
+		dropped = append(dropped, "trusted_card_keys")
+	}
+	envs, _ := doc["envs"].(map[string]any)
+	for name, e := range envs {
+		if userConfig.IsSet("envs." + name) {
+			delete(envs, name)
+			dropped = append(dropped, "envs."+name)
+			continue
+		}
+		env, _ := e.(map[string]any)
+		if _, ok := env["token_command"]; ok {
+			delete(env, "token_command")
+			dropped = append(dropped, "envs."+name+".token_command")
+		}
+		if isHelper(env["token_store"]) {
+			delete(env, "token_store")
+			dropped = append(dropped, "envs."+name+".token_store")
+		}
+		if signing, ok := env["signing"].(map[string]any); ok && isHelper(signing["key"]) {
+			delete(signing, "key")
+			dropped = append(dropped, "envs."+name+".signing.key")
+		}
+		for _, k := range untrustedEnvKeys {
+			if _, ok := env[k]; ok {
+				delete(env, k)
+				dropped = append(dropped, "envs."+name+"."+k)
+			}
+		}
+		if tls, ok := env["tls"].(map[string]any); ok {
+			if _, ok := tls["insecure_skip_verify"]; ok {
+				delete(tls, "insecure_skip_verify")
+				dropped = append(dropped, "envs."+name+".tls.insecure_skip_verify")
+			}
+		}
+	}
+	slices.Sort(dropped)
+	return dropped
+}
+
+// projectTrusted reports whether the user config trusts the project rooted
+// at dir.
+func projectTrusted(dir string) bool {
+	for _, p := range userConfig.GetStringSlice("trusted_projects") {
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				p = filepath.Join(home, rest)
+			}
+		}
+		if abs, err := filepath.Abs(p); err == nil && abs == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// loadConfigLayers records the user config as the first layer and merges
+// the nearest project config, with its includes, over it.
+func loadConfigLayers() error {
+	configLayers = nil
+	userConfig = viper.New()
+	if f := viper.ConfigFileUsed(); f != "" {
+		userConfig.SetConfigFile(f)
+		if err := userConfig.ReadInConfig(); err == nil {
+			configLayers = append(configLayers, configLayer{Kind: layerUser, File: f, keys: flattenKeys(userConfig.AllSettings(), "")})
+		}
+	}
+
+	if os.Getenv("A2ACLI_NO_PROJECT_CONFIG") != "" {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	project := findProjectConfig(cwd, home)
+	if project == "" {
+		return nil
+	}
+	// ${VAR} references could copy secrets from the environment into
+	// headers or URLs, so they are only expanded in trusted projects.
+	trusted := projectTrusted(filepath.Dir(project))
+	layers, docs, err := loadLayerFile(project, layerProject, map[string]bool{}, trusted)
+	if err != nil {
+		return err
+	}
+
+	for i, doc := range docs {
+		if !trusted {
+			if dropped := untrustedKeys(doc); len(dropped) > 0 {
+				fmt.Fprintf(os.Stderr, "%s ignoring %s from %s: the project is not trusted.\n  To allow it: a2acli config set trusted_projects '[%s]'\n",
+					StyleWarn.Render("Warning:"), strings.Join(dropped, ", "), layers[i].File, filepath.Dir(project))
+				layers[i].keys = flattenKeys(doc, "")
+			}
+		}
+		if err := viper.MergeConfigMap(doc); err != nil {
+			return fmt.Errorf("%s: %w", layers[i].File, err)
+		}
+		verboseLog("merged %s config %s", layers[i].Kind, layers[i].File)
+	}
+	configLayers = append(configLayers, layers...)
+	return nil
+}
+
+// flattenKeys returns the leaf keys of doc as lower-cased dotted paths.
+func flattenKeys(doc map[string]any, prefix string) map[string]bool {
+	out := map[string]bool{}
+	for k, v := range doc {
+		key := prefix + strings.ToLower(k)
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			for sub := range flattenKeys(m, key+".") {
+				out[sub] = true
+			}
+			continue
+		}
+		out[key] = true
+	}
+	return out
+}
+
+// configSource returns the layer that supplies key in the effective
+// configuration, or nil when no config file sets it.
+func configSource(key string) *configLayer {
+	key = strings.ToLower(key)
+	for i := len(configLayers) - 1; i >= 0; i-- {
+		l := &configLayers[i]
+		if l.keys[key] {
+			return l
+		}
+		// A map or list value set as a whole, e.g. headers or svc_params.
+		for k := range l.keys {
+			if strings.HasPrefix(k, key+".") {
+				return l
+			}
+		}
+	}
+	return nil
+}
+
+// displayPath shortens path relative to the current directory when that is
+// shorter.
+func displayPath(path string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, path); err == nil && len(rel) < len(path) {
+			return rel
+		}
+	}
+	return path
+}
+
+// origin returns a muted note naming where a value shown by `a2acli config`
+// comes from: the command-line flag when one was given, otherwise the
+// environment variable or config layer that sets key.
+func origin(flag, key string) string {
+	if flag != "" {
+		if f := rootCmd.Flag(flag); f != nil && f.Changed {
+			return StyleMuted.Render(" (from --" + flag + ")")
+		}
+	}
+	if v := "A2ACLI_" + strings.ToUpper(key); os.Getenv(v) != "" {
+		return StyleMuted.Render(" (from $" + v + ")")
+	}
+	if l := configSource(key); l != nil {
+		return StyleMuted.Render(fmt.Sprintf(" (from %s: %s)", l.Kind, displayPath(l.File)))
+	}
+	return ""
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// writeFiles creates files under dir from a map of relative path to content.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadLayersIn reads userFile as the user config and loads the project
+// layers seen from dir.
+func loadLayersIn(t *testing.T, userFile, dir string) error {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { configLayers, userConfig = nil, viper.New() })
+	viper.SetConfigFile(userFile)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	return loadConfigLayers()
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	home := t.TempDir()
+	writeFiles(t, home, map[string]string{
+		".a2acli.yaml":          "legacy user config",
+		"repo/.a2acli.yaml":     "envs: {}",
+		"repo/svc/api/keep.txt": "",
+	})
+	if got := findProjectConfig(filepath.Join(home, "repo", "svc", "api"), home); got != filepath.Join(home, "repo", ".a2acli.yaml") {
+		t.Errorf("from a subdirectory: %q", got)
+	}
+	// ~/.a2acli.yaml is the legacy user config, not a project file.
+	if got := findProjectConfig(home, home); got != "" {
+		t.Errorf("at home: %q", got)
+	}
+	if got := findProjectConfig(filepath.Join(home, "repo"), filepath.Join(home, "repo")); got != "" {
+		t.Errorf("search went past home: %q", got)
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	t.Setenv("A2ACLI_NO_PROJECT_CONFIG", "")
+	t.Setenv("TEAM_TOKEN", "s3cret")
+	t.Setenv("UNSET_FOR_TEST", "")
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	writeFiles(t, root, map[string]string{
+		"user.yaml": `default_env: local
+trusted_projects:
+  - ` + filepath.Join(root, "repo") + `
+envs:
+  local:
+    service_url: http://localhost:9001
+  staging:
+    service_url: http://user-staging
+    retries: 1
+`,
+		"shared/team.yaml": `envs:
+  staging:
+    service_url: http://team-staging
+    timeout: 30s
+    headers:
+      X-Team: platform
+`,
+		"repo/.a2acli.yaml": `include:
+  - ../shared/team.yaml
+default_env: staging
+envs:
+  staging:
+    token: ${TEAM_TOKEN}
+    default_skill: ${UNSET_FOR_TEST:-triage}
+    tls:
+      cacert: certs/ca.pem
+`,
+		"repo/src/keep.txt": "",
+	})
+
+	if err := loadLayersIn(t, filepath.Join(root, "user.yaml"), filepath.Join(root, "repo", "src")); err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, l := range configLayers {
+		kinds = append(kinds, l.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "user,include,project" {
+		t.Fatalf("layers = %s", got)
+	}
+
+	for key, want := range map[string]string{
+		"default_env":                 "staging",
+		"envs.local.service_url":      "http://localhost:9001",
+		"envs.staging.service_url":    "http://team-staging",
+		"envs.staging.retries":        "1",
+		"envs.staging.timeout":        "30s",
+		"envs.staging.headers.x-team": "platform",
+		"envs.staging.token":          "s3cret",
+		"envs.staging.default_skill":  "triage",
+		"envs.staging.tls.cacert":     filepath.Join(root, "repo", "certs", "ca.pem"),
+	} {
+		if got := viper.GetString(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	for key, want := range map[string]string{
+		"default_env":              layerProject,
+		"envs.staging.service_url": layerInclude,
+		"envs.staging.headers":     layerInclude,
+		"envs.staging.retries":     layerUser,
+	} {
+		if l := configSource(key); l == nil || l.Kind != want {
+			t.Errorf("source of %s = %+v, want %s", key, l, want)
+		}
+	}
+
+	// Writes go to the user file only.
+	setConfig("envs.local.retries", 2)
+	if err := saveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "user.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); strings.Contains(s, "team-staging") || strings.Contains(s, "s3cret") || !strings.Contains(s, "retries: 2") {
+		t.Errorf("user config after save:\n%s", s)
+	}
+}
+
+func TestTrustedProject(t *testing.T) {
+	t.Setenv("A2ACLI_NO_PROJECT_CONFIG", "")
+	t.Setenv("TEAM_TOKEN", "s3cret")
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	repo := filepath.Join(root, "repo")
+	writeFiles(t, root, map[string]string{
+		"user.yaml": "trusted_projects:\n  - " + repo + "\nenvs:\n  ci:\n    service_url: http://user-ci\n",
+		"repo/.a2acli.yaml": `envs:
+  ci:
+    service_url: http://ci
+    token: ${TEAM_TOKEN}
+    token_command: ./get-token.sh
+    headers_from_env:
+      X-Build: BUILD_ID
+    proxy:
+      url: http://proxy.example.com
+    tls:
+      insecure_skip_verify: true
+`,
+	})
+	if err := loadLayersIn(t, filepath.Join(root, "user.yaml"), repo); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"envs.ci.service_url":              "http://ci",
+		"envs.ci.token":                    "s3cret",
+		"envs.ci.token_command":            "./get-token.sh",
+		"envs.ci.headers_from_env.x-build": "BUILD_ID",
+		"envs.ci.proxy.url":                "http://proxy.example.com",
+		"envs.ci.tls.insecure_skip_verify": "true",
+	} {
+		if got := viper.GetString(key); got != want {
+			t.Errorf("trusted %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestUntrustedProject(t *testing.T) {
+	t.Setenv("A2ACLI_NO_PROJECT_CONFIG", "")
+	t.Setenv("TEAM_TOKEN", "s3cret")
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	writeFiles(t, root, map[string]string{
+		"user.yaml": "envs:\n  prod:\n    service_url: https://prod.example.com\n    token: user-token\n",
+		"repo/.a2acli.yaml": `include: [team.yaml]
+envs:
+  prod:
+    service_url: https://attacker.example.com
+  ci:
+    service_url: http://ci
+    token: ${TEAM_TOKEN}
+    token_command: ./get-token.sh
+    headers_from_env:
+      X-Secret: AWS_SECRET_ACCESS_KEY
+    proxy:
+      url: http://proxy.example.com
+    tls:
+      insecure_skip_verify: true
+      cacert: ca.pem
+`,
+		"repo/team.yaml": `envs:
+  ci:
+    credential_hosts: [attacker.example.com]
+    card_signature:
+      required: false
+  Prod:
+    token: stolen
+`,
+	})
+	if err := loadLayersIn(t, filepath.Join(root, "user.yaml"), filepath.Join(root, "repo")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A project cannot change an environment the user config defines.
+	for key, want := range map[string]string{
+		"envs.prod.service_url": "https://prod.example.com",
+		"envs.prod.token":       "user-token",
+	} {
+		if got := viper.GetString(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	// Its own environments are kept, without the settings that need trust.
+	for key, want := range map[string]string{
+		"envs.ci.service_url": "http://ci",
+		"envs.ci.tls.cacert":  filepath.Join(root, "repo", "ca.pem"),
+		"envs.ci.token":       "${TEAM_TOKEN}",
+	} {
+		if got := viper.GetString(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	for _, key := range []string{
+		"envs.ci.token_command",
+		"envs.ci.headers_from_env",
+		"envs.ci.proxy",
+		"envs.ci.tls.insecure_skip_verify",
+		"envs.ci.credential_hosts",
+		"envs.ci.card_signature",
+	} {
+		if viper.IsSet(key) {
+			t.Errorf("untrusted %s = %v", key, viper.Get(key))
+		}
+		if l := configSource(key); l != nil {
+			t.Errorf("untrusted %s has a source: %+v", key, l)
+		}
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	t.Setenv("A2ACLI_NO_PROJECT_CONFIG", "")
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	writeFiles(t, root, map[string]string{
+		"user.yaml":         "envs: {}\n",
+		"repo/.a2acli.yaml": "include: [a.yaml]\n",
+		"repo/a.yaml":       "include: [b.yaml]\n",
+		"repo/b.yaml":       "include: [a.yaml]\n",
+	})
+	err := loadLayersIn(t, filepath.Join(root, "user.yaml"), filepath.Join(root, "repo"))
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("include cycle: %v", err)
+	}
+
+	writeFiles(t, root, map[string]string{"repo/.a2acli.yaml": "include: [missing.yaml]\n"})
+	if err := loadLayersIn(t, filepath.Join(root, "user.yaml"), filepath.Join(root, "repo")); err == nil {
+		t.Error("a missing include was ignored")
+	}
+}
@@ -0,0 +1,423 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/a2aproject/a2a-go/v2/a2aclient"
+	a2agrpc "github.com/a2aproject/a2a-go/v2/a2agrpc/v1"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	// tlsCertFile and tlsKeyFile are the PEM client certificate and key
+	// presented for mutual TLS (--cert, --key, envs.<name>.tls.cert/key).
+	// When only the certificate is given, the key is read from the same file.
+	tlsCertFile string
+	tlsKeyFile  string
+	// tlsCACertFile is a PEM bundle of additional trusted roots (--cacert,
+	// envs.<name>.tls.cacert), added to the system pool.
+	tlsCACertFile string
+	// tlsInsecure disables server certificate verification
+	// (--insecure-skip-verify, envs.<name>.tls.insecure_skip_verify).
+	tlsInsecure bool
+
+	insecureWarning sync.Once
+
+	// proxyURL routes every HTTP and gRPC connection through an explicit
+	// proxy (--proxy-url, envs.<name>.proxy.url), overriding HTTP_PROXY and
+	// HTTPS_PROXY. Credentials may be given as userinfo. http, https and
+	// socks5 proxies are supported.
+	proxyURL string
+	// noProxy lists hosts that bypass the proxy (envs.<name>.proxy.no_proxy),
+	// in NO_PROXY syntax. When empty, the NO_PROXY environment variable
+	// applies.
+	noProxy []string
+
+	// unixSocketPath is the socket of a unix:///path service URL, and
+	// unixSocketHost the placeholder host that stands in for it in
+	// serviceURL (see useUnixSocket).
+	unixSocketPath string
+	unixSocketHost string
+)
+
+// tlsConfigured reports whether any TLS option differs from the defaults.
+func tlsConfigured() bool {
+	return tlsCertFile != "" || tlsKeyFile != "" || tlsCACertFile != "" || tlsInsecure
+}
+
+// clientTLSConfig builds the TLS configuration shared by the card resolver,
+// every transport and artifact downloads. It returns nil when no TLS option
+// is set so Go's defaults apply unchanged.
+func clientTLSConfig() (*tls.Config, error) {
+	if !tlsConfigured() {
+		return nil, nil
+	}
+	return buildTLSConfig(tlsCertFile, tlsKeyFile, tlsCACertFile, tlsInsecure)
+}
+
+// buildTLSConfig builds a TLS configuration from a client certificate and
+// key, a CA bundle added to the system roots, and the insecure flag.
+func buildTLSConfig(certFile, keyFile, caCertFile string, insecure bool) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA certificates: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s contains no PEM certificates", caCertFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" {
+			return nil, fmt.Errorf("--key requires --cert")
+		}
+		if keyFile == "" {
+			keyFile = certFile
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if insecure {
+		insecureWarning.Do(func() {
+			fmt.Fprintf(os.Stderr, "%s TLS certificate verification is DISABLED (--insecure-skip-verify). "+
+				"Connections can be intercepted; never use this against production agents.\n",
+				StyleWarn.Render("WARNING:"))
+		})
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg, nil
+}
+
+// mustClientTLSConfig is clientTLSConfig for command paths, where an unusable
+// certificate or CA bundle is a usage error.
+func mustClientTLSConfig() *tls.Config {
+	cfg, err := clientTLSConfig()
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid TLS configuration", err,
+			"Check the --cert, --key and --cacert files (or envs.<name>.tls in config.yaml)")
+	}
+	return cfg
+}
+
+// newHTTPTransport returns a clone of http.DefaultTransport carrying the
+// configured TLS and proxy settings. With a unix:// service URL every
+// connection goes to the socket, whatever host the card advertises.
+func newHTTPTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = mustClientTLSConfig()
+	t.Proxy = httpProxy(mustProxyFunc())
+	if unixSocketPath != "" {
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", unixSocketPath)
+		}
+	}
+	return t
+}
+
+// newHTTPClient returns a client for talking to the agent. A zero timeout
+// means no overall deadline.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: withRetries(newHTTPTransport())}
+}
+
+// grpcDialOptions returns the dial options for the gRPC transport. The card
+// advertises gRPC endpoints as host:port without a scheme, so TLS is used
+// when a TLS option is set or the agent itself was reached over https, and
+// plaintext otherwise (e.g. a local `a2acli serve`).
+//
+// gRPC's built-in proxy support only reads the environment, so it is
+// replaced by grpcDialer, which applies the same proxy, NO_PROXY and
+// unix socket rules as the HTTP transports.
+func grpcDialOptions() []grpc.DialOption {
+	cfg := mustClientTLSConfig()
+	if cfg == nil && isHTTPS(serviceURL) {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	opts := []grpc.DialOption{grpc.WithNoProxy(), grpc.WithContextDialer(grpcDialer(mustProxyFunc()))}
+	if cfg == nil {
+		verboseLog("gRPC: using plaintext connection")
+		return append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	verboseLog("gRPC: using TLS")
+	return append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+}
+
+// grpcTransportOption registers the gRPC transport with grpcDialOptions,
+// dialing the card's endpoint through grpcTarget.
+func grpcTransportOption() a2aclient.FactoryOption {
+	opts := grpcDialOptions()
+	return a2aclient.WithTransport(a2a.TransportProtocolGRPC,
+		a2aclient.TransportFactoryFn(func(_ context.Context, _ *a2a.AgentCard, iface *a2a.AgentInterface) (a2aclient.Transport, error) {
+			conn, err := grpc.NewClient(grpcTarget(iface.URL), opts...)
+			if err != nil {
+				return nil, err
+			}
+			return a2agrpc.NewGRPCTransport(conn), nil
+		}))
+}
+
+// grpcTarget turns an advertised gRPC endpoint into a grpc.NewClient
+// target. Bare host:port endpoints use the passthrough resolver so
+// grpcDialer sees the host name rather than a resolved IP, which NO_PROXY
+// matching and proxies that resolve names themselves both depend on.
+func grpcTarget(endpoint string) string {
+	if unixSocketPath != "" {
+		return "passthrough:///" + net.JoinHostPort(unixSocketHost, "0")
+	}
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	return "passthrough:///" + endpoint
+}
+
+// grpcDialer connects to addr directly, through the unix socket, or through
+// an HTTP CONNECT or SOCKS5 tunnel, as the proxy settings dictate.
+func grpcDialer(proxyFor func(*url.URL) (*url.URL, error)) func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		d := &net.Dialer{Timeout: 30 * time.Second}
+		if unixSocketPath != "" {
+			return d.DialContext(ctx, "unix", unixSocketPath)
+		}
+		p, err := proxyFor(&url.URL{Scheme: "https", Host: addr})
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return d.DialContext(ctx, "tcp", addr)
+		}
+		verboseLog("gRPC: connecting to %s via proxy %s", addr, p.Redacted())
+		switch p.Scheme {
+		case "socks5", "socks5h":
+			socks, err := proxy.FromURL(p, d)
+			if err != nil {
+				return nil, err
+			}
+			return socks.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+		default:
+			return dialConnect(ctx, d, p, addr)
+		}
+	}
+}
+
+// dialConnect opens an HTTP CONNECT tunnel to addr through proxyURL.
+func dialConnect(ctx context.Context, d *net.Dialer, p *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := p.Host
+	if p.Port() == "" {
+		port := "80"
+		if p.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(p.Hostname(), port)
+	}
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to proxy %s: %w", p.Redacted(), err)
+	}
+	if p.Scheme == "https" {
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+		if c := mustClientTLSConfig(); c != nil {
+			cfg = c.Clone()
+		}
+		cfg.ServerName = p.Hostname()
+		conn = tls.Client(conn, cfg)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if p.User != nil {
+		pass, _ := p.User.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(p.User.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", p.Redacted(), err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", p.Redacted(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", p.Redacted(), addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn returns bytes the proxy sent after its CONNECT response
+// before reading from the connection again.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) { return c.r.Read(b) }
+
+// proxyFunc returns the proxy selection shared by every transport:
+// --proxy-url / envs.<name>.proxy.url when set, else HTTP_PROXY and
+// HTTPS_PROXY, minus the hosts matched by envs.<name>.proxy.no_proxy or
+// NO_PROXY. Loopback hosts and the unix socket placeholder never use a
+// proxy.
+func proxyFunc() (func(*url.URL) (*url.URL, error), error) {
+	return buildProxyFunc(proxyURL, noProxy, unixSocketHost)
+}
+
+// buildProxyFunc is proxyFunc for an explicit proxy URL, no_proxy list and
+// unix socket placeholder host.
+func buildProxyFunc(proxy string, bypass []string, unixHost string) (func(*url.URL) (*url.URL, error), error) {
+	cfg := httpproxy.FromEnvironment()
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", u.Scheme)
+		}
+		cfg.HTTPProxy, cfg.HTTPSProxy = proxy, proxy
+	}
+	if len(bypass) > 0 {
+		cfg.NoProxy = strings.Join(bypass, ",")
+	}
+	f := cfg.ProxyFunc()
+	return func(u *url.URL) (*url.URL, error) {
+		if unixHost != "" && u.Hostname() == unixHost {
+			return nil, nil
+		}
+		return f(u)
+	}, nil
+}
+
+// mustProxyFunc is proxyFunc for command paths, where a malformed proxy URL
+// is a usage error.
+func mustProxyFunc() func(*url.URL) (*url.URL, error) {
+	f, err := proxyFunc()
+	if err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid proxy configuration", err,
+			"Use --proxy-url http://[user:pass@]host:port (or envs.<name>.proxy.url in config.yaml)")
+	}
+	return f
+}
+
+// httpProxy adapts a proxy selection function to http.Transport.Proxy.
+func httpProxy(f func(*url.URL) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(r *http.Request) (*url.URL, error) { return f(r.URL) }
+}
+
+// dialUnixAware dials the unix socket for the placeholder host of a
+// unix:// service URL and addr otherwise. Download clients use it so that
+// artifact URLs on the agent reach the socket while other hosts do not.
+func dialUnixAware(d *net.Dialer) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(addr); err == nil && unixSocketHost != "" && host == unixSocketHost {
+			return d.DialContext(ctx, "unix", unixSocketPath)
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// useUnixSocket rewrites a unix:///path/to/agent.sock service URL into an
+// http:// URL on a placeholder host derived from the socket path, which
+// newHTTPTransport, grpcDialer and dialUnixAware route to the socket. The
+// placeholder keeps card caching and token storage, which key on the
+// service URL, distinct per socket.
+func useUnixSocket() error {
+	path, host, err := unixSocketTarget(serviceURL)
+	if err != nil || path == "" {
+		return err
+	}
+	unixSocketPath = path
+	unixSocketHost = host
+	verboseLog("service %s: using unix socket %s as http://%s", serviceURL, path, unixSocketHost)
+	serviceURL = "http://" + unixSocketHost
+	return nil
+}
+
+// unixSocketTarget returns the socket path and placeholder host of a
+// unix:// service URL, or empty strings for any other URL.
+func unixSocketTarget(rawURL string) (path, host string, err error) {
+	path, ok := strings.CutPrefix(rawURL, "unix://")
+	if !ok {
+		return "", "", nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return "", "", fmt.Errorf("unix socket URL %q must use an absolute path (unix:///path/to/agent.sock)", rawURL)
+	}
+	sum := sha256.Sum256([]byte(path))
+	return path, "unix-" + hex.EncodeToString(sum[:4]) + ".sock", nil
+}
+
+// mustUseUnixSocket is useUnixSocket for command paths.
+func mustUseUnixSocket() {
+	if err := useUnixSocket(); err != nil {
+		fatalCode(ErrCodeInvalidArgument, "invalid service URL", err, "")
+	}
+}
+
+func isHTTPS(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && strings.EqualFold(u.Scheme, "https")
+}
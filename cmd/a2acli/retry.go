@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// requestRetries is how many times a failed HTTP request to the agent is
+	// retried (--retries or envs.<name>.retries).
+	requestRetries int
+
+	// retryBaseDelay is the first backoff delay; it doubles per attempt.
+	retryBaseDelay = 500 * time.Millisecond
+)
+
+// maxRetryDelay caps the backoff and any Retry-After the agent sends.
+const maxRetryDelay = 30 * time.Second
+
+// retryTransport retries requests that the agent cannot have acted on: ones
+// that never reached it (connection refused, DNS failure) and ones it
+// refused with 429 or 503. Idempotent GET and HEAD requests, such as
+// AgentCard fetches, are also retried on 502, 504 and other network errors.
+// A2A calls like SendMessage are POSTs and may not be idempotent, so they
+// are never retried once the agent could have received them.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+}
+
+// withRetries wraps base in a retryTransport when retries are enabled.
+func withRetries(base http.RoundTripper) http.RoundTripper {
+	if requestRetries <= 0 {
+		return base
+	}
+	return &retryTransport{base: base, retries: requestRetries}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.retries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		next := req
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return resp, err
+			}
+			next = req.Clone(req.Context())
+			next.Body = body
+		}
+
+		delay := retryDelay(attempt, resp)
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+		verboseLog("%s %s: %s; retrying in %s (%d/%d)", req.Method, req.URL.Redacted(), reason, delay, attempt+1, t.retries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+// shouldRetry reports whether a request may safely be sent again.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return true
+		}
+		return idempotent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// retryDelay honours a Retry-After in seconds and otherwise backs off
+// exponentially from retryBaseDelay.
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			return min(time.Duration(s)*time.Second, maxRetryDelay)
+		}
+	}
+	return min(retryBaseDelay<<attempt, maxRetryDelay)
+}
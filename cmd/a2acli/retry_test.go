@@ -0,0 +1,117 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	origRetries, origDelay := requestRetries, retryBaseDelay
+	defer func() { requestRetries, retryBaseDelay = origRetries, origDelay }()
+	requestRetries, retryBaseDelay = 2, time.Millisecond
+
+	// status is returned for the first call; later calls succeed and echo
+	// the body so a replayed body can be checked.
+	serve := func(status int) (*httptest.Server, *atomic.Int32) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = io.Copy(w, r.Body)
+		}))
+		return srv, &calls
+	}
+	post := func(url string) (int, string) {
+		t.Helper()
+		resp, err := newHTTPClient(5*time.Second).Post(url, "application/json", strings.NewReader(`{"id":1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	srv, calls := serve(http.StatusServiceUnavailable)
+	defer srv.Close()
+	if code, body := post(srv.URL); code != http.StatusOK || body != `{"id":1}` || calls.Load() != 2 {
+		t.Errorf("503 then 200: status %d, body %q, %d calls", code, body, calls.Load())
+	}
+
+	// A POST that reached the agent and failed upstream may have been
+	// processed, so it is not retried.
+	srv502, calls502 := serve(http.StatusBadGateway)
+	defer srv502.Close()
+	if code, _ := post(srv502.URL); code != http.StatusBadGateway || calls502.Load() != 1 {
+		t.Errorf("POST 502: status %d, %d calls", code, calls502.Load())
+	}
+	srvGet, callsGet := serve(http.StatusBadGateway)
+	defer srvGet.Close()
+	resp, err := newHTTPClient(5 * time.Second).Get(srvGet.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || callsGet.Load() != 2 {
+		t.Errorf("GET 502: status %d, %d calls", resp.StatusCode, callsGet.Load())
+	}
+
+	// Connection failures are retried, then reported.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	var dials atomic.Int32
+	rt := &retryTransport{retries: 2, base: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		dials.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	if _, err := (&http.Client{Transport: rt}).Post("http://"+addr, "text/plain", strings.NewReader("x")); err == nil {
+		t.Error("expected a connection error")
+	}
+	if n := dials.Load(); n != 3 {
+		t.Errorf("connection refused: %d attempts, want 3", n)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRetryDelay(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": {"2"}}}
+	if d := retryDelay(0, resp); d != 2*time.Second {
+		t.Errorf("Retry-After: %s", d)
+	}
+	resp.Header.Set("Retry-After", "3600")
+	if d := retryDelay(0, resp); d != maxRetryDelay {
+		t.Errorf("Retry-After is not capped: %s", d)
+	}
+	if d := retryDelay(3, nil); d != 8*retryBaseDelay {
+		t.Errorf("backoff: %s", d)
+	}
+}
@@ -29,6 +29,8 @@ import (
 	"github.com/a2aproject/a2a-go/v2/a2a"
 	a2agrpc "github.com/a2aproject/a2a-go/v2/a2agrpc/v1"
 	"github.com/a2aproject/a2a-go/v2/a2asrv"
+
+	"github.com/ghchinoy/a2acli/internal/httpsig"
 )
 
 var (
@@ -37,6 +39,9 @@ var (
 	serveEcho  bool
 	serveProxy string
 	serveExec  string
+
+	// serveVerify, when its Key is set, requires signed requests.
+	serveVerify signingConfig
 )
 
 func setupServeCmd() *cobra.Command {
@@ -45,10 +50,15 @@ func setupServeCmd() *cobra.Command {
 		GroupID: GroupServer,
 		Short:   "Start an A2A-compliant mock server",
 		Long: `Spin up a local A2A-compliant agent for testing purposes.
-Currently, this CLI supports the --echo mock mode.`,
-		Example: `  a2acli serve --echo --port 9001`,
-		Args:    cobra.NoArgs,
-		Run:     runServe,
+Currently, this CLI supports the --echo mock mode.
+
+With --verify-key, A2A requests must carry a valid request signature (RFC 9421
+HTTP Message Signatures or the X-A2A-Signature HMAC scheme) made with that key;
+others are rejected with 401. The agent card stays public.`,
+		Example: `  a2acli serve --echo --port 9001
+  a2acli serve --echo --verify-key-id partner-1 --verify-key partner-ed25519.pub.pem`,
+		Args: cobra.NoArgs,
+		Run:  runServe,
 	}
 
 	cmd.Flags().IntVar(&servePort, "port", 9001, "Listen port")
@@ -56,6 +66,9 @@ Currently, this CLI supports the --echo mock mode.`,
 	cmd.Flags().BoolVar(&serveEcho, "echo", false, "Echo mode: return the user's message as a response")
 	cmd.Flags().StringVar(&serveProxy, "proxy", "", "Proxy mode (not yet supported)")
 	cmd.Flags().StringVar(&serveExec, "exec", "", "Exec mode (not yet supported)")
+	cmd.Flags().StringVar(&serveVerify.Key, "verify-key", "", "Require requests signed with this PEM public key or HMAC secret file (or helper:<name>)")
+	cmd.Flags().StringVar(&serveVerify.KeyID, "verify-key-id", "", "Key ID that signatures made with --verify-key must use")
+	cmd.Flags().StringVar(&serveVerify.Alg, "verify-alg", "", "Signature algorithm of --verify-key (default: inferred from the key)")
 
 	return cmd
 }
@@ -78,10 +91,18 @@ func runServe(_ *cobra.Command, _ []string) {
 	}
 
 	card := &a2a.AgentCard{
-		Name:         "a2acli-mock-agent",
-		Description:  "A simple echo agent spun up via a2acli",
-		Version:      "1.0.0",
-		Capabilities: a2a.AgentCapabilities{Streaming: true},
+		Name:               "a2acli-mock-agent",
+		Description:        "A simple echo agent spun up via a2acli",
+		Version:            "1.0.0",
+		Capabilities:       a2a.AgentCapabilities{Streaming: true},
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+		Skills: []a2a.AgentSkill{{
+			ID:          "echo",
+			Name:        "Echo",
+			Description: "Returns each part of the message as an artifact",
+			Tags:        []string{"test"},
+		}},
 	}
 
 	// Determine transport
@@ -101,6 +122,18 @@ func runServe(_ *cobra.Command, _ []string) {
 
 	handler := a2asrv.NewHandler(&echoExecutor{})
 
+	var verifier *httpsig.Verifier
+	if serveVerify.enabled() {
+		if selectedTransport == a2a.TransportProtocolGRPC {
+			fatalCode(ErrCodeInvalidArgument, "--verify-key is not supported with the grpc transport", nil, "Request signatures cover HTTP requests; use --transport jsonrpc or rest")
+		}
+		key, err := serveVerify.loadKey()
+		if err != nil {
+			fatalCode(ErrCodeInvalidArgument, "invalid --verify-key", err, "Pass --verify-key-id and a PEM public key or HMAC secret file")
+		}
+		verifier = &httpsig.Verifier{Keys: map[string]*httpsig.Key{key.ID: key}}
+	}
+
 	if !disableTUI {
 		fmt.Printf("Starting Mock Agent (%s) on %s\n", selectedTransport, addr)
 	}
@@ -134,11 +167,19 @@ func runServe(_ *cobra.Command, _ []string) {
 		mux := http.NewServeMux()
 		mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(card))
 
+		var api http.Handler
 		if selectedTransport == a2a.TransportProtocolJSONRPC {
-			mux.Handle("/", a2asrv.NewJSONRPCHandler(handler))
+			api = a2asrv.NewJSONRPCHandler(handler)
 		} else {
-			mux.Handle("/", a2asrv.NewRESTHandler(handler))
+			api = a2asrv.NewRESTHandler(handler)
+		}
+		if verifier != nil {
+			api = verifier.Middleware(api)
+			if !disableTUI {
+				fmt.Printf("Requiring request signatures from key %s (%s)\n", serveVerify.KeyID, verifier.Keys[serveVerify.KeyID].Alg)
+			}
 		}
+		mux.Handle("/", api)
 
 		srv := &http.Server{Handler: mux}
 
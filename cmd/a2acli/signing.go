@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ghchinoy/a2acli/internal/httpsig"
+	"github.com/ghchinoy/a2acli/internal/oauth"
+)
+
+// signingConfig is the request signing setup of an environment
+// (envs.<name>.signing) or of serve's verification flags.
+type signingConfig struct {
+	// Scheme is rfc9421 (default) or hmac.
+	Scheme string
+	KeyID  string
+	// Alg is an RFC 9421 algorithm name; empty infers it from the key.
+	Alg string
+	// Key is a path to a PEM key or HMAC secret file, or helper:<name> to
+	// fetch it from a credential helper under the key ID.
+	Key string
+}
+
+// requestSigning is the active environment's signing setup. Requests are
+// signed when Key is set.
+var requestSigning signingConfig
+
+func (c signingConfig) enabled() bool { return c.Key != "" }
+
+// loadKey reads the key material and parses it.
+func (c signingConfig) loadKey() (*httpsig.Key, error) {
+	if c.KeyID == "" {
+		return nil, fmt.Errorf("signing key %s has no key ID", c.Key)
+	}
+	var material []byte
+	if name, ok := strings.CutPrefix(c.Key, "helper:"); ok {
+		b, err := oauth.HelperStore{Program: name}.Get(c.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, fmt.Errorf("credential helper %s has no key %q", name, c.KeyID)
+		}
+		material = b
+	} else {
+		b, err := os.ReadFile(c.Key)
+		if err != nil {
+			return nil, err
+		}
+		material = b
+	}
+	return httpsig.ParseKey(c.KeyID, c.Alg, material)
+}
+
+// signer returns the request signer for c.
+func (c signingConfig) signer() (httpsig.Signer, error) {
+	key, err := c.loadKey()
+	if err != nil {
+		return nil, err
+	}
+	return httpsig.NewSigner(c.Scheme, key)
+}
+
+// withRequestSigning wraps client's transport so every request to the agent
+// carries a signature. a2aclient interceptors only see service parameters,
+// not the HTTP request, so the signer sits below them at the HTTP layer.
+func withRequestSigning(client *http.Client) (*http.Client, error) {
+	if !requestSigning.enabled() {
+		return client, nil
+	}
+	signer, err := requestSigning.signer()
+	if err != nil {
+		return nil, fmt.Errorf("request signing: %w", err)
+	}
+	verboseLog("signing requests with key %s (%s)", requestSigning.KeyID, schemeName(requestSigning.Scheme))
+	signed := *client
+	signed.Transport = &httpsig.Transport{Base: client.Transport, Signer: signer}
+	return &signed, nil
+}
+
+func schemeName(scheme string) string {
+	if scheme == "" {
+		return httpsig.SchemeRFC9421
+	}
+	return scheme
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ghchinoy/a2acli/internal/httpsig"
+)
+
+func TestWithRequestSigning(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	dir := t.TempDir()
+	privDER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+	privFile := filepath.Join(dir, "client.pem")
+	pubFile := filepath.Join(dir, "client.pub.pem")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyKey, err := signingConfig{KeyID: "partner-1", Key: pubFile}.loadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &httpsig.Verifier{Keys: map[string]*httpsig.Key{"partner-1": verifyKey}}
+	srv := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	defer srv.Close()
+
+	orig := requestSigning
+	defer func() { requestSigning = orig }()
+
+	post := func() int {
+		t.Helper()
+		client, err := withRequestSigning(newHTTPClient(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	requestSigning = signingConfig{}
+	if code := post(); code != http.StatusUnauthorized {
+		t.Errorf("unsigned: status %d, want 401", code)
+	}
+	requestSigning = signingConfig{KeyID: "partner-1", Key: privFile}
+	if code := post(); code != http.StatusOK {
+		t.Errorf("signed: status %d, want 200", code)
+	}
+	requestSigning = signingConfig{KeyID: "partner-1", Key: privFile, Scheme: httpsig.SchemeHMAC}
+	if _, err := withRequestSigning(newHTTPClient(0)); err == nil {
+		t.Error("expected an error for the hmac scheme with an Ed25519 key")
+	}
+}
+
+func TestSigningKeyFromHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake credential helper is a shell script")
+	}
+	helper := filepath.Join(t.TempDir(), "a2acli-credential-fake")
+	script := `#!/bin/sh
+read key
+if [ "$1" = get ] && [ "$key" = k1 ]; then
+  echo '{"ServerURL":"k1","Username":"a2acli","Secret":"s3cret"}'
+  exit 0
+fi
+echo "credentials not found"; exit 1
+`
+	if err := os.WriteFile(helper, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := signingConfig{KeyID: "k1", Key: "helper:" + helper}.loadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Alg != httpsig.AlgHMACSHA256 || string(key.Secret) != "s3cret" {
+		t.Errorf("key = %s %q", key.Alg, key.Secret)
+	}
+	if _, err := (signingConfig{KeyID: "k2", Key: "helper:" + helper}).loadKey(); err == nil || !strings.Contains(err.Error(), "k2") {
+		t.Errorf("expected a missing-key error naming k2, got %v", err)
+	}
+}
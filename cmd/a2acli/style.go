@@ -15,6 +15,10 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -71,3 +75,27 @@ var (
 		Dark:  "#ffffff",
 	})
 )
+
+// printTable writes rows as columns two spaces apart. Unlike tabwriter, it
+// measures cells without their escape codes, so styled cells line up.
+func printTable(w io.Writer, rows [][]string) {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(cell)+2))
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
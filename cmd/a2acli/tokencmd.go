@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghchinoy/a2acli/internal/oauth"
+)
+
+// tokenCommandTimeout bounds a single run of token_command.
+const tokenCommandTimeout = time.Minute
+
+var (
+	// commandTokens supplies the bearer token from envs.<name>.token_command,
+	// e.g. "gcloud auth print-identity-token". It is nil unless the active
+	// environment configures one and no --token or static token is set.
+	commandTokens *commandTokenSource
+
+	// headersFromEnv maps header names to the environment variables holding
+	// their values (envs.<name>.headers_from_env), so API keys stay out of
+	// config.yaml and shell history.
+	headersFromEnv map[string]string
+)
+
+// commandTokenSource runs a token command lazily, on the first request that
+// needs a token, and reuses its output until the JWT exp claim. Tokens with
+// an exp are also cached in the token store so later invocations skip the
+// command; opaque tokens are reused for the current invocation only.
+type commandTokenSource struct {
+	command    string
+	serviceURL string
+
+	mu  sync.Mutex
+	tok *oauth.StoredToken
+}
+
+func newCommandTokenSource(command, serviceURL string) *commandTokenSource {
+	return &commandTokenSource{command: command, serviceURL: serviceURL}
+}
+
+// Token returns a cached token or runs the command to obtain one.
+func (s *commandTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil && !s.tok.IsExpired() {
+		return s.tok.AccessToken, nil
+	}
+	if tok, err := oauth.LoadCommandToken(s.serviceURL, s.command); err == nil && tok != nil {
+		verboseLog("token_command: using cached token (expires %s)", tok.ExpiresAt.Format("15:04:05"))
+		s.tok = tok
+		return tok.AccessToken, nil
+	}
+	return s.run(ctx)
+}
+
+// Refresh discards the cached token and runs the command again. It is
+// called when the agent rejects the current token with a 401.
+func (s *commandTokenSource) Refresh(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tok = nil
+	if err := oauth.DeleteCommandToken(s.serviceURL, s.command); err != nil {
+		verboseLog("token_command: failed to drop cached token: %v", err)
+	}
+	return s.run(ctx)
+}
+
+func (s *commandTokenSource) run(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+	defer cancel()
+
+	verboseLog("token_command: running %q", s.command)
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, s.command)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("token_command %q: %w: %s", s.command, err, msg)
+		}
+		return "", fmt.Errorf("token_command %q: %w", s.command, err)
+	}
+	token := strings.TrimSpace(string(out))
+	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+	if token == "" || strings.ContainsAny(token, " \n") {
+		return "", fmt.Errorf("token_command %q must print exactly one token on stdout", s.command)
+	}
+
+	tok := &oauth.StoredToken{AccessToken: token, ExpiresAt: jwtExpiry(token)}
+	s.tok = tok
+	if tok.ExpiresAt.IsZero() {
+		verboseLog("token_command: token has no exp claim; not caching it across runs")
+		return token, nil
+	}
+	verboseLog("token_command: token expires %s", tok.ExpiresAt.Format(time.RFC3339))
+	if err := oauth.SaveCommandToken(s.serviceURL, s.command, tok); err != nil {
+		verboseLog("token_command: failed to cache token: %v", err)
+	}
+	return token, nil
+}
+
+// jwtExpiry returns the exp claim of a JWT without verifying it, or the
+// zero time when token is not a JWT or has no exp. The value only decides
+// how long a2acli reuses a token; the agent still validates it.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(claims.Exp), 0)
+}
+
+// bearerToken returns the token to send when no OAuth token applies:
+// --token or the environment's static token, else token_command output.
+func bearerToken(ctx context.Context) (string, error) {
+	if authToken != "" || commandTokens == nil {
+		return authToken, nil
+	}
+	return commandTokens.Token(ctx)
+}
+
+// envHeaders returns the environment's extra headers: the static headers
+// setting overlaid with headers_from_env. A variable that is unset or empty
+// is an error rather than a silently missing credential.
+func envHeaders() (map[string]string, error) {
+	if len(configHeaders) == 0 && len(headersFromEnv) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(configHeaders)+len(headersFromEnv))
+	for name, v := range configHeaders {
+		out[name] = v
+	}
+	for name, variable := range headersFromEnv {
+		v := os.Getenv(variable)
+		if v == "" {
+			return nil, fmt.Errorf("header %s: environment variable %s (headers_from_env) is not set", name, variable)
+		}
+		out[name] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2aclient"
+	"github.com/ghchinoy/a2acli/internal/oauth"
+)
+
+func testJWT(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." +
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"sa","exp":%d}`, exp.Unix()))) + ".sig"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	if got := jwtExpiry(testJWT(exp)); !got.Equal(exp) {
+		t.Errorf("jwtExpiry = %v, want %v", got, exp)
+	}
+	for _, opaque := range []string{"ya29.opaque", "a.b.c", ""} {
+		if got := jwtExpiry(opaque); !got.IsZero() {
+			t.Errorf("jwtExpiry(%q) = %v, want zero", opaque, got)
+		}
+	}
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("token command script uses sh")
+	}
+	oauth.SetTokenStore(oauth.FileStore{Dir: t.TempDir()})
+	defer oauth.SetTokenStore(nil)
+
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "runs")
+	token := testJWT(time.Now().Add(time.Hour))
+	command := fmt.Sprintf("echo run >> %s; printf '%%s\\n' %s", counter, token)
+	runs := func() int {
+		b, _ := os.ReadFile(counter)
+		return strings.Count(string(b), "run")
+	}
+
+	ctx := context.Background()
+	const svc = "https://agent.example.com"
+	src := newCommandTokenSource(command, svc)
+	for range 2 {
+		got, err := src.Token(ctx)
+		if err != nil {
+			t.Fatalf("Token: %v", err)
+		}
+		if got != token {
+			t.Fatalf("Token = %q, want %q", got, token)
+		}
+	}
+	if n := runs(); n != 1 {
+		t.Fatalf("command ran %d times, want 1", n)
+	}
+
+	// A later invocation reuses the cached token until exp.
+	if _, err := newCommandTokenSource(command, svc).Token(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("cached token not reused across sources: %d runs", n)
+	}
+	// Cached command output is never picked up as an OAuth token.
+	if tok, _ := oauth.LoadToken(svc); tok != nil {
+		t.Errorf("LoadToken returned command token %+v", tok)
+	}
+
+	// A 401 seen by the interceptor re-runs the command.
+	pi := &paramInterceptor{tokens: src}
+	if err := pi.After(ctx, &a2aclient.Response{Err: errors.New("HTTP 401 Unauthorized")}); err != nil {
+		t.Fatal(err)
+	}
+	if n := runs(); n != 2 {
+		t.Errorf("after 401: %d runs, want 2", n)
+	}
+	req := &a2aclient.Request{}
+	if _, _, err := pi.Before(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.ServiceParams.Get("authorization"); len(got) != 1 || got[0] != "Bearer "+token {
+		t.Errorf("authorization = %v", got)
+	}
+
+	failing := newCommandTokenSource("echo denied >&2; exit 3", svc)
+	if _, err := failing.Token(ctx); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected the command's stderr in the error, got %v", err)
+	}
+}
+
+func TestEnvHeaders(t *testing.T) {
+	orig := headersFromEnv
+	defer func() { headersFromEnv = orig }()
+
+	t.Setenv("A2ACLI_TEST_API_KEY", "k-123")
+	headersFromEnv = map[string]string{"x-api-key": "A2ACLI_TEST_API_KEY"}
+	got, err := envHeaders()
+	if err != nil || got["x-api-key"] != "k-123" {
+		t.Fatalf("envHeaders = %v, %v", got, err)
+	}
+
+	headersFromEnv["x-tenant"] = "A2ACLI_TEST_UNSET_VAR"
+	if _, err := envHeaders(); err == nil || !strings.Contains(err.Error(), "A2ACLI_TEST_UNSET_VAR") {
+		t.Errorf("expected an error naming the unset variable, got %v", err)
+	}
+}
@@ -47,12 +47,21 @@ type model struct {
 	err        error
 	outDir     string
 	width      int
+	// pendingSaves counts URL artifacts still downloading; the program
+	// waits for them before quitting when the stream ends.
+	pendingSaves int
+	streamDone   bool
 }
 
 type eventMsg streamMsg
 type errMsg error
 type doneMsg struct{}
 
+// artifactSavedMsg reports the outcome of a background artifact download.
+type artifactSavedMsg struct {
+	summary string
+}
+
 func initialModel(sub <-chan streamMsg, outDir string) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
@@ -101,9 +110,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 
 	case doneMsg:
+		m.streamDone = true
+		if m.pendingSaves > 0 {
+			m.status = "Downloading artifacts"
+			return m, nil
+		}
 		m.quitting = true
 		return m, tea.Quit
 
+	case artifactSavedMsg:
+		m.pendingSaves--
+		if msg.summary != "" {
+			m.messages = append(m.messages, StyleAccent.Render(msg.summary))
+		}
+		if m.streamDone && m.pendingSaves == 0 {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -148,7 +173,9 @@ func (m model) handleEvent(msg eventMsg) (tea.Model, tea.Cmd) {
 		m.handleStatusUpdate(v)
 
 	case *a2a.TaskArtifactUpdateEvent:
-		m.handleArtifactUpdate(v)
+		if cmd := m.handleArtifactUpdate(v); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	}
 
 	return m, tea.Batch(cmds...)
@@ -178,17 +205,26 @@ func (m *model) handleStatusUpdate(v *a2a.TaskStatusUpdateEvent) {
 	}
 }
 
-func (m *model) handleArtifactUpdate(v *a2a.TaskArtifactUpdateEvent) {
+// handleArtifactUpdate records an artifact in the history and saves it when
+// --out-dir or --file is set. Artifacts with URL parts are downloaded by the
+// returned command so the progress bar keeps rendering meanwhile.
+func (m *model) handleArtifactUpdate(v *a2a.TaskArtifactUpdateEvent) tea.Cmd {
 	m.status = "Artifact Received"
 	m.messages = append(m.messages, StyleArtifact.Render(fmt.Sprintf("ARTIFACT: %s", v.Artifact.Name)))
 
+	var cmd tea.Cmd
 	saveMsg := ""
 	if m.outDir != "" || outFile != "" {
-		path, err := saveArtifact(m.outDir, outFile, *v.Artifact, 0)
-		if err != nil {
-			saveMsg = fmt.Sprintf("Error saving: %v", err)
+		art, dir := *v.Artifact, m.outDir
+		if hasURLParts(v.Artifact) {
+			m.pendingSaves++
+			cmd = func() tea.Msg {
+				saved, err := saveArtifact(dir, outFile, art, 0)
+				return artifactSavedMsg{summary: savedSummary(saved, err)}
+			}
 		} else {
-			saveMsg = fmt.Sprintf("Saved to: %s", path)
+			saved, err := saveArtifact(dir, outFile, art, 0)
+			saveMsg = savedSummary(saved, err)
 		}
 	}
 
@@ -212,6 +248,30 @@ func (m *model) handleArtifactUpdate(v *a2a.TaskArtifactUpdateEvent) {
 	if saveMsg != "" {
 		m.messages = append(m.messages, StyleAccent.Render(saveMsg))
 	}
+	return cmd
+}
+
+// savedSummary renders the outcome of saveArtifact as history lines.
+func savedSummary(saved []savedPart, err error) string {
+	var lines []string
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("Error saving: %v", err))
+	}
+	for _, sp := range saved {
+		switch {
+		case sp.Skipped:
+			lines = append(lines, fmt.Sprintf("Skipped (already exists): %s", sp.Path))
+		case sp.IsURL && sp.Err != nil:
+			lines = append(lines, fmt.Sprintf("Download failed (%v), URL: %s", sp.Err, sp.Path))
+		case sp.IsURL:
+			lines = append(lines, fmt.Sprintf("URL: %s", sp.Path))
+		case sp.Err != nil:
+			lines = append(lines, fmt.Sprintf("Error saving: %v", sp.Err))
+		default:
+			lines = append(lines, fmt.Sprintf("Saved to: %s", sp.Path))
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (m model) View() string {
@@ -248,6 +308,10 @@ func (m model) View() string {
 		width = 0
 	}
 
+	if bar := activeProgress.View(); bar != "" {
+		statusLine += "\n" + bar
+	}
+
 	return docStyle.Width(width).Render(fmt.Sprintf(
 		"%s\n\n%s\n\n%s",
 		history,
@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cardlint checks AgentCards against a set of rules for problems
+// that a schema cannot catch: plain-HTTP interfaces, security schemes that
+// are referenced but never defined, duplicate skill IDs, unreachable URLs and
+// the like. Each rule has an ID and a default severity, and findings can be
+// suppressed by rule or by rule and path.
+package cardlint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+// Severity is how serious a finding is. The values are SARIF levels.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Note    Severity = "note"
+)
+
+// Rule is a lint rule.
+type Rule struct {
+	ID       string   `json:"id"`
+	Severity Severity `json:"severity"`
+	// Summary says what the rule checks; Help how to fix a finding.
+	Summary string `json:"summary"`
+	Help    string `json:"help"`
+	// Network rules make requests and are skipped offline.
+	Network bool `json:"network,omitempty"`
+
+	check func(ctx context.Context, in *input) []Finding
+}
+
+// Finding is one problem found in a card.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Path locates the value in the card, e.g. skills[2].id; empty for the
+	// card as a whole.
+	Path string `json:"path,omitempty"`
+	// Line is the 1-based line of Path in the card document, or 0.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	// Suppressed findings are reported but do not fail the lint.
+	Suppressed bool `json:"suppressed,omitempty"`
+}
+
+// Options configures a lint run.
+type Options struct {
+	// Client makes the requests of network rules; nil uses
+	// http.DefaultClient.
+	Client *http.Client
+	// Offline skips the network rules.
+	Offline bool
+	// Protocol is the A2A version clients will speak, e.g. 1.0.0.
+	Protocol string
+	// MaxSize is the size above which a card is oversized; 0 means
+	// DefaultMaxSize.
+	MaxSize int
+	// Suppress lists rule IDs, or rule:path to suppress a rule only at a
+	// path and below it.
+	Suppress []string
+}
+
+// DefaultMaxSize is the default card size limit. Cards are fetched on every
+// discovery, so anything much larger usually carries data that belongs
+// elsewhere.
+const DefaultMaxSize = 64 << 10
+
+// input is what the rules see.
+type input struct {
+	raw    []byte
+	card   *a2a.AgentCard
+	opts   Options
+	client *http.Client
+	// legacy is set for cards in the A2A 0.3 format.
+	legacy bool
+}
+
+// Rules returns every rule, in the order they run.
+func Rules() []Rule {
+	return rules
+}
+
+// Lookup returns the rule with the given ID.
+func Lookup(id string) (Rule, bool) {
+	for _, r := range rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Lint checks card, parsed from the document raw, and returns its findings
+// ordered by line. Network rules run concurrently.
+func Lint(ctx context.Context, raw []byte, card *a2a.AgentCard, opts Options) []Finding {
+	in := &input{raw: raw, card: card, opts: opts, client: opts.Client, legacy: isLegacy(raw)}
+	if in.client == nil {
+		in.client = http.DefaultClient
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var findings []Finding
+	for _, r := range rules {
+		if r.Network && opts.Offline {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found := r.check(ctx, in)
+			for i := range found {
+				found[i].Rule, found[i].Severity = r.ID, r.Severity
+			}
+			mu.Lock()
+			findings = append(findings, found...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	lines := lineIndex(raw)
+	for i := range findings {
+		f := &findings[i]
+		f.Line = lines[f.Path]
+		f.Suppressed = suppressed(opts.Suppress, f)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return a.Line != 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+	return findings
+}
+
+// Failed reports whether any unsuppressed finding is an error, or, when
+// strict is set, a warning.
+func Failed(findings []Finding, strict bool) bool {
+	for _, f := range findings {
+		if f.Suppressed {
+			continue
+		}
+		if f.Severity == Error || (strict && f.Severity == Warning) {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressed reports whether an entry of list suppresses f: its rule ID, or
+// its rule ID and a path that is f's path or a parent of it.
+func suppressed(list []string, f *Finding) bool {
+	for _, s := range list {
+		id, path, scoped := strings.Cut(strings.TrimSpace(s), ":")
+		if id != f.Rule {
+			continue
+		}
+		if !scoped || f.Path == path || strings.HasPrefix(f.Path, path+".") || strings.HasPrefix(f.Path, path+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// isLegacy reports whether raw is an A2A 0.3 card, which has a top-level url
+// or protocolVersion instead of supportedInterfaces.
+func isLegacy(raw []byte) bool {
+	var probe struct {
+		SupportedInterfaces json.RawMessage `json:"supportedInterfaces"`
+		URL                 string          `json:"url"`
+		ProtocolVersion     string          `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return false
+	}
+	return probe.SupportedInterfaces == nil && (probe.URL != "" || probe.ProtocolVersion != "")
+}
+
+// lineIndex maps the path of every member and element of the JSON document
+// raw to the line it starts on.
+func lineIndex(raw []byte) map[string]int {
+	type frame struct {
+		array     bool
+		index     int
+		key       string
+		expectKey bool
+	}
+	path := func(stack []frame) string {
+		var b strings.Builder
+		for _, f := range stack {
+			if f.array {
+				b.WriteString("[" + strconv.Itoa(f.index) + "]")
+			} else {
+				if b.Len() > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(f.key)
+			}
+		}
+		return b.String()
+	}
+	line := func(off int64) int {
+		return bytes.Count(raw[:off], []byte("\n")) + 1
+	}
+
+	lines := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	var stack []frame
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return lines
+		}
+		// InputOffset is before any whitespace and separator; skip them.
+		for start < int64(len(raw)) && strings.IndexByte(" \t\r\n,:", raw[start]) >= 0 {
+			start++
+		}
+		if n := len(stack); n > 0 {
+			top := &stack[n-1]
+			if !top.array && top.expectKey {
+				if key, ok := tok.(string); ok {
+					top.key, top.expectKey = key, false
+					lines[path(stack)] = line(start)
+					continue
+				}
+			}
+			if top.array {
+				if d, ok := tok.(json.Delim); !ok || (d != ']' && d != '}') {
+					lines[path(stack)] = line(start)
+				}
+			}
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, frame{expectKey: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, frame{array: true})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+		// A value is complete.
+		if n := len(stack); n > 0 {
+			if stack[n-1].array {
+				stack[n-1].index++
+			} else {
+				stack[n-1].expectKey = true
+			}
+		}
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardlint_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/a2aproject/a2a-go/v2/a2aclient/agentcard"
+	"github.com/ghchinoy/a2acli/internal/cardlint"
+)
+
+const goodCard = `{
+  "name": "Good Agent",
+  "description": "Passes every rule",
+  "version": "1.0.0",
+  "supportedInterfaces": [
+    {"url": "https://agent.example.com/a2a", "protocolBinding": "JSONRPC", "protocolVersion": "1.0"},
+    {"url": "http://127.0.0.1:9001", "protocolBinding": "HTTP+JSON", "protocolVersion": "1.0"}
+  ],
+  "securitySchemes": {"bearer": {"httpAuthSecurityScheme": {"scheme": "Bearer"}}},
+  "securityRequirements": [{"schemes": {"bearer": []}}],
+  "capabilities": {},
+  "defaultInputModes": ["text/plain"],
+  "defaultOutputModes": ["text/plain", "application/json"],
+  "skills": [
+    {"id": "echo", "name": "Echo", "description": "Echoes", "tags": []},
+    {"id": "sum", "name": "Sum", "description": "Sums", "tags": [], "inputModes": ["application/json"]}
+  ]
+}`
+
+const badCard = `{
+  "name": "Bad Agent",
+  "description": "",
+  "version": "1.0.0",
+  "supportedInterfaces": [
+    {"url": "http://agent.example.com/a2a", "protocolBinding": "JSONRPC", "protocolVersion": "1.0"},
+    {"url": "https://agent.example.com/rest", "protocolBinding": "HTTP+JSON", "protocolVersion": "0.9"},
+    {"url": "ftp://agent.example.com", "protocolBinding": "JSONRPC"}
+  ],
+  "securitySchemes": {"apiKey": {"apiKeySecurityScheme": {"location": "header", "name": "X-Key"}}},
+  "securityRequirements": [{"schemes": {"oauth": []}}],
+  "capabilities": {},
+  "defaultInputModes": ["text"],
+  "defaultOutputModes": [],
+  "skills": [
+    {"id": "echo", "name": "Echo", "description": "Echoes", "tags": []},
+    {"id": "echo", "name": "Echo 2", "description": "Echoes too", "tags": [],
+     "securityRequirements": [{"schemes": {"mtls": []}}]}
+  ]
+}`
+
+func lint(t *testing.T, raw string, opts cardlint.Options) []cardlint.Finding {
+	t.Helper()
+	card, err := agentcard.DefaultCardParser([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cardlint.Lint(context.Background(), []byte(raw), card, opts)
+}
+
+// key identifies a finding as rule@path:line.
+func key(f cardlint.Finding) string {
+	return fmt.Sprintf("%s@%s:%d", f.Rule, f.Path, f.Line)
+}
+
+func TestLintGoodCard(t *testing.T) {
+	if findings := lint(t, goodCard, cardlint.Options{Offline: true}); len(findings) != 0 {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	findings := lint(t, badCard, cardlint.Options{Offline: true})
+	want := []string{
+		"required-fields@description:3",
+		"interface-https@supportedInterfaces[0].url:6",
+		"protocol-version-mismatch@supportedInterfaces[1].protocolVersion:7",
+		"interface-https@supportedInterfaces[2].url:8",
+		"protocol-version-missing@supportedInterfaces[2]:8",
+		"unused-security-scheme@securitySchemes.apiKey:10",
+		"undefined-security-scheme@securityRequirements[0].schemes.oauth:11",
+		"mode-not-media-type@defaultInputModes[0]:13",
+		"missing-modes@defaultOutputModes:14",
+		"duplicate-skill-id@skills[1].id:17",
+		"undefined-security-scheme@skills[1].securityRequirements[0].schemes.mtls:18",
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, key(f))
+		r, ok := cardlint.Lookup(f.Rule)
+		if !ok || f.Severity != r.Severity {
+			t.Errorf("%s has severity %s, want the rule's", key(f), f.Severity)
+		}
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !cardlint.Failed(findings, false) {
+		t.Error("Failed = false")
+	}
+}
+
+func TestLintProtocol(t *testing.T) {
+	findings := lint(t, goodCard, cardlint.Options{Offline: true, Protocol: "0.3.0"})
+	if len(findings) != 1 || findings[0].Rule != "protocol-version-mismatch" || !strings.Contains(findings[0].Message, "0.3") {
+		t.Errorf("findings = %+v", findings)
+	}
+	// Warnings only fail in strict mode.
+	if cardlint.Failed(findings, false) || !cardlint.Failed(findings, true) {
+		t.Error("a warning failed the lint outside strict mode, or passed it in strict mode")
+	}
+
+	// 0.3-format cards are converted on parsing; the converted interfaces
+	// carry the card's protocolVersion.
+	legacy := `{"name":"Old","url":"https://old.example.com","protocolVersion":"1.0","preferredTransport":"JSONRPC"}`
+	card := &a2a.AgentCard{Name: "Old", SupportedInterfaces: []*a2a.AgentInterface{
+		{URL: "https://old.example.com", ProtocolBinding: a2a.TransportProtocolJSONRPC, ProtocolVersion: "1.0"},
+		{URL: "https://old.example.com/rest", ProtocolBinding: a2a.TransportProtocolHTTPJSON, ProtocolVersion: "1.0"},
+	}}
+	var mismatch []string
+	for _, f := range cardlint.Lint(context.Background(), []byte(legacy), card, cardlint.Options{Offline: true}) {
+		if f.Rule == "protocol-version-mismatch" {
+			mismatch = append(mismatch, key(f))
+		}
+	}
+	if len(mismatch) != 1 || mismatch[0] != "protocol-version-mismatch@protocolVersion:1" {
+		t.Errorf("0.3-format card claiming 1.0: %v", mismatch)
+	}
+}
+
+func TestLintSuppress(t *testing.T) {
+	findings := lint(t, badCard, cardlint.Options{Offline: true, Suppress: []string{
+		"duplicate-skill-id",
+		"undefined-security-scheme:skills[1]",
+		"interface-https:supportedInterfaces[0].url",
+		"missing-modes:defaultInputModes",
+	}})
+	suppressed := map[string]bool{}
+	for _, f := range findings {
+		suppressed[key(f)] = f.Suppressed
+	}
+	for k, want := range map[string]bool{
+		"duplicate-skill-id@skills[1].id:17":                                          true,
+		"undefined-security-scheme@skills[1].securityRequirements[0].schemes.mtls:18": true,
+		"undefined-security-scheme@securityRequirements[0].schemes.oauth:11":          false,
+		"interface-https@supportedInterfaces[0].url:6":                                true,
+		"interface-https@supportedInterfaces[2].url:8":                                false,
+		"missing-modes@defaultOutputModes:14":                                         false,
+	} {
+		if got, ok := suppressed[k]; !ok || got != want {
+			t.Errorf("%s suppressed = %v (found %v), want %v", k, got, ok, want)
+		}
+	}
+
+	all := lint(t, goodCard, cardlint.Options{Offline: true, Protocol: "0.3", Suppress: []string{"protocol-version-mismatch"}})
+	if len(all) != 1 || !all[0].Suppressed || cardlint.Failed(all, true) {
+		t.Errorf("suppressed findings = %+v", all)
+	}
+}
+
+func TestLintSize(t *testing.T) {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(goodCard), &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc["description"] = strings.Repeat("x", 2048)
+	raw, _ := json.Marshal(doc)
+	findings := lint(t, string(raw), cardlint.Options{Offline: true, MaxSize: 1024})
+	if len(findings) != 1 || findings[0].Rule != "oversized-card" || !strings.Contains(findings[0].Message, "largest field is description") {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestLintNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs", "/a2a":
+			w.WriteHeader(http.StatusOK)
+		case "/rpc":
+			// RPC endpoints answer GET with an error; that still counts as
+			// reachable.
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := l.Addr().String()
+	_ = l.Close()
+
+	raw := fmt.Sprintf(`{
+  "name": "Net Agent",
+  "description": "d",
+  "version": "1",
+  "supportedInterfaces": [
+    {"url": "%[1]s/a2a", "protocolBinding": "JSONRPC", "protocolVersion": "1.0"},
+    {"url": "%[1]s/rpc", "protocolBinding": "HTTP+JSON", "protocolVersion": "1.0"},
+    {"url": "http://%[2]s", "protocolBinding": "JSONRPC", "protocolVersion": "1.0"},
+    {"url": "%[2]s", "protocolBinding": "GRPC", "protocolVersion": "1.0"}
+  ],
+  "documentationUrl": "%[1]s/docs",
+  "iconUrl": "%[1]s/missing.png",
+  "provider": {"organization": "Example", "url": "data:text/plain,example"},
+  "capabilities": {},
+  "defaultInputModes": ["text/plain"],
+  "defaultOutputModes": ["text/plain"],
+  "skills": [{"id": "s", "name": "S", "description": "d", "tags": []}]
+}`, srv.URL, closed)
+
+	var got []string
+	for _, f := range lint(t, raw, cardlint.Options{Client: srv.Client()}) {
+		got = append(got, key(f))
+	}
+	want := []string{
+		"unreachable-interface@supportedInterfaces[2].url:8",
+		"unreachable-interface@supportedInterfaces[3].url:9",
+		"broken-link@iconUrl:12",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if offline := lint(t, raw, cardlint.Options{Offline: true}); len(offline) != 0 {
+		t.Errorf("offline findings = %+v", offline)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	findings := lint(t, badCard, cardlint.Options{Offline: true, Suppress: []string{"duplicate-skill-id"}})
+	b, err := json.Marshal(cardlint.SARIF(findings, "agent-card.json", "a2acli", "v1.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				Suppressions []struct {
+					Kind string `json:"kind"`
+				} `json:"suppressions"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("log = %s", b)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(cardlint.Rules()) || len(run.Results) != len(findings) {
+		t.Fatalf("%d rules, %d results", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	for i, r := range run.Results {
+		f := findings[i]
+		loc := r.Locations[0].PhysicalLocation
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID || r.Level != string(f.Severity) ||
+			loc.ArtifactLocation.URI != "agent-card.json" || loc.Region.StartLine != f.Line {
+			t.Errorf("result %d = %+v for %+v", i, r, f)
+		}
+		if suppressed := len(r.Suppressions) > 0; suppressed != f.Suppressed {
+			t.Errorf("result %d suppressions = %+v, finding suppressed = %v", i, r.Suppressions, f.Suppressed)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardlint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+// probeTimeout bounds each request of the network rules.
+const probeTimeout = 10 * time.Second
+
+var rules = []Rule{
+	{
+		ID:       "required-fields",
+		Severity: Error,
+		Summary:  "The card and its skills and interfaces have the fields A2A requires",
+		Help:     "Add the missing field; clients may reject the card without it",
+		check:    checkRequiredFields,
+	},
+	{
+		ID:       "missing-modes",
+		Severity: Error,
+		Summary:  "Default input and output modes are declared",
+		Help:     `Set defaultInputModes and defaultOutputModes to the media types the agent accepts and returns, e.g. "text/plain"`,
+		check:    checkModes,
+	},
+	{
+		ID:       "mode-not-media-type",
+		Severity: Warning,
+		Summary:  "Input and output modes are media types",
+		Help:     `Use a media type such as "text/plain" or "application/json" rather than a bare name like "text"`,
+		check:    checkMediaTypes,
+	},
+	{
+		ID:       "interface-https",
+		Severity: Error,
+		Summary:  "Interface URLs use HTTPS",
+		Help:     "Serve the interface over HTTPS; plain HTTP exposes credentials and messages (loopback addresses are exempt)",
+		check:    checkInterfaceHTTPS,
+	},
+	{
+		ID:       "undefined-security-scheme",
+		Severity: Error,
+		Summary:  "Security requirements only name schemes defined in securitySchemes",
+		Help:     "Define the scheme under securitySchemes, or fix the name in the requirement",
+		check:    checkUndefinedSchemes,
+	},
+	{
+		ID:       "unused-security-scheme",
+		Severity: Note,
+		Summary:  "Defined security schemes are required by the card or a skill",
+		Help:     "Reference the scheme from securityRequirements, or remove it",
+		check:    checkUnusedSchemes,
+	},
+	{
+		ID:       "duplicate-skill-id",
+		Severity: Error,
+		Summary:  "Skill IDs are unique",
+		Help:     "Give each skill its own ID; clients select skills by ID",
+		check:    checkDuplicateSkills,
+	},
+	{
+		ID:       "protocol-version-missing",
+		Severity: Warning,
+		Summary:  "Interfaces declare their A2A protocol version",
+		Help:     `Set protocolVersion on every interface, e.g. "1.0"`,
+		check:    checkProtocolMissing,
+	},
+	{
+		ID:       "protocol-version-mismatch",
+		Severity: Warning,
+		Summary:  "Declared protocol versions are known, consistent with the card format and usable by the client",
+		Help:     "Add an interface for the client's protocol version, or correct the declared version",
+		check:    checkProtocolMismatch,
+	},
+	{
+		ID:       "oversized-card",
+		Severity: Warning,
+		Summary:  "The card is small enough to fetch on every discovery",
+		Help:     "Move bulky content (long descriptions, examples, embedded images) to documentationUrl or an extended card",
+		check:    checkSize,
+	},
+	{
+		ID:       "unreachable-interface",
+		Severity: Error,
+		Summary:  "Interface URLs accept connections",
+		Help:     "Check the URL, DNS and firewall; the address in the card must be reachable by clients, not just inside the deployment",
+		Network:  true,
+		check:    checkReachable,
+	},
+	{
+		ID:       "broken-link",
+		Severity: Warning,
+		Summary:  "documentationUrl, iconUrl and provider.url resolve",
+		Help:     "Fix or remove the URL",
+		Network:  true,
+		check:    checkLinks,
+	},
+}
+
+func at(path, format string, args ...any) Finding {
+	return Finding{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func ifacePath(i int) string { return fmt.Sprintf("supportedInterfaces[%d]", i) }
+
+func checkRequiredFields(_ context.Context, in *input) []Finding {
+	c := in.card
+	var out []Finding
+	for _, f := range []struct{ name, value string }{
+		{"name", c.Name}, {"description", c.Description}, {"version", c.Version},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			out = append(out, at(f.name, "card has no %s", f.name))
+		}
+	}
+	if len(c.SupportedInterfaces) == 0 {
+		out = append(out, at("supportedInterfaces", "card declares no interfaces"))
+	}
+	for i, iface := range c.SupportedInterfaces {
+		if iface.URL == "" {
+			out = append(out, at(ifacePath(i), "interface has no url"))
+		}
+		if iface.ProtocolBinding == "" {
+			out = append(out, at(ifacePath(i), "interface has no protocolBinding"))
+		}
+	}
+	if len(c.Skills) == 0 {
+		out = append(out, at("skills", "card declares no skills"))
+	}
+	for i, s := range c.Skills {
+		path := fmt.Sprintf("skills[%d]", i)
+		for _, f := range []struct{ name, value string }{
+			{"id", s.ID}, {"name", s.Name}, {"description", s.Description},
+		} {
+			if strings.TrimSpace(f.value) == "" {
+				out = append(out, at(path, "skill has no %s", f.name))
+			}
+		}
+	}
+	return out
+}
+
+func checkModes(_ context.Context, in *input) []Finding {
+	var out []Finding
+	if len(in.card.DefaultInputModes) == 0 {
+		out = append(out, at("defaultInputModes", "defaultInputModes is empty"))
+	}
+	if len(in.card.DefaultOutputModes) == 0 {
+		out = append(out, at("defaultOutputModes", "defaultOutputModes is empty"))
+	}
+	return out
+}
+
+func checkMediaTypes(_ context.Context, in *input) []Finding {
+	var out []Finding
+	check := func(path string, modes []string) {
+		for i, m := range modes {
+			if !strings.Contains(m, "/") {
+				out = append(out, at(fmt.Sprintf("%s[%d]", path, i), "%q is not a media type", m))
+			}
+		}
+	}
+	check("defaultInputModes", in.card.DefaultInputModes)
+	check("defaultOutputModes", in.card.DefaultOutputModes)
+	for i, s := range in.card.Skills {
+		check(fmt.Sprintf("skills[%d].inputModes", i), s.InputModes)
+		check(fmt.Sprintf("skills[%d].outputModes", i), s.OutputModes)
+	}
+	return out
+}
+
+// IsLoopback reports whether host names the local machine: localhost, a
+// *.localhost name or a loopback address. Interfaces on such hosts are exempt
+// from the interface-https rule.
+func IsLoopback(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// interfaceURL parses an interface URL. gRPC interfaces may give a bare
+// host:port, which is returned as a URL without a scheme.
+func interfaceURL(iface *a2a.AgentInterface) (*url.URL, error) {
+	if iface.ProtocolBinding == a2a.TransportProtocolGRPC && !strings.Contains(iface.URL, "://") {
+		host, port, err := net.SplitHostPort(iface.URL)
+		if err != nil {
+			return nil, err
+		}
+		return &url.URL{Host: net.JoinHostPort(host, port)}, nil
+	}
+	return url.Parse(iface.URL)
+}
+
+func checkInterfaceHTTPS(_ context.Context, in *input) []Finding {
+	var out []Finding
+	for i, iface := range in.card.SupportedInterfaces {
+		if iface.URL == "" {
+			continue
+		}
+		path := ifacePath(i) + ".url"
+		u, err := interfaceURL(iface)
+		if err != nil {
+			out = append(out, at(path, "%q is not a valid URL: %v", iface.URL, err))
+			continue
+		}
+		switch u.Scheme {
+		case "https", "grpcs", "unix", "":
+		case "http", "grpc":
+			if !IsLoopback(u.Hostname()) {
+				out = append(out, at(path, "%s uses plain %s", iface.URL, strings.ToUpper(u.Scheme)))
+			}
+		default:
+			out = append(out, at(path, "%s has unsupported scheme %q", iface.URL, u.Scheme))
+		}
+	}
+	return out
+}
+
+// requirementRefs calls fn for every scheme named by a security requirement
+// of the card or its skills, with the path of the reference.
+func requirementRefs(c *a2a.AgentCard, fn func(path string, name a2a.SecuritySchemeName)) {
+	visit := func(prefix string, opts a2a.SecurityRequirementsOptions) {
+		for i, req := range opts {
+			names := make([]string, 0, len(req))
+			for name := range req {
+				names = append(names, string(name))
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fn(fmt.Sprintf("%ssecurityRequirements[%d].schemes.%s", prefix, i, name), a2a.SecuritySchemeName(name))
+			}
+		}
+	}
+	visit("", c.SecurityRequirements)
+	for i, s := range c.Skills {
+		visit(fmt.Sprintf("skills[%d].", i), s.SecurityRequirements)
+	}
+}
+
+func checkUndefinedSchemes(_ context.Context, in *input) []Finding {
+	var out []Finding
+	requirementRefs(in.card, func(path string, name a2a.SecuritySchemeName) {
+		if _, ok := in.card.SecuritySchemes[name]; !ok {
+			out = append(out, at(path, "security scheme %q is not defined in securitySchemes", name))
+		}
+	})
+	return out
+}
+
+func checkUnusedSchemes(_ context.Context, in *input) []Finding {
+	used := map[a2a.SecuritySchemeName]bool{}
+	requirementRefs(in.card, func(_ string, name a2a.SecuritySchemeName) { used[name] = true })
+	var out []Finding
+	for name := range in.card.SecuritySchemes {
+		if !used[name] {
+			out = append(out, at("securitySchemes."+string(name), "security scheme %q is never required", name))
+		}
+	}
+	return out
+}
+
+func checkDuplicateSkills(_ context.Context, in *input) []Finding {
+	first := map[string]int{}
+	var out []Finding
+	for i, s := range in.card.Skills {
+		if s.ID == "" {
+			continue
+		}
+		if j, ok := first[s.ID]; ok {
+			out = append(out, at(fmt.Sprintf("skills[%d].id", i), "skill ID %q is also used by skills[%d]", s.ID, j))
+			continue
+		}
+		first[s.ID] = i
+	}
+	return out
+}
+
+// majorMinor reduces a protocol version such as 1.0.0 or 1 to 1.0.
+func majorMinor(v string) string {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".", 3)
+	if len(parts) == 1 {
+		parts = append(parts, "0")
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func checkProtocolMissing(_ context.Context, in *input) []Finding {
+	var out []Finding
+	for i, iface := range in.card.SupportedInterfaces {
+		if iface.ProtocolVersion == "" {
+			out = append(out, at(ifacePath(i), "interface %s does not declare a protocolVersion", iface.URL))
+		}
+	}
+	return out
+}
+
+func checkProtocolMismatch(_ context.Context, in *input) []Finding {
+	var out []Finding
+	known := map[string]bool{"0.3": true, "1.0": true}
+	declared := map[string]bool{}
+	for i, iface := range in.card.SupportedInterfaces {
+		if iface.ProtocolVersion == "" {
+			continue
+		}
+		v := majorMinor(string(iface.ProtocolVersion))
+		declared[v] = true
+		if !known[v] {
+			out = append(out, at(ifacePath(i)+".protocolVersion", "unknown A2A protocol version %q", iface.ProtocolVersion))
+		}
+		// 0.3 cards declare one version for all their interfaces.
+		if in.legacy && i == 0 && !strings.HasPrefix(v, "0.") {
+			out = append(out, at("protocolVersion", "card uses the A2A 0.3 format but declares protocol version %s", iface.ProtocolVersion))
+		}
+	}
+	client := majorMinor(in.opts.Protocol)
+	if in.opts.Protocol == "" {
+		client = majorMinor(string(a2a.Version))
+	}
+	if len(declared) > 0 && !declared[client] {
+		versions := make([]string, 0, len(declared))
+		for v := range declared {
+			versions = append(versions, v)
+		}
+		sort.Strings(versions)
+		out = append(out, at("supportedInterfaces", "no interface speaks A2A %s, the client's version; the card declares %s",
+			client, strings.Join(versions, ", ")))
+	}
+	return out
+}
+
+func checkSize(_ context.Context, in *input) []Finding {
+	limit := in.opts.MaxSize
+	if limit <= 0 {
+		limit = DefaultMaxSize
+	}
+	if len(in.raw) <= limit {
+		return nil
+	}
+	msg := fmt.Sprintf("card is %s, over the %s limit", size(len(in.raw)), size(limit))
+	var members map[string]json.RawMessage
+	if json.Unmarshal(in.raw, &members) == nil {
+		largest, n := "", 0
+		for k, v := range members {
+			if len(v) > n {
+				largest, n = k, len(v)
+			}
+		}
+		if largest != "" {
+			msg += fmt.Sprintf("; the largest field is %s (%s)", largest, size(n))
+		}
+	}
+	return []Finding{at("", "%s", msg)}
+}
+
+func size(n int) string {
+	if n < 1<<10 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.0f KiB", float64(n)/(1<<10))
+}
+
+// probeAll runs probe for each path concurrently and collects its findings.
+func probeAll(paths []string, probe func(path string) *Finding) []Finding {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var out []Finding
+	for _, p := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if f := probe(p); f != nil {
+				mu.Lock()
+				out = append(out, *f)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+func checkReachable(ctx context.Context, in *input) []Finding {
+	targets := map[string]*a2a.AgentInterface{}
+	var paths []string
+	for i, iface := range in.card.SupportedInterfaces {
+		if iface.URL != "" {
+			path := ifacePath(i) + ".url"
+			targets[path] = iface
+			paths = append(paths, path)
+		}
+	}
+	return probeAll(paths, func(path string) *Finding {
+		iface := targets[path]
+		u, err := interfaceURL(iface)
+		if err != nil || u.Scheme == "unix" {
+			// Invalid URLs are interface-https findings; sockets are local.
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+		defer cancel()
+		if u.Scheme == "" || u.Scheme == "grpc" || u.Scheme == "grpcs" {
+			host := u.Host
+			if u.Port() == "" {
+				host = net.JoinHostPort(u.Hostname(), "443")
+			}
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", host)
+			if err != nil {
+				f := at(path, "%s is unreachable: %v", iface.URL, err)
+				return &f
+			}
+			_ = conn.Close()
+			return nil
+		}
+		// Any HTTP response shows the endpoint is there; RPC endpoints
+		// commonly answer GET with 404 or 405.
+		if _, err := get(ctx, in.client, iface.URL); err != nil {
+			f := at(path, "%s is unreachable: %v", iface.URL, err)
+			return &f
+		}
+		return nil
+	})
+}
+
+func checkLinks(ctx context.Context, in *input) []Finding {
+	links := map[string]string{"documentationUrl": in.card.DocumentationURL, "iconUrl": in.card.IconURL}
+	if in.card.Provider != nil {
+		links["provider.url"] = in.card.Provider.URL
+	}
+	var paths []string
+	for path, link := range links {
+		if link != "" && !strings.HasPrefix(link, "data:") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return probeAll(paths, func(path string) *Finding {
+		link := links[path]
+		if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			f := at(path, "%q is not an http(s) URL", link)
+			return &f
+		}
+		ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+		defer cancel()
+		status, err := get(ctx, in.client, link)
+		switch {
+		case err != nil:
+			f := at(path, "%s does not resolve: %v", link, err)
+			return &f
+		case status >= http.StatusBadRequest:
+			f := at(path, "%s returns HTTP %d", link, status)
+			return &f
+		}
+		return nil
+	})
+}
+
+// get requests target and returns the response status.
+func get(ctx context.Context, client *http.Client, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The caller reports the URL; keep just the cause.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
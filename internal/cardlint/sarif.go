@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardlint
+
+// SARIF 2.1.0 (https://docs.oasis-open.org/sarif/sarif/v2.1.0/) is the
+// format code scanning services such as GitHub's accept.
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// SARIFLog is a SARIF log with a single run.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string    `json:"id"`
+	ShortDescription     sarifText `json:"shortDescription"`
+	Help                 sarifText `json:"help"`
+	DefaultConfiguration struct {
+		Level Severity `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        Severity           `json:"level"`
+	Message      sarifText          `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *struct {
+			StartLine int `json:"startLine"`
+		} `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+	LogicalLocations []struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	} `json:"logicalLocations,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+// SARIF returns findings as a SARIF log. uri locates the card that was
+// linted; tool and version identify the linter.
+func SARIF(findings []Finding, uri, tool, version string) SARIFLog {
+	driver := sarifDriver{Name: tool, Version: version, InformationURI: "https://github.com/ghchinoy/a2acli"}
+	index := map[string]int{}
+	for i, r := range rules {
+		sr := sarifRule{ID: r.ID, ShortDescription: sarifText{r.Summary}, Help: sarifText{r.Help}}
+		sr.DefaultConfiguration.Level = r.Severity
+		driver.Rules = append(driver.Rules, sr)
+		index[r.ID] = i
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = uri
+		if f.Line > 0 {
+			loc.PhysicalLocation.Region = &struct {
+				StartLine int `json:"startLine"`
+			}{f.Line}
+		}
+		if f.Path != "" {
+			loc.LogicalLocations = []struct {
+				FullyQualifiedName string `json:"fullyQualifiedName"`
+			}{{f.Path}}
+		}
+		res := sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: index[f.Rule],
+			Level:     f.Severity,
+			Message:   sarifText{f.Message},
+			Locations: []sarifLocation{loc},
+		}
+		if f.Suppressed {
+			res.Suppressions = []sarifSuppression{{Kind: "external"}}
+		}
+		results = append(results, res)
+	}
+	return SARIFLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
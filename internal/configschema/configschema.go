@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configschema validates a2acli's config.yaml against its published
+// JSON Schema. Viper silently ignores keys it does not know, so a typo such
+// as servce_url would otherwise go unnoticed; here it is reported with its
+// line number and the closest known key.
+package configschema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/ghchinoy/a2acli/internal/conformance"
+)
+
+// URL is the published location of the schema, also its $id. Editors with a
+// YAML language server can use it via
+//
+//	# yaml-language-server: $schema=<URL>
+const URL = "https://raw.githubusercontent.com/ghchinoy/a2acli/main/internal/configschema/config.schema.json"
+
+//go:embed config.schema.json
+var schemaJSON []byte
+
+// Schema returns the JSON Schema document.
+func Schema() []byte { return slices.Clone(schemaJSON) }
+
+// Issue is one problem found in a config file.
+type Issue struct {
+	// Path is the dotted key path, e.g. envs.prod.transport; empty for the
+	// document itself.
+	Path string `json:"path"`
+	// Line is the 1-based line of the key in the file, or 0 if unknown.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+var printer = message.NewPrinter(language.English)
+
+// ValidateYAML parses a config.yaml document and checks it against the
+// schema. A YAML syntax error is returned as an error; schema violations are
+// returned as issues sorted by line.
+func ValidateYAML(data []byte) ([]Issue, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := root.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	// Round-trip through JSON so YAML scalars take the types the validator
+	// expects.
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("config is not representable as JSON: %w", err)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := conformance.NewSchemaSet([]conformance.RawSchema{{URL: URL, Data: schemaJSON}})
+	if err != nil {
+		return nil, err
+	}
+	err = set.Validate(URL, value)
+	if err == nil {
+		return nil, nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, leaf := range leaves(verr) {
+		loc := leaf.InstanceLocation
+		if ap, ok := leaf.ErrorKind.(*kind.AdditionalProperties); ok {
+			known := knownKeys(loc)
+			for _, p := range ap.Properties {
+				path := append(slices.Clone(loc), p)
+				msg := "unknown key"
+				if s := suggest(p, known); s != "" {
+					msg += fmt.Sprintf(" (did you mean %s?)", s)
+				}
+				issues = append(issues, Issue{Path: strings.Join(path, "."), Line: line(&root, path), Message: msg})
+			}
+			continue
+		}
+		issues = append(issues, Issue{
+			Path:    strings.Join(loc, "."),
+			Line:    line(&root, loc),
+			Message: leaf.ErrorKind.LocalizedString(printer),
+		})
+	}
+	sort.SliceStable(issues, func(a, b int) bool { return issues[a].Line < issues[b].Line })
+	return issues, nil
+}
+
+// Line returns the 1-based line of the dotted key path in the YAML
+// document data, or of its closest existing parent; 0 if data does not parse.
+func Line(data []byte, path string) int {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || path == "" {
+		return 0
+	}
+	return line(&root, strings.Split(path, "."))
+}
+
+// leaves flattens a validation error into the errors with no causes, which
+// name the actual violations.
+func leaves(e *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(e.Causes) == 0 {
+		return []*jsonschema.ValidationError{e}
+	}
+	var out []*jsonschema.ValidationError
+	for _, c := range e.Causes {
+		out = append(out, leaves(c)...)
+	}
+	return out
+}
+
+// line returns the line of the key at path in the YAML document.
+func line(root *yaml.Node, path []string) int {
+	n := root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	ln := n.Line
+	for _, seg := range path {
+		found := false
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == seg {
+					ln = n.Content[i].Line
+					n = n.Content[i+1]
+					found = true
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			var idx int
+			if _, err := fmt.Sscanf(seg, "%d", &idx); err == nil && idx >= 0 && idx < len(n.Content) {
+				n = n.Content[idx]
+				ln = n.Line
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return ln
+}
+
+// knownKeys returns the property names the schema allows for the object at
+// path.
+func knownKeys(path []string) []string {
+	var schema map[string]any
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		return nil
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+	resolve := func(n map[string]any) map[string]any {
+		for {
+			ref, ok := n["$ref"].(string)
+			if !ok {
+				return n
+			}
+			def, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+			if def == nil {
+				return n
+			}
+			n = def
+		}
+	}
+
+	n := resolve(schema)
+	for _, seg := range path {
+		props, _ := n["properties"].(map[string]any)
+		if p, ok := props[seg].(map[string]any); ok {
+			n = resolve(p)
+		} else if ap, ok := n["additionalProperties"].(map[string]any); ok {
+			n = resolve(ap)
+		} else {
+			return nil
+		}
+	}
+	props, _ := n["properties"].(map[string]any)
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suggest returns the known key closest to key, if it is close enough to be
+// a likely typo.
+func suggest(key string, known []string) string {
+	best, bestDist := "", 3
+	for _, k := range known {
+		if d := editDistance(strings.ToLower(key), k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ghchinoy/a2acli/internal/configschema"
+)
+
+func TestValidConfig(t *testing.T) {
+	cfg := `default_env: prod
+token_store: file
+envs:
+  prod:
+    service_url: https://agent.example.com
+    transport: jsonrpc
+    protocol: 1.0.0
+    timeout: 1m30s
+    retries: 3
+    headers:
+      X-Team: research
+    oauth:
+      client_id: cli
+      scopes: [openid]
+    tls:
+      insecure_skip_verify: false
+    signing:
+      key_id: k1
+      key: /keys/k1.pem
+  sock:
+    service_url: unix:///run/agent.sock
+`
+	issues, err := configschema.ValidateYAML([]byte(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+	if issues, err := configschema.ValidateYAML(nil); err != nil || len(issues) != 0 {
+		t.Errorf("empty config: %v, %v", issues, err)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	cfg := `default_env: prod
+envs:
+  prod:
+    servce_url: https://agent.example.com
+    transport: grpcc
+    retries: many
+    tls:
+      insecure: true
+`
+	issues, err := configschema.ValidateYAML([]byte(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"envs.prod.servce_url":   "did you mean service_url?",
+		"envs.prod":              "service_url",
+		"envs.prod.transport":    "grpc",
+		"envs.prod.retries":      "integer",
+		"envs.prod.tls.insecure": "unknown key",
+	}
+	got := map[string]configschema.Issue{}
+	for _, i := range issues {
+		got[i.Path] = i
+	}
+	for path, substr := range want {
+		i, ok := got[path]
+		if !ok {
+			t.Errorf("no issue for %s; got %v", path, issues)
+			continue
+		}
+		if !strings.Contains(i.Message, substr) {
+			t.Errorf("%s: message %q does not mention %q", path, i.Message, substr)
+		}
+	}
+	if i := got["envs.prod.servce_url"]; i.Line != 4 {
+		t.Errorf("servce_url reported on line %d, want 4", i.Line)
+	}
+
+	if _, err := configschema.ValidateYAML([]byte("envs: [unclosed")); err == nil {
+		t.Error("expected a YAML syntax error")
+	}
+}
+
+func TestSchemaIsPublishedDocument(t *testing.T) {
+	var doc map[string]any
+	if err := json.Unmarshal(configschema.Schema(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["$id"] != configschema.URL {
+		t.Errorf("$id = %v, want %s", doc["$id"], configschema.URL)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpsig signs and verifies HTTP requests, either with RFC 9421
+// HTTP Message Signatures or with a simple HMAC-SHA256 header scheme, for
+// agents that authenticate callers by request signature instead of bearer
+// tokens.
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// Signature schemes.
+const (
+	// SchemeRFC9421 adds Content-Digest, Signature-Input and Signature
+	// headers as defined by RFC 9421 and RFC 9530.
+	SchemeRFC9421 = "rfc9421"
+	// SchemeHMAC adds X-A2A-Key-Id, X-A2A-Timestamp and X-A2A-Signature, the
+	// hex HMAC-SHA256 of "<timestamp>\n<METHOD>\n<path?query>\n<hex sha256(body)>".
+	SchemeHMAC = "hmac"
+)
+
+// Signer adds a signature to an outgoing request. body is the exact request
+// body, which the signature covers.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// NewSigner returns the Signer for scheme ("" selects rfc9421).
+func NewSigner(scheme string, key *Key) (Signer, error) {
+	if key.Private == nil && key.Secret == nil {
+		return nil, fmt.Errorf("key %s is a public key and cannot sign", key.ID)
+	}
+	switch scheme {
+	case "", SchemeRFC9421:
+		return &MessageSigner{Key: key}, nil
+	case SchemeHMAC:
+		if key.Alg != AlgHMACSHA256 {
+			return nil, fmt.Errorf("the hmac scheme needs a shared secret, not a %s key", key.Alg)
+		}
+		return &HMACSigner{Key: key}, nil
+	}
+	return nil, fmt.Errorf("unknown signature scheme %q (use rfc9421 or hmac)", scheme)
+}
+
+// sign computes the signature of msg with key.
+func sign(key *Key, msg []byte) ([]byte, error) {
+	switch key.Alg {
+	case AlgHMACSHA256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write(msg)
+		return mac.Sum(nil), nil
+	case AlgEd25519:
+		return key.Private.Sign(rand.Reader, msg, crypto.Hash(0))
+	case AlgECDSAP256SHA256:
+		h := sha256.Sum256(msg)
+		r, s, err := ecdsa.Sign(rand.Reader, key.Private.(*ecdsa.PrivateKey), h[:])
+		if err != nil {
+			return nil, err
+		}
+		// RFC 9421 §3.3.4: r and s as fixed-size big-endian integers.
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...), nil
+	case AlgRSAPSSSHA512:
+		h := sha512.Sum512(msg)
+		return rsa.SignPSS(rand.Reader, key.Private.(*rsa.PrivateKey), crypto.SHA512, h[:], &rsa.PSSOptions{SaltLength: 64})
+	case AlgRSAv15SHA256:
+		h := sha256.Sum256(msg)
+		return rsa.SignPKCS1v15(rand.Reader, key.Private.(*rsa.PrivateKey), crypto.SHA256, h[:])
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", key.Alg)
+}
+
+// verify checks sig over msg with key.
+func verify(key *Key, msg, sig []byte) error {
+	ok := false
+	switch key.Alg {
+	case AlgHMACSHA256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write(msg)
+		ok = subtle.ConstantTimeCompare(mac.Sum(nil), sig) == 1
+	case AlgEd25519:
+		ok = ed25519.Verify(key.Public.(ed25519.PublicKey), msg, sig)
+	case AlgECDSAP256SHA256:
+		if len(sig) == 64 {
+			h := sha256.Sum256(msg)
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			ok = ecdsa.Verify(key.Public.(*ecdsa.PublicKey), h[:], r, s)
+		}
+	case AlgRSAPSSSHA512:
+		h := sha512.Sum512(msg)
+		ok = rsa.VerifyPSS(key.Public.(*rsa.PublicKey), crypto.SHA512, h[:], sig, &rsa.PSSOptions{SaltLength: 64}) == nil
+	case AlgRSAv15SHA256:
+		h := sha256.Sum256(msg)
+		ok = rsa.VerifyPKCS1v15(key.Public.(*rsa.PublicKey), crypto.SHA256, h[:], sig) == nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", key.Alg)
+	}
+	if !ok {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// ErrBadSignature reports a signature that does not match the request.
+var ErrBadSignature = errors.New("signature does not match the request")
+
+// contentDigest returns the RFC 9530 Content-Digest value for body.
+func contentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+// authority returns the request's @authority: the Host header as sent by
+// the client (URL.Host on outgoing requests), lower-cased.
+func authority(req *http.Request) string {
+	host := req.Host
+	if host == "" && req.URL != nil {
+		host = req.URL.Host
+	}
+	return strings.ToLower(host)
+}
+
+// Transport is an http.RoundTripper that signs each request before sending
+// it with Base (http.DefaultTransport when nil).
+type Transport struct {
+	Base   http.RoundTripper
+	Signer Signer
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("sign request: read body: %w", err)
+		}
+		body = b
+	}
+	// RoundTrippers must not modify the caller's request.
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+		signed.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		signed.ContentLength = int64(len(body))
+	}
+	if err := t.Signer.Sign(signed, body); err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpsig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghchinoy/a2acli/internal/httpsig"
+)
+
+func pemKey(t *testing.T, priv any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func pemPublic(t *testing.T, pub any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// roundTrip sends a JSON-RPC style POST through a signing transport to a
+// server guarded by the verification middleware.
+func roundTrip(t *testing.T, signer httpsig.Signer, verifier *httpsig.Verifier, tamper func(*http.Request)) int {
+	t.Helper()
+	srv := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(b)
+	})))
+	defer srv.Close()
+
+	base := http.DefaultTransport
+	if tamper != nil {
+		base = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			tamper(r)
+			return http.DefaultTransport.RoundTrip(r)
+		})
+	}
+	client := &http.Client{Transport: &httpsig.Transport{Base: base, Signer: signer}}
+	resp, err := client.Post(srv.URL+"/rpc?tenant=a", "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"SendMessage"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSignVerify(t *testing.T) {
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name, scheme, alg string
+		private, public   []byte
+	}{
+		{"rfc9421 hmac", httpsig.SchemeRFC9421, "", []byte("shared-secret\n"), []byte("shared-secret")},
+		{"rfc9421 ed25519", httpsig.SchemeRFC9421, "", pemKey(t, edPriv), pemPublic(t, edPub)},
+		{"rfc9421 ecdsa", httpsig.SchemeRFC9421, "", pemKey(t, ecKey), pemPublic(t, &ecKey.PublicKey)},
+		{"rfc9421 rsa-pss", httpsig.SchemeRFC9421, "", pemKey(t, rsaKey), pemPublic(t, &rsaKey.PublicKey)},
+		{"rfc9421 rsa-v1_5", httpsig.SchemeRFC9421, httpsig.AlgRSAv15SHA256, pemKey(t, rsaKey), pemPublic(t, &rsaKey.PublicKey)},
+		{"hmac scheme", httpsig.SchemeHMAC, "", []byte("shared-secret"), []byte("shared-secret")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sk, err := httpsig.ParseKey("partner-1", tt.alg, tt.private)
+			if err != nil {
+				t.Fatalf("ParseKey(private): %v", err)
+			}
+			vk, err := httpsig.ParseKey("partner-1", tt.alg, tt.public)
+			if err != nil {
+				t.Fatalf("ParseKey(public): %v", err)
+			}
+			signer, err := httpsig.NewSigner(tt.scheme, sk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier := &httpsig.Verifier{Keys: map[string]*httpsig.Key{"partner-1": vk}}
+
+			if code := roundTrip(t, signer, verifier, nil); code != http.StatusOK {
+				t.Errorf("signed request: status %d", code)
+			}
+			// Changing the path after signing must be detected.
+			if code := roundTrip(t, signer, verifier, func(r *http.Request) { r.URL.Path = "/admin" }); code != http.StatusUnauthorized {
+				t.Errorf("re-targeted request: status %d, want 401", code)
+			}
+			// A stale signature is rejected.
+			stale := &httpsig.Verifier{Keys: verifier.Keys, Now: func() time.Time { return time.Now().Add(time.Hour) }}
+			if code := roundTrip(t, signer, stale, nil); code != http.StatusUnauthorized {
+				t.Errorf("stale signature: status %d, want 401", code)
+			}
+			// An unknown key ID is rejected.
+			other := &httpsig.Verifier{Keys: map[string]*httpsig.Key{}}
+			if code := roundTrip(t, signer, other, nil); code != http.StatusUnauthorized {
+				t.Errorf("unknown key: status %d, want 401", code)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsBodyTampering(t *testing.T) {
+	key, _ := httpsig.ParseKey("k", "", []byte("secret"))
+	signer, _ := httpsig.NewSigner(httpsig.SchemeRFC9421, key)
+	verifier := &httpsig.Verifier{Keys: map[string]*httpsig.Key{"k": key}}
+	code := roundTrip(t, signer, verifier, func(r *http.Request) {
+		body := `{"jsonrpc":"2.0","method":"CancelTask"}`
+		r.Body = io.NopCloser(strings.NewReader(body))
+		r.ContentLength = int64(len(body))
+	})
+	if code != http.StatusUnauthorized {
+		t.Errorf("tampered body: status %d, want 401", code)
+	}
+
+	srv := httptest.NewServer(verifier.Middleware(http.NotFoundHandler()))
+	defer srv.Close()
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestParseKeyAlgorithmMismatch(t *testing.T) {
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := httpsig.ParseKey("k", httpsig.AlgRSAPSSSHA512, pemKey(t, edPriv)); err == nil {
+		t.Error("expected an error for an Ed25519 key declared as rsa-pss-sha512")
+	}
+	if _, err := httpsig.ParseKey("k", httpsig.AlgEd25519, []byte("secret")); err == nil {
+		t.Error("expected an error for a shared secret declared as ed25519")
+	}
+	pub, _ := httpsig.ParseKey("k", "", pemPublic(t, edPriv.Public()))
+	if _, err := httpsig.NewSigner("", pub); err == nil {
+		t.Error("a public key must not be usable for signing")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// Algorithm names from the RFC 9421 HTTP Signature Algorithms registry.
+const (
+	AlgHMACSHA256      = "hmac-sha256"
+	AlgEd25519         = "ed25519"
+	AlgECDSAP256SHA256 = "ecdsa-p256-sha256"
+	AlgRSAPSSSHA512    = "rsa-pss-sha512"
+	AlgRSAv15SHA256    = "rsa-v1_5-sha256"
+)
+
+// Key is signing or verification key material with its key ID and
+// algorithm. Exactly one of Secret (HMAC) or Private/Public is set; a key
+// parsed from a private key can both sign and verify.
+type Key struct {
+	ID      string
+	Alg     string
+	Secret  []byte
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// ParseKey builds a Key from material, which is either a PEM private or
+// public key (PKCS#8, PKCS#1, SEC 1 or PKIX) or, for hmac-sha256, the raw
+// shared secret with surrounding whitespace trimmed. An empty alg is
+// inferred from the key type: Ed25519 keys use ed25519, P-256 keys
+// ecdsa-p256-sha256, RSA keys rsa-pss-sha512 and non-PEM material
+// hmac-sha256.
+func ParseKey(id, alg string, material []byte) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("signing key has no key ID")
+	}
+	k := &Key{ID: id, Alg: alg}
+	block, _ := pem.Decode(material)
+	if block == nil {
+		if alg != "" && alg != AlgHMACSHA256 {
+			return nil, fmt.Errorf("key %s: algorithm %s needs a PEM key", id, alg)
+		}
+		k.Alg = AlgHMACSHA256
+		k.Secret = bytes.TrimSpace(material)
+		if len(k.Secret) == 0 {
+			return nil, fmt.Errorf("key %s: empty HMAC secret", id)
+		}
+		return k, nil
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		k.Private = signer
+		k.Public = signer.Public()
+	} else {
+		k.Public = parsed
+	}
+
+	var want []string
+	switch pub := k.Public.(type) {
+	case ed25519.PublicKey:
+		want = []string{AlgEd25519}
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("key %s: only P-256 ECDSA keys are supported", id)
+		}
+		want = []string{AlgECDSAP256SHA256}
+	case *rsa.PublicKey:
+		want = []string{AlgRSAPSSSHA512, AlgRSAv15SHA256}
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %T", id, k.Public)
+	}
+	if k.Alg == "" {
+		k.Alg = want[0]
+	}
+	for _, a := range want {
+		if a == k.Alg {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("key %s: algorithm %s does not match a %T", id, k.Alg, k.Public)
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpsig
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signatureLabel is the label a2acli gives its RFC 9421 signature.
+const signatureLabel = "sig1"
+
+// MessageSigner signs requests with RFC 9421 HTTP Message Signatures. The
+// signature covers @method, @authority, @path, @query when present, and the
+// Content-Digest and Content-Type headers when the request has a body.
+type MessageSigner struct {
+	Key *Key
+	// Now returns the created time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// Sign implements Signer.
+func (s *MessageSigner) Sign(req *http.Request, body []byte) error {
+	components := []string{"@method", "@authority", "@path"}
+	if req.URL.RawQuery != "" {
+		components = append(components, "@query")
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Digest", contentDigest(body))
+		components = append(components, "content-digest")
+		if req.Header.Get("Content-Type") != "" {
+			components = append(components, "content-type")
+		}
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	params := signatureParams{
+		Components: components,
+		Created:    now().Unix(),
+		KeyID:      s.Key.ID,
+		Alg:        s.Key.Alg,
+	}
+	base, err := signatureBase(req, params)
+	if err != nil {
+		return err
+	}
+	sig, err := sign(s.Key, []byte(base))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Signature-Input", signatureLabel+"="+params.String())
+	req.Header.Set("Signature", signatureLabel+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	return nil
+}
+
+// signatureParams is an RFC 9421 signature parameter list.
+type signatureParams struct {
+	Components []string
+	Created    int64
+	KeyID      string
+	Alg        string
+	// raw is the serialized value as received, which verification must
+	// use verbatim in the signature base.
+	raw string
+}
+
+// String serializes p as an Inner List with parameters (RFC 8941).
+func (p signatureParams) String() string {
+	if p.raw != "" {
+		return p.raw
+	}
+	quoted := make([]string, len(p.Components))
+	for i, c := range p.Components {
+		quoted[i] = strconv.Quote(c)
+	}
+	s := "(" + strings.Join(quoted, " ") + ");created=" + strconv.FormatInt(p.Created, 10) + ";keyid=" + strconv.Quote(p.KeyID)
+	if p.Alg != "" {
+		s += ";alg=" + strconv.Quote(p.Alg)
+	}
+	return s
+}
+
+// signatureBase builds the RFC 9421 §2.5 signature base for req.
+func signatureBase(req *http.Request, p signatureParams) (string, error) {
+	var b strings.Builder
+	for _, c := range p.Components {
+		var v string
+		switch c {
+		case "@method":
+			v = req.Method
+		case "@authority":
+			v = authority(req)
+		case "@path":
+			v = req.URL.EscapedPath()
+			if v == "" {
+				v = "/"
+			}
+		case "@query":
+			v = "?" + req.URL.RawQuery
+		case "@target-uri":
+			scheme := "http"
+			if req.TLS != nil || req.URL.Scheme == "https" {
+				scheme = "https"
+			}
+			v = scheme + "://" + authority(req) + req.URL.RequestURI()
+		default:
+			if strings.HasPrefix(c, "@") {
+				return "", fmt.Errorf("unsupported derived component %s", c)
+			}
+			values := req.Header.Values(c)
+			if len(values) == 0 {
+				return "", fmt.Errorf("covered header %s is missing", c)
+			}
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+			v = strings.Join(values, ", ")
+		}
+		fmt.Fprintf(&b, "%q: %s\n", c, v)
+	}
+	fmt.Fprintf(&b, "%q: %s", "@signature-params", p.String())
+	return b.String(), nil
+}
+
+// parseSignature extracts the first signature from the Signature-Input and
+// Signature headers. It understands the subset of RFC 8941 structured
+// fields used by signature parameters: quoted components, and string or
+// integer parameters.
+func parseSignature(req *http.Request) (signatureParams, []byte, error) {
+	var p signatureParams
+	input := req.Header.Get("Signature-Input")
+	label, value, ok := strings.Cut(input, "=")
+	if !ok {
+		return p, nil, fmt.Errorf("malformed Signature-Input")
+	}
+	label = strings.TrimSpace(label)
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, ", "+label); i >= 0 {
+		value = value[:i]
+	}
+	p.raw = value
+
+	if !strings.HasPrefix(value, "(") {
+		return p, nil, fmt.Errorf("malformed Signature-Input component list")
+	}
+	end := strings.IndexByte(value, ')')
+	if end < 0 {
+		return p, nil, fmt.Errorf("malformed Signature-Input component list")
+	}
+	for _, f := range strings.Fields(value[1:end]) {
+		c, err := strconv.Unquote(f)
+		if err != nil {
+			return p, nil, fmt.Errorf("malformed component %s", f)
+		}
+		p.Components = append(p.Components, strings.ToLower(c))
+	}
+	for _, param := range strings.Split(value[end+1:], ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "created":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return p, nil, fmt.Errorf("malformed created parameter")
+			}
+			p.Created = n
+		case "keyid", "alg":
+			s, err := strconv.Unquote(v)
+			if err != nil {
+				return p, nil, fmt.Errorf("malformed %s parameter", k)
+			}
+			if k == "keyid" {
+				p.KeyID = s
+			} else {
+				p.Alg = s
+			}
+		}
+	}
+
+	var sig []byte
+	for _, entry := range strings.Split(req.Header.Get("Signature"), ",") {
+		l, v, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || l != label {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(strings.Trim(v, ":"))
+		if err != nil {
+			return p, nil, fmt.Errorf("malformed Signature value")
+		}
+		sig = b
+	}
+	if sig == nil {
+		return p, nil, fmt.Errorf("no Signature for label %s", label)
+	}
+	return p, sig, nil
+}
+
+// requiredComponents are the components a verified signature must cover, so
+// a signature for one request cannot be replayed against another path or
+// with a different body.
+func requiredComponents(req *http.Request, body []byte) []string {
+	required := []string{"@method", "@authority", "@path"}
+	if req.URL.RawQuery != "" {
+		required = append(required, "@query")
+	}
+	if len(body) > 0 {
+		required = append(required, "content-digest")
+	}
+	return required
+}
+
+// verifyMessage checks an RFC 9421 signature on req.
+func (v *Verifier) verifyMessage(req *http.Request, body []byte) (string, error) {
+	p, sig, err := parseSignature(req)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range requiredComponents(req, body) {
+		if !slices.Contains(p.Components, c) {
+			return p.KeyID, fmt.Errorf("signature does not cover %s", c)
+		}
+	}
+	if len(body) > 0 && req.Header.Get("Content-Digest") != contentDigest(body) {
+		return p.KeyID, fmt.Errorf("content-digest does not match the body")
+	}
+	key, err := v.key(p.KeyID, p.Alg)
+	if err != nil {
+		return p.KeyID, err
+	}
+	if err := v.checkTime(time.Unix(p.Created, 0)); err != nil {
+		return p.KeyID, err
+	}
+	base, err := signatureBase(req, p)
+	if err != nil {
+		return p.KeyID, err
+	}
+	return p.KeyID, verify(key, []byte(base), sig)
+}
+
+// HMACSigner signs requests with the X-A2A-Signature header scheme.
+type HMACSigner struct {
+	Key *Key
+	// Now returns the timestamp; nil uses time.Now.
+	Now func() time.Time
+}
+
+// Header names of the HMAC scheme.
+const (
+	HeaderKeyID     = "X-A2A-Key-Id"
+	HeaderTimestamp = "X-A2A-Timestamp"
+	HeaderSignature = "X-A2A-Signature"
+)
+
+// hmacMessage is the string the HMAC scheme signs.
+func hmacMessage(req *http.Request, ts string, body []byte) []byte {
+	target := req.URL.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	return []byte(ts + "\n" + req.Method + "\n" + target + "\n" + hex.EncodeToString(sha256Sum(body)))
+}
+
+// Sign implements Signer.
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	ts := strconv.FormatInt(now().Unix(), 10)
+	sig, err := sign(s.Key, hmacMessage(req, ts, body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderKeyID, s.Key.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, hex.EncodeToString(sig))
+	return nil
+}
+
+// verifyHMAC checks an X-A2A-Signature on req.
+func (v *Verifier) verifyHMAC(req *http.Request, body []byte) (string, error) {
+	keyID := req.Header.Get(HeaderKeyID)
+	key, err := v.key(keyID, AlgHMACSHA256)
+	if err != nil {
+		return keyID, err
+	}
+	ts := req.Header.Get(HeaderTimestamp)
+	n, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return keyID, fmt.Errorf("malformed %s", HeaderTimestamp)
+	}
+	if err := v.checkTime(time.Unix(n, 0)); err != nil {
+		return keyID, err
+	}
+	sig, err := hex.DecodeString(req.Header.Get(HeaderSignature))
+	if err != nil {
+		return keyID, fmt.Errorf("malformed %s", HeaderSignature)
+	}
+	return keyID, verify(key, hmacMessage(req, ts, body), sig)
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ghchinoy/a2acli/internal/jose"
+)
+
+var b64 = base64.RawURLEncoding
+
+func compact(t *testing.T, header map[string]string, payload string, sign func(input []byte) []byte) string {
+	t.Helper()
+	hb, _ := json.Marshal(header)
+	input := b64.EncodeToString(hb) + "." + b64.EncodeToString([]byte(payload))
+	return input + "." + b64.EncodeToString(sign([]byte(input)))
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	set := &jose.JWKS{Keys: []jose.JWK{
+		{Kty: "RSA", Kid: "r1", N: b64.EncodeToString(rsaKey.N.Bytes()), E: b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{Kty: "EC", Kid: "e1", Crv: "P-256", X: b64.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))), Y: b64.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{Kty: "OKP", Kid: "d1", Crv: "Ed25519", X: b64.EncodeToString(edPub)},
+		{Kty: "RSA", Kid: "enc", Use: "enc", N: b64.EncodeToString(rsaKey.N.Bytes()), E: "AQAB"},
+	}}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		sign   func([]byte) []byte
+	}{
+		{"RS256", map[string]string{"alg": "RS256", "kid": "r1"}, func(in []byte) []byte {
+			h := sha256.Sum256(in)
+			sig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, h[:])
+			return sig
+		}},
+		{"PS256 without kid", map[string]string{"alg": "PS256"}, func(in []byte) []byte {
+			h := sha256.Sum256(in)
+			sig, _ := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, h[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+			return sig
+		}},
+		{"ES256", map[string]string{"alg": "ES256", "kid": "e1"}, func(in []byte) []byte {
+			h := sha256.Sum256(in)
+			r, s, _ := ecdsa.Sign(rand.Reader, ecKey, h[:])
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}},
+		{"EdDSA", map[string]string{"alg": "EdDSA", "kid": "d1"}, func(in []byte) []byte {
+			return ed25519.Sign(edPriv, in)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jws, err := jose.ParseCompact(compact(t, tt.header, `{"sub":"x"}`, tt.sign))
+			if err != nil {
+				t.Fatalf("ParseCompact: %v", err)
+			}
+			if _, err := jws.VerifyJWKS(set); err != nil {
+				t.Fatalf("VerifyJWKS: %v", err)
+			}
+			if string(jws.Payload) != `{"sub":"x"}` {
+				t.Errorf("payload = %s", jws.Payload)
+			}
+		})
+	}
+
+	// A tampered payload must not verify.
+	token := compact(t, map[string]string{"alg": "EdDSA", "kid": "d1"}, `{"sub":"x"}`, func(in []byte) []byte {
+		return ed25519.Sign(edPriv, in)
+	})
+	hb, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": "d1"})
+	sig := token[len(token)-86:]
+	forged := b64.EncodeToString(hb) + "." + b64.EncodeToString([]byte(`{"sub":"y"}`)) + "." + sig
+	jws, err := jose.ParseCompact(forged)
+	if err != nil {
+		t.Fatalf("ParseCompact: %v", err)
+	}
+	if _, err := jws.VerifyJWKS(set); !errors.Is(err, jose.ErrNoMatchingKey) {
+		t.Errorf("forged token: err = %v, want ErrNoMatchingKey", err)
+	}
+}
+
+func TestParseCompactRejectsUnsigned(t *testing.T) {
+	for _, alg := range []string{"none", "HS256", ""} {
+		hb, _ := json.Marshal(map[string]string{"alg": alg})
+		token := b64.EncodeToString(hb) + "." + b64.EncodeToString([]byte("{}")) + "."
+		if _, err := jose.ParseCompact(token); err == nil {
+			t.Errorf("alg %q: expected error", alg)
+		}
+	}
+}
+
+func TestParseJWKSSingleKey(t *testing.T) {
+	set, err := jose.ParseJWKS([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	if err != nil {
+		t.Fatalf("ParseJWKS: %v", err)
+	}
+	if len(set.Keys) != 1 {
+		t.Fatalf("keys = %d, want 1", len(set.Keys))
+	}
+	if _, err := set.Keys[0].PublicKey(); err != nil {
+		t.Errorf("PublicKey: %v", err)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	// The example of RFC 8785 section 3.2.2.
+	in := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	got, err := jose.Canonicalize([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	// Members sort by UTF-16 code units, so U+1F600 (a surrogate pair)
+	// precedes U+FB33.
+	got, _ = jose.Canonicalize([]byte(`{"\ufb33":1,"\ud83d\ude00":2,"a":3,"-0":-0,"big":1e21,"int":100000000000000000000,"tiny":1e-7,"small":0.000001}`))
+	want = "{\"-0\":0,\"a\":3,\"big\":1e+21,\"int\":100000000000000000000,\"small\":0.000001,\"tiny\":1e-7,\"\U0001F600\":2,\"\uFB33\":1}"
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	if _, err := jose.Canonicalize([]byte(`{"a":1} {}`)); err == nil {
+		t.Error("expected an error for trailing data")
+	}
+}
+
+func TestSignDetached(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	payload := []byte(`{"name":"agent"}`)
+
+	for _, tt := range []struct {
+		key crypto.Signer
+		alg string
+	}{
+		{rsaKey, ""}, {rsaKey, "PS256"}, {ecKey, ""}, {edKey, ""},
+	} {
+		alg := tt.alg
+		if alg == "" {
+			var err error
+			if alg, err = jose.DefaultAlg(tt.key.Public()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Run(alg, func(t *testing.T) {
+			protected, sig, err := jose.SignDetached(jose.Header{Alg: alg, Kid: "k1", Typ: "JOSE"}, payload, tt.key)
+			if err != nil {
+				t.Fatalf("SignDetached: %v", err)
+			}
+			jwk, err := jose.NewJWK(tt.key.Public(), "k1", alg)
+			if err != nil {
+				t.Fatalf("NewJWK: %v", err)
+			}
+			jws, err := jose.ParseDetached(protected, payload, sig)
+			if err != nil {
+				t.Fatalf("ParseDetached: %v", err)
+			}
+			if _, err := jws.VerifyJWKS(&jose.JWKS{Keys: []jose.JWK{jwk}}); err != nil {
+				t.Errorf("VerifyJWKS: %v", err)
+			}
+			tampered, _ := jose.ParseDetached(protected, []byte(`{"name":"other"}`), sig)
+			if _, err := tampered.VerifyJWKS(&jose.JWKS{Keys: []jose.JWK{jwk}}); err == nil {
+				t.Error("a different payload verified")
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jose implements the small subset of JOSE that a2acli needs:
+// JSON Web Keys (RFC 7517), signing and verification of compact and
+// detached JSON Web Signatures (RFC 7515) with the asymmetric algorithms of
+// RFC 7518 and RFC 8037, and the JSON Canonicalization Scheme (RFC 8785)
+// that signed AgentCards are computed over.
+package jose
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+)
+
+// JWK is a public JSON Web Key. Private key members are never read.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKey decodes the key material into an *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeB64(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad modulus: %w", k.Kid, err)
+		}
+		e, err := decodeB64(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad exponent: %w", k.Kid, err)
+		}
+		if len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwk %q: unsupported RSA exponent", k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := decodeB64(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad x: %w", k.Kid, err)
+		}
+		y, err := decodeB64(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad y: %w", k.Kid, err)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("jwk %q: point is not on curve %s", k.Kid, k.Crv)
+		}
+		return pub, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := decodeB64(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %q: bad Ed25519 key", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("jwk %q: unsupported key type %q", k.Kid, k.Kty)
+}
+
+// Candidates returns the keys that may have produced a signature with the
+// given kid and alg. Keys reserved for encryption are skipped. When kid is
+// empty every signing key compatible with alg is returned, and keys without
+// a kid of their own match any kid.
+func (s *JWKS) Candidates(kid, alg string) []JWK {
+	var out []JWK
+	for _, k := range s.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if kid != "" && k.Kid != "" && k.Kid != kid {
+			continue
+		}
+		if k.Alg != "" && alg != "" && k.Alg != alg {
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// ParseJWKS decodes a JWK Set. A single bare JWK is accepted as a set of one.
+func ParseJWKS(b []byte) (*JWKS, error) {
+	var set JWKS
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		var k JWK
+		if err := json.Unmarshal(b, &k); err == nil && k.Kty != "" {
+			set.Keys = []JWK{k}
+		}
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no keys")
+	}
+	return &set, nil
+}
+
+// FetchJWKS downloads and parses the JWK Set at url. A nil client uses
+// http.DefaultClient.
+func FetchJWKS(ctx context.Context, client *http.Client, url string) (*JWKS, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/jwk-set+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS %s: HTTP %d", url, resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+	return ParseJWKS(b)
+}
+
+var b64 = base64.RawURLEncoding
+
+func decodeB64(s string) ([]byte, error) {
+	return b64.DecodeString(s)
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384/512 for crypto.Hash
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrNoMatchingKey is returned when no key in a JWKS verifies a signature.
+var ErrNoMatchingKey = errors.New("no matching key verifies the signature")
+
+// Header is the protected header of a JWS.
+type Header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid,omitempty"`
+	Typ  string   `json:"typ,omitempty"`
+	Jku  string   `json:"jku,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// JWS is a parsed compact-serialised JSON Web Signature.
+type JWS struct {
+	Header       Header
+	RawHeader    string
+	Payload      []byte
+	signingInput string
+	signature    []byte
+}
+
+// ParseCompact parses header.payload.signature. Unsecured ("none") and
+// symmetric (HS*) algorithms are rejected because a2acli only verifies
+// signatures against published public keys.
+func ParseCompact(token string) (*JWS, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed JWS: expected 3 segments, got %d", len(parts))
+	}
+	payload, err := decodeB64(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWS payload: %w", err)
+	}
+	return parseJWS(parts[0], parts[1], payload, parts[2])
+}
+
+// ParseDetached parses a JWS whose payload is carried separately (RFC 7515
+// Appendix F), as in AgentCard signatures: the base64url protected header,
+// the payload itself and the base64url signature.
+func ParseDetached(protected string, payload []byte, signature string) (*JWS, error) {
+	return parseJWS(protected, b64.EncodeToString(payload), payload, signature)
+}
+
+func parseJWS(rawHeader, rawPayload string, payload []byte, rawSig string) (*JWS, error) {
+	hb, err := decodeB64(rawHeader)
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWS header: %w", err)
+	}
+	var h Header
+	if err := json.Unmarshal(hb, &h); err != nil {
+		return nil, fmt.Errorf("malformed JWS header: %w", err)
+	}
+	if _, _, err := algParams(h.Alg); err != nil {
+		return nil, err
+	}
+	if len(h.Crit) > 0 {
+		return nil, fmt.Errorf("unsupported critical JWS header parameters %v", h.Crit)
+	}
+	sig, err := decodeB64(rawSig)
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWS signature: %w", err)
+	}
+	return &JWS{
+		Header:       h,
+		RawHeader:    rawHeader,
+		Payload:      payload,
+		signingInput: rawHeader + "." + rawPayload,
+		signature:    sig,
+	}, nil
+}
+
+// Verify checks the signature with pub.
+func (j *JWS) Verify(pub crypto.PublicKey) error {
+	return verifySignature(j.Header.Alg, pub, []byte(j.signingInput), j.signature)
+}
+
+// VerifyJWKS checks the signature against the keys in set that match the
+// header's kid and alg, returning the key that verified it.
+func (j *JWS) VerifyJWKS(set *JWKS) (*JWK, error) {
+	candidates := set.Candidates(j.Header.Kid, j.Header.Alg)
+	for i := range candidates {
+		pub, err := candidates[i].PublicKey()
+		if err != nil {
+			continue
+		}
+		if err := j.Verify(pub); err == nil {
+			return &candidates[i], nil
+		}
+	}
+	if j.Header.Kid != "" {
+		return nil, fmt.Errorf("%w (kid %q)", ErrNoMatchingKey, j.Header.Kid)
+	}
+	return nil, ErrNoMatchingKey
+}
+
+// algParams returns the key family and hash for a JWS alg.
+func algParams(alg string) (family string, hash crypto.Hash, err error) {
+	switch alg {
+	case "RS256":
+		return "RSA", crypto.SHA256, nil
+	case "RS384":
+		return "RSA", crypto.SHA384, nil
+	case "RS512":
+		return "RSA", crypto.SHA512, nil
+	case "PS256":
+		return "RSA-PSS", crypto.SHA256, nil
+	case "PS384":
+		return "RSA-PSS", crypto.SHA384, nil
+	case "PS512":
+		return "RSA-PSS", crypto.SHA512, nil
+	case "ES256":
+		return "EC", crypto.SHA256, nil
+	case "ES384":
+		return "EC", crypto.SHA384, nil
+	case "ES512":
+		return "EC", crypto.SHA512, nil
+	case "EdDSA", "Ed25519":
+		return "OKP", 0, nil
+	case "", "none":
+		return "", 0, fmt.Errorf("unsigned JWS (alg %q) is not accepted", alg)
+	}
+	return "", 0, fmt.Errorf("unsupported JWS algorithm %q", alg)
+}
+
+func verifySignature(alg string, pub crypto.PublicKey, input, sig []byte) error {
+	family, hash, err := algParams(alg)
+	if err != nil {
+		return err
+	}
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write(input)
+		digest = h.Sum(nil)
+	}
+	switch family {
+	case "RSA", "RSA-PSS":
+		k, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an RSA key, got %T", alg, pub)
+		}
+		if family == "RSA" {
+			return rsa.VerifyPKCS1v15(k, hash, digest, sig)
+		}
+		return rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "EC":
+		k, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an EC key, got %T", alg, pub)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid %s signature length %d", alg, len(sig))
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("ecdsa: verification error")
+		}
+		return nil
+	case "OKP":
+		k, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s requires an Ed25519 key, got %T", alg, pub)
+		}
+		if !ed25519.Verify(k, input, sig) {
+			return errors.New("ed25519: verification error")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported JWS algorithm %q", alg)
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// ParseKey parses a PEM private key (PKCS#8, PKCS#1 or SEC 1) or public key
+// (PKIX or PKCS#1). signer is nil for a public key.
+func ParseKey(material []byte) (pub crypto.PublicKey, signer crypto.Signer, err error) {
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return nil, nil, fmt.Errorf("no PEM key found")
+	}
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if s, ok := parsed.(crypto.Signer); ok {
+		return s.Public(), s, nil
+	}
+	return parsed, nil, nil
+}
+
+// DefaultAlg returns the JWS algorithm used for a key when none is given:
+// RS256 for RSA, ES256/ES384/ES512 by curve for ECDSA and EdDSA for Ed25519.
+func DefaultAlg(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return "ES256", nil
+		case elliptic.P384():
+			return "ES384", nil
+		case elliptic.P521():
+			return "ES512", nil
+		}
+		return "", fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "EdDSA", nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", pub)
+}
+
+// NewJWK returns the public JWK for pub.
+func NewJWK(pub crypto.PublicKey, kid, alg string) (JWK, error) {
+	k := JWK{Kid: kid, Alg: alg, Use: "sig"}
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = b64.EncodeToString(p.N.Bytes())
+		k.E = b64.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (p.Curve.Params().BitSize + 7) / 8
+		k.Kty, k.Crv = "EC", p.Curve.Params().Name
+		k.X = b64.EncodeToString(p.X.FillBytes(make([]byte, size)))
+		k.Y = b64.EncodeToString(p.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		k.Kty, k.Crv = "OKP", "Ed25519"
+		k.X = b64.EncodeToString(p)
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", pub)
+	}
+	return k, nil
+}
+
+// Sign computes the JWS signature of input with key.
+func Sign(alg string, key crypto.Signer, input []byte) ([]byte, error) {
+	family, hash, err := algParams(alg)
+	if err != nil {
+		return nil, err
+	}
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write(input)
+		digest = h.Sum(nil)
+	}
+	switch family {
+	case "RSA":
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("%s requires an RSA key, got %T", alg, key.Public())
+		}
+		return key.Sign(rand.Reader, digest, hash)
+	case "RSA-PSS":
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("%s requires an RSA key, got %T", alg, key.Public())
+		}
+		return key.Sign(rand.Reader, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash})
+	case "EC":
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an EC key, got %T", alg, key.Public())
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed-size concatenation of r and s, not ASN.1.
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...), nil
+	case "OKP":
+		if _, ok := key.Public().(ed25519.PublicKey); !ok {
+			return nil, fmt.Errorf("%s requires an Ed25519 key, got %T", alg, key.Public())
+		}
+		return key.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+	return nil, fmt.Errorf("unsupported JWS algorithm %q", alg)
+}
+
+// SignDetached signs payload under the protected header h and returns the
+// base64url header and signature, leaving the payload to be carried
+// separately (RFC 7515 Appendix F).
+func SignDetached(h Header, payload []byte, key crypto.Signer) (protected, signature string, err error) {
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return "", "", err
+	}
+	protected = b64.EncodeToString(hb)
+	sig, err := Sign(h.Alg, key, []byte(protected+"."+b64.EncodeToString(payload)))
+	if err != nil {
+		return "", "", err
+	}
+	return protected, b64.EncodeToString(sig), nil
+}
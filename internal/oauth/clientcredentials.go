@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GrantClientCredentials is the grant_type of the client_credentials flow
+// (RFC 6749 §4.4). It is recorded in StoredToken.GrantType so expired tokens
+// can be re-minted without user interaction.
+const GrantClientCredentials = "client_credentials"
+
+// Client authentication methods for the token endpoint (RFC 6749 §2.3.1),
+// named as in the token_endpoint_auth_method registry (RFC 7591).
+const (
+	// ClientAuthBasic sends client_id and client_secret in an HTTP Basic
+	// Authorization header.
+	ClientAuthBasic = "client_secret_basic"
+	// ClientAuthPost sends client_id and client_secret in the form body.
+	ClientAuthPost = "client_secret_post"
+)
+
+// ClientCredentials configures a client_credentials token request.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	// AuthMethod is ClientAuthBasic (the default) or ClientAuthPost.
+	AuthMethod string
+	Scopes     []string
+	// Audience is sent as the "audience" parameter used by many
+	// authorization servers to select the target API.
+	Audience string
+	// Resource is sent as the RFC 8707 "resource" indicator.
+	Resource string
+}
+
+// ClientCredentialsToken requests an access token with the client_credentials
+// grant. No refresh token is expected; callers mint a new token instead.
+func ClientCredentialsToken(ctx context.Context, cc ClientCredentials) (*TokenResponse, error) {
+	if cc.TokenURL == "" || cc.ClientID == "" {
+		return nil, fmt.Errorf("client credentials require a token URL and client ID")
+	}
+	body := url.Values{"grant_type": {GrantClientCredentials}}
+	if len(cc.Scopes) > 0 {
+		body.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+	if cc.Audience != "" {
+		body.Set("audience", cc.Audience)
+	}
+	if cc.Resource != "" {
+		body.Set("resource", cc.Resource)
+	}
+
+	method := cc.AuthMethod
+	if method == "" {
+		method = ClientAuthBasic
+	}
+	switch method {
+	case ClientAuthBasic:
+	case ClientAuthPost:
+		body.Set("client_id", cc.ClientID)
+		body.Set("client_secret", cc.ClientSecret)
+	default:
+		return nil, fmt.Errorf("unsupported client auth method %q (use %s or %s)", method, ClientAuthBasic, ClientAuthPost)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.TokenURL,
+		strings.NewReader(body.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("build client credentials request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if method == ClientAuthBasic {
+		// RFC 6749 §2.3.1: credentials are form-urlencoded before being
+		// placed in the Basic header.
+		req.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client credentials exchange: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return decodeTokenResponse(resp, "token endpoint")
+}
+
+// NewClientCredentialsToken mints a token with cc and returns it as a
+// StoredToken that records everything LoadValidToken needs to mint a
+// replacement once it expires.
+func NewClientCredentialsToken(ctx context.Context, cc ClientCredentials) (*StoredToken, error) {
+	tok, err := ClientCredentialsToken(ctx, cc)
+	if err != nil {
+		return nil, err
+	}
+	stored := &StoredToken{
+		TokenURL:     cc.TokenURL,
+		GrantType:    GrantClientCredentials,
+		ClientID:     cc.ClientID,
+		ClientSecret: cc.ClientSecret,
+		AuthMethod:   cc.AuthMethod,
+		Scopes:       cc.Scopes,
+		Audience:     cc.Audience,
+		Resource:     cc.Resource,
+	}
+	stored.apply(tok)
+	return stored, nil
+}
+
+// clientCredentials rebuilds the request configuration recorded in a token
+// minted by NewClientCredentialsToken.
+func (t *StoredToken) clientCredentials() ClientCredentials {
+	return ClientCredentials{
+		TokenURL:     t.TokenURL,
+		ClientID:     t.ClientID,
+		ClientSecret: t.ClientSecret,
+		AuthMethod:   t.AuthMethod,
+		Scopes:       t.Scopes,
+		Audience:     t.Audience,
+		Resource:     t.Resource,
+	}
+}
+
+// apply copies a token endpoint response onto t.
+func (t *StoredToken) apply(tok *TokenResponse) {
+	t.AccessToken = tok.AccessToken
+	if tok.RefreshToken != "" {
+		t.RefreshToken = tok.RefreshToken
+	}
+	if tok.ExpiresIn > 0 {
+		t.ExpiresAt = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	} else {
+		t.ExpiresAt = time.Time{}
+	}
+	if tok.Scope != "" {
+		t.Scope = tok.Scope
+	} else if len(t.Scopes) > 0 && t.Scope == "" {
+		t.Scope = strings.Join(t.Scopes, " ")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GrantDeviceCode is the grant_type of the device authorization grant
+// (RFC 8628 §3.4).
+const GrantDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// pollUnit scales the polling interval; tests shorten it.
+var pollUnit = time.Second
+
+// DeviceAuthorization is the device authorization response (RFC 8628 §3.2).
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// RequestDeviceCode starts the device authorization grant at
+// deviceEndpoint for cfg's client and scopes. A confidential client
+// authenticates as it does at the token endpoint (RFC 8628 §3.1).
+func RequestDeviceCode(ctx context.Context, deviceEndpoint string, cfg Config) (*DeviceAuthorization, error) {
+	body := url.Values{}
+	if len(cfg.Scopes) > 0 {
+		body.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+
+	req, err := newFormRequest(ctx, deviceEndpoint, body, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build device authorization request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		e := &TokenError{Endpoint: "device authorization endpoint", Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(e)
+		return nil, e
+	}
+	var da DeviceAuthorization
+	if err := json.NewDecoder(resp.Body).Decode(&da); err != nil {
+		return nil, fmt.Errorf("decode device authorization response: %w", err)
+	}
+	if da.DeviceCode == "" || da.UserCode == "" || da.VerificationURI == "" {
+		return nil, fmt.Errorf("device authorization response is missing device_code, user_code or verification_uri")
+	}
+	return &da, nil
+}
+
+// PollDeviceToken polls tokenEndpoint, authenticated as cfg's client, until
+// the user approves or denies the request, or the device code expires. It
+// waits Interval seconds between attempts (5 if unset) and adds 5 seconds
+// on every slow_down response, as RFC 8628 §3.5 requires.
+func PollDeviceToken(ctx context.Context, tokenEndpoint string, da *DeviceAuthorization, cfg Config) (*TokenResponse, error) {
+	interval := time.Duration(da.Interval) * pollUnit
+	if interval <= 0 {
+		interval = 5 * pollUnit
+	}
+	if da.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(da.ExpiresIn)*pollUnit)
+		defer cancel()
+	}
+
+	body := url.Values{
+		"grant_type":  {GrantDeviceCode},
+		"device_code": {da.DeviceCode},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("device code expired before the request was approved")
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		tok, err := postTokenForm(ctx, tokenEndpoint, body, cfg, "device token exchange", "token endpoint")
+		if err == nil {
+			return tok, nil
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+
+		var te *TokenError
+		if !errors.As(err, &te) {
+			return nil, err
+		}
+		switch te.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * pollUnit
+		case "access_denied":
+			return nil, fmt.Errorf("the authorization request was denied")
+		case "expired_token":
+			return nil, fmt.Errorf("device code expired before the request was approved")
+		default:
+			return nil, err
+		}
+	}
+}
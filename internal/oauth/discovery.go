@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	wellKnownOAuth = "/.well-known/oauth-authorization-server"
+	wellKnownOIDC  = "/.well-known/openid-configuration"
+)
+
+// Metadata is the subset of RFC 8414 authorization server metadata (and the
+// OpenID Connect Discovery 1.0 superset) that a2acli uses.
+type Metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                     string   `json:"token_endpoint,omitempty"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint,omitempty"`
+	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported,omitempty"`
+}
+
+// SelectPKCEMethod picks the code_challenge_method to use with the server.
+// S256 is preferred; plain is only used when it is the sole advertised
+// method. Servers that do not advertise code_challenge_methods_supported
+// are assumed to support S256, which OAuth 2.1 makes mandatory.
+func (m *Metadata) SelectPKCEMethod() (string, error) {
+	methods := m.CodeChallengeMethodsSupported
+	if len(methods) == 0 || slices.Contains(methods, PKCEMethodS256) {
+		return PKCEMethodS256, nil
+	}
+	if slices.Contains(methods, PKCEMethodPlain) {
+		return PKCEMethodPlain, nil
+	}
+	return "", fmt.Errorf("authorization server supports no usable PKCE method (advertises %s)", strings.Join(methods, ", "))
+}
+
+// FetchMetadata retrieves the metadata document at metadataURL. A URL with
+// a /.well-known/ path is fetched as-is; anything else is treated as an
+// issuer identifier and resolved with DiscoverIssuer.
+//
+// When the issuer can be derived from a well-known URL, the document's
+// issuer must match it (RFC 8414 §3.3, OIDC Discovery §4.3) so a
+// compromised or misconfigured document cannot redirect tokens elsewhere.
+func FetchMetadata(ctx context.Context, metadataURL string) (*Metadata, error) {
+	u, err := url.Parse(metadataURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid metadata URL %q", metadataURL)
+	}
+	if !strings.Contains(u.Path, "/.well-known/") {
+		return DiscoverIssuer(ctx, metadataURL)
+	}
+	md, err := getMetadata(ctx, metadataURL)
+	if err != nil {
+		return nil, err
+	}
+	if want := issuerFromWellKnown(u); want != "" && md.Issuer != "" && !sameIssuer(md.Issuer, want) {
+		return nil, fmt.Errorf("metadata at %s names issuer %q, expected %q", metadataURL, md.Issuer, want)
+	}
+	return md, nil
+}
+
+// DiscoverIssuer locates the metadata for an issuer identifier. It tries
+// the RFC 8414 location (well-known suffix inserted between host and path),
+// then the OpenID Connect location (suffix appended to the issuer), and
+// finally the RFC 8414 suffix appended to the issuer, which some servers
+// use for issuers with a path component.
+func DiscoverIssuer(ctx context.Context, issuer string) (*Metadata, error) {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid issuer %q", issuer)
+	}
+	base := strings.TrimSuffix(issuer, "/")
+	path := strings.TrimSuffix(u.Path, "/")
+	origin := u.Scheme + "://" + u.Host
+
+	candidates := []string{origin + wellKnownOAuth + path, base + wellKnownOIDC}
+	if path != "" {
+		candidates = append(candidates, base+wellKnownOAuth)
+	}
+
+	var errs []error
+	for _, c := range candidates {
+		md, err := getMetadata(ctx, c)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if md.Issuer != "" && !sameIssuer(md.Issuer, issuer) {
+			return nil, fmt.Errorf("metadata at %s names issuer %q, expected %q", c, md.Issuer, issuer)
+		}
+		return md, nil
+	}
+	return nil, fmt.Errorf("discover %s: %w", issuer, errors.Join(errs...))
+}
+
+func getMetadata(ctx context.Context, metadataURL string) (*Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build metadata request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch metadata %s: HTTP %d", metadataURL, resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read metadata: %w", err)
+	}
+	var md Metadata
+	if err := json.Unmarshal(b, &md); err != nil {
+		return nil, fmt.Errorf("decode metadata from %s: %w", metadataURL, err)
+	}
+	if md.AuthorizationEndpoint == "" && md.TokenEndpoint == "" {
+		return nil, fmt.Errorf("metadata at %s lists no authorization or token endpoint", metadataURL)
+	}
+	return &md, nil
+}
+
+// issuerFromWellKnown derives the issuer a well-known metadata URL belongs
+// to, or "" when the URL does not follow either convention.
+func issuerFromWellKnown(u *url.URL) string {
+	origin := u.Scheme + "://" + u.Host
+	switch {
+	case strings.HasSuffix(u.Path, wellKnownOIDC):
+		return origin + strings.TrimSuffix(u.Path, wellKnownOIDC)
+	case strings.HasPrefix(u.Path, wellKnownOAuth):
+		return origin + strings.TrimPrefix(u.Path, wellKnownOAuth)
+	}
+	return ""
+}
+
+func sameIssuer(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
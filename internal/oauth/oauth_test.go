@@ -16,9 +16,20 @@ package oauth_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -104,3 +115,632 @@ func TestLoadValidToken_ExpiredWithRefresh(t *testing.T) {
 		t.Error("expected loaded token to be valid (not expired)")
 	}
 }
+
+func TestClientCredentialsToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{"basic auth", oauth.ClientAuthBasic},
+		{"post body", oauth.ClientAuthPost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Fatalf("failed to parse form: %v", err)
+				}
+				if r.FormValue("grant_type") != "client_credentials" {
+					t.Errorf("expected grant_type client_credentials, got %s", r.FormValue("grant_type"))
+				}
+				if r.FormValue("scope") != "a2a:read a2a:write" || r.FormValue("audience") != "https://agent" {
+					t.Errorf("unexpected scope/audience: %q %q", r.FormValue("scope"), r.FormValue("audience"))
+				}
+				id, secret, basic := r.BasicAuth()
+				// RFC 6749 §2.3.1: Basic credentials are form-urlencoded.
+				id, _ = url.QueryUnescape(id)
+				if tt.method == oauth.ClientAuthPost {
+					basic, id, secret = false, r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+				}
+				if basic != (tt.method == oauth.ClientAuthBasic) || id != "ci:bot" || secret != "s3cret" {
+					t.Errorf("unexpected client auth: basic=%v id=%q secret=%q", basic, id, secret)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "cc_token", "expires_in": 60})
+			}))
+			defer ts.Close()
+
+			tok, err := oauth.NewClientCredentialsToken(context.Background(), oauth.ClientCredentials{
+				TokenURL:     ts.URL,
+				ClientID:     "ci:bot",
+				ClientSecret: "s3cret",
+				AuthMethod:   tt.method,
+				Scopes:       []string{"a2a:read", "a2a:write"},
+				Audience:     "https://agent",
+			})
+			if err != nil {
+				t.Fatalf("NewClientCredentialsToken failed: %v", err)
+			}
+			if tok.AccessToken != "cc_token" || tok.GrantType != oauth.GrantClientCredentials || tok.IsExpired() {
+				t.Errorf("unexpected token: %+v", tok)
+			}
+		})
+	}
+}
+
+func TestLoadValidToken_ReMintsClientCredentials(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "reminted", "expires_in": 3600})
+	}))
+	defer ts.Close()
+
+	serviceURL := "http://cc-service.local:9999"
+	if err := oauth.SaveToken(serviceURL, &oauth.StoredToken{
+		AccessToken: "stale",
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		TokenURL:    ts.URL,
+		GrantType:   oauth.GrantClientCredentials,
+		ClientID:    "ci-bot",
+	}); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	loaded, err := oauth.LoadValidToken(context.Background(), serviceURL)
+	if err != nil {
+		t.Fatalf("LoadValidToken failed: %v", err)
+	}
+	if loaded.AccessToken != "reminted" || loaded.IsExpired() || calls != 1 {
+		t.Errorf("expected a re-minted token after one call, got %+v (calls=%d)", loaded, calls)
+	}
+	if again, _ := oauth.LoadToken(serviceURL); again == nil || again.AccessToken != "reminted" {
+		t.Error("re-minted token was not saved")
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	defer oauth.SetPollUnit(time.Millisecond)()
+
+	var polls []time.Time
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/device" {
+			if r.FormValue("scope") != "agent:invoke" {
+				t.Errorf("expected scope agent:invoke, got %q", r.FormValue("scope"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"device_code":      "dev-123",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": "https://auth.example.com/device",
+				"expires_in":       600,
+				"interval":         1,
+			})
+			return
+		}
+		if r.FormValue("grant_type") != oauth.GrantDeviceCode || r.FormValue("device_code") != "dev-123" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		polls = append(polls, time.Now())
+		switch len(polls) {
+		case 1:
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+		case 2:
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "slow_down"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "device_token", "expires_in": 3600})
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	da, err := oauth.RequestDeviceCode(ctx, ts.URL+"/device", oauth.Config{Scopes: []string{"agent:invoke"}})
+	if err != nil {
+		t.Fatalf("RequestDeviceCode failed: %v", err)
+	}
+	if da.UserCode != "ABCD-EFGH" {
+		t.Errorf("unexpected user code %q", da.UserCode)
+	}
+
+	tok, err := oauth.PollDeviceToken(ctx, ts.URL+"/token", da, oauth.Config{})
+	if err != nil {
+		t.Fatalf("PollDeviceToken failed: %v", err)
+	}
+	if tok.AccessToken != "device_token" || len(polls) != 3 {
+		t.Fatalf("expected token after 3 polls, got %q after %d", tok.AccessToken, len(polls))
+	}
+	// After slow_down the interval grows from 1 to 6 units.
+	if gap := polls[2].Sub(polls[1]); gap < 6*time.Millisecond {
+		t.Errorf("slow_down did not increase the polling interval (gap %s)", gap)
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	defer oauth.SetPollUnit(time.Millisecond)()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "access_denied"})
+	}))
+	defer ts.Close()
+
+	_, err := oauth.PollDeviceToken(context.Background(), ts.URL, &oauth.DeviceAuthorization{DeviceCode: "d", Interval: 1}, oauth.Config{})
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected denial error, got %v", err)
+	}
+}
+
+func TestDeviceFlowConfidentialClient(t *testing.T) {
+	defer oauth.SetPollUnit(time.Millisecond)()
+	for _, method := range []string{oauth.ClientAuthBasic, oauth.ClientAuthPost} {
+		t.Run(method, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Fatalf("failed to parse form: %v", err)
+				}
+				id, secret, basic := r.BasicAuth()
+				if method == oauth.ClientAuthPost {
+					basic, id, secret = false, r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+				}
+				if basic != (method == oauth.ClientAuthBasic) || id != "corp-cli" || secret != "s3cret" {
+					t.Errorf("%s: unexpected client auth: basic=%v id=%q secret=%q", r.URL.Path, basic, id, secret)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/device" {
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"device_code": "dev-1", "user_code": "CODE", "verification_uri": "https://auth.example.com/device", "interval": 1,
+					})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "device_token"})
+			}))
+			defer ts.Close()
+
+			cfg := oauth.Config{ClientID: "corp-cli", ClientSecret: "s3cret", AuthMethod: method}
+			ctx := context.Background()
+			da, err := oauth.RequestDeviceCode(ctx, ts.URL+"/device", cfg)
+			if err != nil {
+				t.Fatalf("RequestDeviceCode failed: %v", err)
+			}
+			if tok, err := oauth.PollDeviceToken(ctx, ts.URL+"/token", da, cfg); err != nil || tok.AccessToken != "device_token" {
+				t.Fatalf("PollDeviceToken = %+v, %v", tok, err)
+			}
+		})
+	}
+}
+
+func TestAuthURLWithConfig(t *testing.T) {
+	pkce, _ := oauth.NewChallenge()
+	cfg := oauth.Config{
+		ClientID:    "corp-cli",
+		RedirectURI: "http://127.0.0.1:53682/cb",
+		Scopes:      []string{"openid", "agent:invoke"},
+		Audience:    "https://agent.corp",
+		AuthParams:  map[string]string{"prompt": "consent", "client_id": "ignored"},
+	}
+	u, err := url.Parse(oauth.AuthURL("https://idp.corp/authorize?tenant=x", "st", pkce, cfg))
+	if err != nil {
+		t.Fatalf("invalid auth URL: %v", err)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"tenant":       "x",
+		"client_id":    "corp-cli",
+		"redirect_uri": "http://127.0.0.1:53682/cb",
+		"scope":        "openid agent:invoke",
+		"audience":     "https://agent.corp",
+		"prompt":       "consent",
+		"state":        "st",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+}
+
+func TestStartCallbackServerEphemeralPort(t *testing.T) {
+	ctx := context.Background()
+	ch, stop, redirect, err := oauth.StartCallbackServer(ctx, "st", oauth.Config{RedirectURI: "http://127.0.0.1:0/cb"})
+	if err != nil {
+		t.Fatalf("StartCallbackServer failed: %v", err)
+	}
+	defer stop()
+
+	u, _ := url.Parse(redirect)
+	if u.Port() == "" || u.Port() == "0" || u.Path != "/cb" {
+		t.Fatalf("expected a concrete ephemeral port, got %s", redirect)
+	}
+	resp, err := http.Get(redirect + "?state=st&code=abc")
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if res := <-ch; res.Err != nil || res.Code != "abc" {
+		t.Errorf("unexpected callback result: %+v", res)
+	}
+
+	if _, _, _, err := oauth.StartCallbackServer(ctx, "st", oauth.Config{RedirectURI: "http://example.com:0/cb"}); err == nil {
+		t.Error("expected non-loopback redirect_uri to be rejected")
+	}
+}
+
+func TestExchangeCodeConfidentialClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "corp-cli" || secret != "shh" {
+			t.Errorf("expected Basic client auth, got %q/%q (ok=%v)", id, secret, ok)
+		}
+		if r.PostFormValue("client_id") != "" {
+			t.Error("client_id must not be sent in the body alongside Basic auth")
+		}
+		if r.FormValue("redirect_uri") != "http://127.0.0.1:5000/cb" {
+			t.Errorf("unexpected redirect_uri %q", r.FormValue("redirect_uri"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "tok"})
+	}))
+	defer ts.Close()
+
+	pkce, _ := oauth.NewChallenge()
+	tok, err := oauth.ExchangeCode(context.Background(), ts.URL, "code", pkce,
+		oauth.Config{ClientID: "corp-cli", ClientSecret: "shh", RedirectURI: "http://127.0.0.1:5000/cb"})
+	if err != nil || tok.AccessToken != "tok" {
+		t.Fatalf("ExchangeCode failed: %v", err)
+	}
+}
+
+func TestDiscoverIssuer(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server/tenant":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":                           ts.URL + "/tenant",
+				"authorization_endpoint":           ts.URL + "/tenant/authorize",
+				"token_endpoint":                   ts.URL + "/tenant/token",
+				"revocation_endpoint":              ts.URL + "/tenant/revoke",
+				"code_challenge_methods_supported": []string{"plain", "S256"},
+			})
+		case "/oidc/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":         ts.URL + "/oidc",
+				"token_endpoint": ts.URL + "/oidc/token",
+				"jwks_uri":       ts.URL + "/oidc/jwks",
+			})
+		case "/evil/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":         "https://elsewhere.example",
+				"token_endpoint": ts.URL + "/token",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	md, err := oauth.DiscoverIssuer(ctx, ts.URL+"/tenant")
+	if err != nil {
+		t.Fatalf("RFC 8414 discovery: %v", err)
+	}
+	if md.RevocationEndpoint != ts.URL+"/tenant/revoke" {
+		t.Errorf("revocation_endpoint = %q", md.RevocationEndpoint)
+	}
+	if m, _ := md.SelectPKCEMethod(); m != oauth.PKCEMethodS256 {
+		t.Errorf("PKCE method = %q, want S256", m)
+	}
+
+	md, err = oauth.DiscoverIssuer(ctx, ts.URL+"/oidc")
+	if err != nil {
+		t.Fatalf("OIDC discovery: %v", err)
+	}
+	if md.JWKSURI != ts.URL+"/oidc/jwks" {
+		t.Errorf("jwks_uri = %q", md.JWKSURI)
+	}
+
+	// A discovery URL is fetched directly.
+	if _, err := oauth.FetchMetadata(ctx, ts.URL+"/oidc/.well-known/openid-configuration"); err != nil {
+		t.Errorf("FetchMetadata: %v", err)
+	}
+	if _, err := oauth.FetchMetadata(ctx, ts.URL+"/evil/.well-known/openid-configuration"); err == nil {
+		t.Error("expected issuer mismatch error")
+	}
+}
+
+func TestSelectPKCEMethod(t *testing.T) {
+	tests := []struct {
+		methods []string
+		want    string
+		wantErr bool
+	}{
+		{nil, oauth.PKCEMethodS256, false},
+		{[]string{"S256"}, oauth.PKCEMethodS256, false},
+		{[]string{"plain"}, oauth.PKCEMethodPlain, false},
+		{[]string{"S512"}, "", true},
+	}
+	for _, tt := range tests {
+		md := &oauth.Metadata{CodeChallengeMethodsSupported: tt.methods}
+		got, err := md.SelectPKCEMethod()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%v: got %q, %v; want %q", tt.methods, got, err, tt.want)
+		}
+	}
+
+	c, err := oauth.NewChallengeWithMethod(oauth.PKCEMethodPlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Challenge != c.Verifier {
+		t.Error("plain challenge must equal the verifier")
+	}
+	u, _ := url.Parse(oauth.AuthURL("https://as.example/authorize", "s", c, oauth.Config{}))
+	if got := u.Query().Get("code_challenge_method"); got != "plain" {
+		t.Errorf("code_challenge_method = %q", got)
+	}
+}
+
+func TestValidateIDToken(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	enc := base64.RawURLEncoding
+	jwks := map[string]any{"keys": []map[string]string{{
+		"kty": "EC", "kid": "k1", "crv": "P-256",
+		"x": enc.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y": enc.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	defer ts.Close()
+
+	sign := func(claims map[string]any) string {
+		hb, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": "k1"})
+		cb, _ := json.Marshal(claims)
+		input := enc.EncodeToString(hb) + "." + enc.EncodeToString(cb)
+		h := sha256.Sum256([]byte(input))
+		r, s, _ := ecdsa.Sign(rand.Reader, key, h[:])
+		return input + "." + enc.EncodeToString(append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...))
+	}
+	now := time.Now().Unix()
+	base := func() map[string]any {
+		return map[string]any{"iss": "https://idp.example", "sub": "u1", "aud": "cli", "exp": now + 300, "iat": now, "nonce": "n1"}
+	}
+	check := oauth.IDTokenCheck{Issuer: "https://idp.example", ClientID: "cli", Nonce: "n1", JWKSURI: ts.URL}
+	ctx := context.Background()
+
+	claims, err := oauth.ValidateIDToken(ctx, sign(base()), check)
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if claims.Subject != "u1" {
+		t.Errorf("sub = %q", claims.Subject)
+	}
+
+	bad := map[string]func(map[string]any){
+		"issuer":   func(c map[string]any) { c["iss"] = "https://other.example" },
+		"audience": func(c map[string]any) { c["aud"] = []string{"someone-else"} },
+		"expired":  func(c map[string]any) { c["exp"] = now - 3600 },
+		"nonce":    func(c map[string]any) { c["nonce"] = "replayed" },
+		"azp":      func(c map[string]any) { c["aud"] = []string{"cli", "api"}; c["azp"] = "api" },
+	}
+	for name, mutate := range bad {
+		c := base()
+		mutate(c)
+		if _, err := oauth.ValidateIDToken(ctx, sign(c), check); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	check.Algorithms = []string{"RS256"}
+	if _, err := oauth.ValidateIDToken(ctx, sign(base()), check); err == nil {
+		t.Error("expected error for alg not advertised by the provider")
+	}
+}
+
+func TestNewTokenStore(t *testing.T) {
+	tests := map[string]string{
+		"":               "file",
+		"file":           "file",
+		"secret-service": "secret-service",
+		"helper:pass":    "helper:pass",
+	}
+	for spec, want := range tests {
+		s, err := oauth.NewTokenStore(spec)
+		if err != nil {
+			t.Fatalf("%q: %v", spec, err)
+		}
+		if s.Name() != want {
+			t.Errorf("%q: Name() = %q, want %q", spec, s.Name(), want)
+		}
+	}
+	for _, spec := range []string{"keychain", "helper:"} {
+		if _, err := oauth.NewTokenStore(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+// TestHelperStore drives SaveToken/LoadToken/DeleteToken through a shell
+// credential helper that keeps secrets in a directory.
+func TestHelperStore(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "helper")
+	script := `#!/bin/sh
+set -e
+cd "` + dir + `"
+slug() { printf %s "$1" | tr -c 'A-Za-z0-9' _; }
+case "$1" in
+get)   key=$(cat); f="$(slug "$key").cred"
+       [ -f "$f" ] || { echo "credentials not found in native keychain"; exit 1; }
+       cat "$f" ;;
+store) cat > in.json; key=$(sed 's/.*"ServerURL":"\([^"]*\)".*/\1/' in.json)
+       mv in.json "$(slug "$key").cred"; printf %s "$key" > "$(slug "$key").key" ;;
+erase) key=$(cat); rm -f "$(slug "$key").cred" "$(slug "$key").key" ;;
+list)  printf '{'; sep=
+       for f in *.key; do [ -f "$f" ] || continue; printf '%s"%s":"a2acli"' "$sep" "$(cat "$f")"; sep=,; done
+       printf '}' ;;
+esac
+`
+	if err := os.WriteFile(helper, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	s, err := oauth.NewTokenStore("helper:" + helper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oauth.SetTokenStore(s)
+	defer oauth.SetTokenStore(nil)
+
+	const svc = "https://agent.example.com"
+	if tok, err := oauth.LoadToken(svc); err != nil || tok != nil {
+		t.Fatalf("empty store: got %v, %v", tok, err)
+	}
+	if err := oauth.SaveToken(svc, &oauth.StoredToken{AccessToken: "secret-at", GrantType: oauth.GrantStatic}); err != nil {
+		t.Fatalf("SaveToken: %v", err)
+	}
+	tok, err := oauth.LoadToken(svc)
+	if err != nil || tok == nil || tok.AccessToken != "secret-at" {
+		t.Fatalf("LoadToken = %+v, %v", tok, err)
+	}
+	if err := oauth.DeleteToken(svc); err != nil {
+		t.Fatalf("DeleteToken: %v", err)
+	}
+	if tok, _ := oauth.LoadToken(svc); tok != nil {
+		t.Error("token still present after DeleteToken")
+	}
+}
+
+func TestTokensBoundToServiceIdentity(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	oauth.SetTokenStore(nil)
+
+	save := func(svc, issuer, at string, exp time.Time) {
+		t.Helper()
+		if err := oauth.SaveToken(svc, &oauth.StoredToken{AccessToken: at, Issuer: issuer, ExpiresAt: exp}); err != nil {
+			t.Fatalf("SaveToken(%s): %v", svc, err)
+		}
+	}
+	save("http://localhost:9001", "", "port-9001", time.Time{})
+	save("https://gw.example.com/agents/a/", "", "tenant-a", time.Time{})
+
+	for svc, want := range map[string]string{
+		"http://localhost:9001":           "port-9001",
+		"http://LOCALHOST:9001/":          "port-9001",
+		"http://localhost:9002":           "",
+		"https://gw.example.com/agents/a": "tenant-a",
+		"https://gw.example.com/agents/b": "",
+		"https://gw.example.com":          "",
+	} {
+		tok, err := oauth.LoadToken(svc)
+		if err != nil {
+			t.Fatalf("LoadToken(%s): %v", svc, err)
+		}
+		got := ""
+		if tok != nil {
+			got = tok.AccessToken
+		}
+		if got != want {
+			t.Errorf("LoadToken(%s) = %q, want %q", svc, got, want)
+		}
+	}
+
+	// Tokens from two issuers coexist; the unexpired one wins.
+	save("https://gw.example.com/agents/a", "https://idp-old.example", "old", time.Now().Add(-time.Hour))
+	save("https://gw.example.com/agents/a", "https://idp-new.example", "new", time.Now().Add(time.Hour))
+	entries, err := oauth.ListTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("ListTokens returned %d entries, want 4", len(entries))
+	}
+	if err := oauth.DeleteToken("https://gw.example.com/agents/a"); err != nil {
+		t.Fatal(err)
+	}
+	if tok, _ := oauth.LoadToken("https://gw.example.com/agents/a"); tok != nil {
+		t.Errorf("DeleteToken left %q behind", tok.AccessToken)
+	}
+	if tok, _ := oauth.LoadToken("http://localhost:9001"); tok == nil {
+		t.Error("DeleteToken removed another service's token")
+	}
+}
+
+func TestLegacyTokenMigration(t *testing.T) {
+	cfg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfg)
+	oauth.SetTokenStore(nil)
+
+	dir := filepath.Join(cfg, "a2acli", "tokens")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	legacy := filepath.Join(dir, "agent_example_com.json")
+	if err := os.WriteFile(legacy, []byte(`{"access_token":"legacy-at","token_url":"https://as/token"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := oauth.ListTokens()
+	if err != nil || len(entries) != 1 || !entries[0].Legacy {
+		t.Fatalf("ListTokens = %+v, %v; want one legacy entry", entries, err)
+	}
+
+	tok, err := oauth.LoadToken("https://agent.example.com:8443/a2a")
+	if err != nil || tok == nil || tok.AccessToken != "legacy-at" {
+		t.Fatalf("LoadToken = %+v, %v", tok, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("legacy file was not removed after migration")
+	}
+	if tok.ServiceURL != "https://agent.example.com:8443/a2a" {
+		t.Errorf("migrated ServiceURL = %q", tok.ServiceURL)
+	}
+	// The migrated token is bound to the first service that used it.
+	if tok, _ := oauth.LoadToken("https://agent.example.com/other"); tok != nil {
+		t.Error("migrated token leaked to another service on the same host")
+	}
+}
+
+func TestStoredTokenRevoke(t *testing.T) {
+	var revoked []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.FormValue("client_id") != "cli" {
+			t.Errorf("client_id = %q", r.FormValue("client_id"))
+		}
+		revoked = append(revoked, r.FormValue("token_type_hint")+"="+r.FormValue("token"))
+		if r.FormValue("token") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"unsupported_token_type"}`))
+		}
+	}))
+	defer ts.Close()
+
+	tok := &oauth.StoredToken{AccessToken: "at", RefreshToken: "rt", ClientID: "cli", RevocationURL: ts.URL}
+	if err := tok.Revoke(context.Background()); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	want := []string{"refresh_token=rt", "access_token=at"}
+	if strings.Join(revoked, ",") != strings.Join(want, ",") {
+		t.Errorf("revoked %v, want %v (refresh token first)", revoked, want)
+	}
+
+	tok = &oauth.StoredToken{AccessToken: "bad", ClientID: "cli", RevocationURL: ts.URL}
+	if err := tok.Revoke(context.Background()); err == nil || !strings.Contains(err.Error(), "unsupported_token_type") {
+		t.Errorf("expected revocation error, got %v", err)
+	}
+	if err := (&oauth.StoredToken{AccessToken: "at"}).Revoke(context.Background()); !errors.Is(err, oauth.ErrNoRevocationEndpoint) {
+		t.Errorf("expected ErrNoRevocationEndpoint, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ghchinoy/a2acli/internal/jose"
+)
+
+// clockSkew is the leeway allowed when checking exp and iat.
+const clockSkew = 2 * time.Minute
+
+// Audience is the aud claim, which may be a single string or an array.
+type Audience []string
+
+// UnmarshalJSON accepts both forms of the aud claim.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("aud must be a string or array of strings")
+	}
+	*a = list
+	return nil
+}
+
+// IDTokenClaims are the OpenID Connect ID token claims a2acli checks or
+// displays.
+type IDTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        Audience `json:"aud"`
+	Expiry          int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce,omitempty"`
+	AuthorizedParty string   `json:"azp,omitempty"`
+	Email           string   `json:"email,omitempty"`
+	Name            string   `json:"name,omitempty"`
+}
+
+// IDTokenCheck describes the expected values of an ID token.
+type IDTokenCheck struct {
+	Issuer   string
+	ClientID string
+	Nonce    string
+	JWKSURI  string
+	// Algorithms restricts the accepted alg values, typically to the
+	// provider's id_token_signing_alg_values_supported. Empty allows any
+	// asymmetric algorithm jose supports.
+	Algorithms []string
+}
+
+// NewNonce returns a random value for the OpenID Connect nonce parameter.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidateIDToken verifies an ID token's signature against the provider's
+// JWKS and checks iss, aud, azp, exp, iat and nonce as required by OpenID
+// Connect Core §3.1.3.7.
+func ValidateIDToken(ctx context.Context, raw string, check IDTokenCheck) (*IDTokenClaims, error) {
+	jws, err := jose.ParseCompact(raw)
+	if err != nil {
+		return nil, fmt.Errorf("id_token: %w", err)
+	}
+	if len(check.Algorithms) > 0 && !slices.Contains(check.Algorithms, jws.Header.Alg) {
+		return nil, fmt.Errorf("id_token: alg %s is not one the provider advertises (%v)", jws.Header.Alg, check.Algorithms)
+	}
+	if check.JWKSURI == "" {
+		return nil, fmt.Errorf("id_token: provider metadata has no jwks_uri to verify the signature")
+	}
+	set, err := jose.FetchJWKS(ctx, nil, check.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf("id_token: %w", err)
+	}
+	if _, err := jws.VerifyJWKS(set); err != nil {
+		return nil, fmt.Errorf("id_token signature: %w", err)
+	}
+
+	var claims IDTokenClaims
+	if err := json.Unmarshal(jws.Payload, &claims); err != nil {
+		return nil, fmt.Errorf("id_token claims: %w", err)
+	}
+	if !sameIssuer(claims.Issuer, check.Issuer) {
+		return nil, fmt.Errorf("id_token: issuer %q, expected %q", claims.Issuer, check.Issuer)
+	}
+	if !slices.Contains(claims.Audience, check.ClientID) {
+		return nil, fmt.Errorf("id_token: audience %v does not include client %q", []string(claims.Audience), check.ClientID)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != "" && claims.AuthorizedParty != check.ClientID {
+		return nil, fmt.Errorf("id_token: azp %q does not match client %q", claims.AuthorizedParty, check.ClientID)
+	}
+	now := time.Now()
+	if claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("id_token has expired")
+	}
+	if claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)) {
+		return nil, fmt.Errorf("id_token issued in the future (check the system clock)")
+	}
+	if check.Nonce != "" && claims.Nonce != check.Nonce {
+		return nil, fmt.Errorf("id_token: nonce mismatch (possible replay)")
+	}
+	return &claims, nil
+}
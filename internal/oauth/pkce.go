@@ -23,6 +23,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -33,24 +34,32 @@ import (
 )
 
 // CallbackAddr is the pre-registered redirect URI base. The mithlond consent SPA's
-// metadata.json registers http://127.0.0.1:8080/callback — the port is FIXED.
-// a2acli must bind exactly here; port randomisation is not possible without a new
-// registration.
+// metadata.json registers http://127.0.0.1:8080/callback — the port is FIXED
+// for the CIMD client. Clients registered elsewhere can set Config.RedirectURI,
+// including an ephemeral loopback port.
 const CallbackAddr = "127.0.0.1:8080"
 
 // CallbackPath is the URL path for the OAuth authorization callback endpoint.
 const CallbackPath = "/callback"
 
-// RedirectURI is the full callback redirect URI used in OAuth requests.
+// RedirectURI is the default callback redirect URI used in OAuth requests.
 const RedirectURI = "http://" + CallbackAddr + CallbackPath
 
 // CIMDURL is a2acli's own Client Instance Metadata Document URL. Used as client_id.
 const CIMDURL = "https://ghchinoy.github.io/a2acli/metadata.json"
 
-// Challenge holds a PKCE S256 code_verifier / code_challenge pair.
+// PKCE code_challenge_method values (RFC 7636 §4.2).
+const (
+	PKCEMethodS256  = "S256"
+	PKCEMethodPlain = "plain"
+)
+
+// Challenge holds a PKCE code_verifier / code_challenge pair.
 type Challenge struct {
 	Verifier  string
 	Challenge string
+	// Method is the code_challenge_method; empty means S256.
+	Method string
 }
 
 // NewChallenge generates a fresh PKCE S256 code_verifier and derives the
@@ -63,20 +72,109 @@ func NewChallenge() (Challenge, error) {
 	verifier := base64.RawURLEncoding.EncodeToString(b)
 	sum := sha256.Sum256([]byte(verifier))
 	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
-	return Challenge{Verifier: verifier, Challenge: challenge}, nil
+	return Challenge{Verifier: verifier, Challenge: challenge, Method: PKCEMethodS256}, nil
+}
+
+// NewChallengeWithMethod generates a challenge for method, which must be
+// S256 or plain. The plain method only exists for servers that cannot do
+// S256; SelectPKCEMethod never picks it when S256 is available.
+func NewChallengeWithMethod(method string) (Challenge, error) {
+	switch method {
+	case "", PKCEMethodS256:
+		return NewChallenge()
+	case PKCEMethodPlain:
+		c, err := NewChallenge()
+		if err != nil {
+			return Challenge{}, err
+		}
+		return Challenge{Verifier: c.Verifier, Challenge: c.Verifier, Method: PKCEMethodPlain}, nil
+	}
+	return Challenge{}, fmt.Errorf("unsupported PKCE method %q", method)
+}
+
+// Config is the client identity used for interactive flows. The zero value
+// is a2acli's public CIMD client with the fixed RedirectURI.
+type Config struct {
+	// ClientID defaults to CIMDURL.
+	ClientID string
+	// ClientSecret is sent with HTTP Basic auth to the token endpoint when
+	// set (confidential clients registered with an enterprise IdP).
+	ClientSecret string
+	// AuthMethod selects how a confidential client authenticates:
+	// ClientAuthBasic (the default) or ClientAuthPost.
+	AuthMethod string
+	// RedirectURI defaults to RedirectURI. A loopback URI without a port, or
+	// with port 0 (e.g. http://127.0.0.1:0/callback), binds an ephemeral
+	// port as allowed by RFC 8252 §7.3.
+	RedirectURI string
+	Scopes      []string
+	Audience    string
+	// AuthParams are extra query parameters for the authorization request,
+	// e.g. prompt=consent or an IdP-specific hint.
+	AuthParams map[string]string
+}
+
+// EffectiveClientID returns ClientID, or CIMDURL when unset.
+func (c Config) EffectiveClientID() string {
+	if c.ClientID != "" {
+		return c.ClientID
+	}
+	return CIMDURL
+}
+
+func (c Config) redirectURI() string {
+	if c.RedirectURI != "" {
+		return c.RedirectURI
+	}
+	return RedirectURI
+}
+
+// setClientAuth authenticates req as the configured client: confidential
+// clients use HTTP Basic (RFC 6749 §2.3.1) unless AuthMethod is
+// ClientAuthPost, public clients send client_id in the form body.
+func (c Config) setClientAuth(req *http.Request, body url.Values) {
+	if c.ClientSecret != "" && c.AuthMethod == ClientAuthPost {
+		body.Set("client_id", c.EffectiveClientID())
+		body.Set("client_secret", c.ClientSecret)
+		return
+	}
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.EffectiveClientID()), url.QueryEscape(c.ClientSecret))
+		return
+	}
+	body.Set("client_id", c.EffectiveClientID())
 }
 
 // AuthURL constructs the authorization URL for the auth-code + PKCE flow.
-func AuthURL(authEndpoint, state string, pkce Challenge) string {
-	v := url.Values{
-		"response_type":         {"code"},
-		"client_id":             {CIMDURL},
-		"redirect_uri":          {RedirectURI},
-		"state":                 {state},
-		"code_challenge":        {pkce.Challenge},
-		"code_challenge_method": {"S256"},
+// cfg.RedirectURI must be the URI actually being served, as returned by
+// StartCallbackServer.
+func AuthURL(authEndpoint, state string, pkce Challenge, cfg Config) string {
+	v := url.Values{}
+	for k, val := range cfg.AuthParams {
+		v.Set(k, val)
 	}
-	return authEndpoint + "?" + v.Encode()
+	if len(cfg.Scopes) > 0 {
+		v.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+	if cfg.Audience != "" {
+		v.Set("audience", cfg.Audience)
+	}
+	// Protocol parameters are set last so AuthParams cannot override them.
+	v.Set("response_type", "code")
+	v.Set("client_id", cfg.EffectiveClientID())
+	v.Set("redirect_uri", cfg.redirectURI())
+	v.Set("state", state)
+	v.Set("code_challenge", pkce.Challenge)
+	method := pkce.Method
+	if method == "" {
+		method = PKCEMethodS256
+	}
+	v.Set("code_challenge_method", method)
+	sep := "?"
+	if strings.Contains(authEndpoint, "?") {
+		sep = "&"
+	}
+	return authEndpoint + sep + v.Encode()
 }
 
 // OpenBrowser opens the given URL in the system default browser.
@@ -103,22 +201,44 @@ type CallbackResult struct {
 	Err   error
 }
 
-// StartCallbackServer starts a local HTTP server on CallbackAddr and waits for
-// the OAuth callback. Returns a channel that yields exactly one CallbackResult.
+// StartCallbackServer starts a local HTTP server for cfg's redirect URI
+// (CallbackAddr by default) and waits for the OAuth callback. It returns a
+// channel that yields exactly one CallbackResult, a stop function, and the
+// redirect URI being served — which differs from the configured one when an
+// ephemeral port was requested.
 //
 // It returns an error immediately if the port cannot be bound — this is the
 // "port 8080 in use" failure path documented in a2ac-38z.2.
-func StartCallbackServer(ctx context.Context, expectedState string) (<-chan CallbackResult, func(), error) {
-	ln, err := net.Listen("tcp", CallbackAddr)
+func StartCallbackServer(ctx context.Context, expectedState string, cfg Config) (<-chan CallbackResult, func(), string, error) {
+	u, err := url.Parse(cfg.redirectURI())
 	if err != nil {
-		return nil, nil, fmt.Errorf("cannot bind %s: %w\nHint: free port 8080 and retry (the OAuth callback server must listen on that exact address)", CallbackAddr, err)
+		return nil, nil, "", fmt.Errorf("invalid redirect_uri: %w", err)
+	}
+	if u.Scheme != "http" || !isLoopback(u.Hostname()) {
+		return nil, nil, "", fmt.Errorf("redirect_uri %s must be an http loopback address (127.0.0.1, [::1] or localhost)", u)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "0"
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("cannot bind %s: %w\nHint: free the port and retry, or configure an ephemeral loopback redirect_uri such as http://127.0.0.1:0/callback", addr, err)
+	}
+	if port == "0" {
+		u.Host = net.JoinHostPort(u.Hostname(), fmt.Sprint(ln.Addr().(*net.TCPAddr).Port))
+	}
+	callbackPath := u.Path
+	if callbackPath == "" {
+		callbackPath = "/"
 	}
 
 	ch := make(chan CallbackResult, 1)
 	mux := http.NewServeMux()
 	srv := &http.Server{Handler: mux}
 
-	mux.HandleFunc(CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
 		state := q.Get("state")
 		code := q.Get("code")
@@ -143,7 +263,16 @@ func StartCallbackServer(ctx context.Context, expectedState string) (<-chan Call
 	}()
 
 	stop := func() { _ = srv.Shutdown(ctx) }
-	return ch, stop, nil
+	return ch, stop, u.String(), nil
+}
+
+// isLoopback reports whether host names the local machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 // TokenResponse is the minimal shape of an OAuth 2.1 token endpoint response.
@@ -153,75 +282,89 @@ type TokenResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	Scope        string `json:"scope,omitempty"`
+	// IDToken is returned by OpenID Connect providers when the openid scope
+	// was requested.
+	IDToken string `json:"id_token,omitempty"`
+}
+
+// TokenError is an OAuth error response from a token endpoint (RFC 6749 §5.2).
+type TokenError struct {
+	Endpoint    string `json:"-"`
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("%s returned %d: %s — %s", e.Endpoint, e.Status, e.Code, e.Description)
 }
 
 // ExchangeCode exchanges an authorization code for tokens at tokenEndpoint.
-func ExchangeCode(ctx context.Context, tokenEndpoint, code string, pkce Challenge) (*TokenResponse, error) {
+// cfg must carry the same client and redirect URI used for AuthURL.
+func ExchangeCode(ctx context.Context, tokenEndpoint, code string, pkce Challenge, cfg Config) (*TokenResponse, error) {
 	body := url.Values{
 		"grant_type":    {"authorization_code"},
 		"code":          {code},
-		"redirect_uri":  {RedirectURI},
-		"client_id":     {CIMDURL},
+		"redirect_uri":  {cfg.redirectURI()},
 		"code_verifier": {pkce.Verifier},
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint,
-		strings.NewReader(body.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("build token request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("token exchange: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		var e struct {
-			Error string `json:"error"`
-			Desc  string `json:"error_description"`
-		}
-		_ = json.NewDecoder(resp.Body).Decode(&e)
-		return nil, fmt.Errorf("token endpoint returned %d: %s — %s", resp.StatusCode, e.Error, e.Desc)
-	}
-
-	var tok TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
-		return nil, fmt.Errorf("decode token response: %w", err)
-	}
-	return &tok, nil
+	return postTokenForm(ctx, tokenEndpoint, body, cfg, "token exchange", "token endpoint")
 }
 
-// RefreshAccessToken exchanges a refresh token for a new access token at tokenEndpoint.
+// RefreshAccessToken exchanges a refresh token for a new access token at
+// tokenEndpoint as a2acli's default public client.
 func RefreshAccessToken(ctx context.Context, tokenEndpoint, refreshToken string) (*TokenResponse, error) {
+	return RefreshAccessTokenWithConfig(ctx, tokenEndpoint, refreshToken, Config{})
+}
+
+// RefreshAccessTokenWithConfig refreshes a token issued to the client in cfg.
+func RefreshAccessTokenWithConfig(ctx context.Context, tokenEndpoint, refreshToken string, cfg Config) (*TokenResponse, error) {
 	body := url.Values{
 		"grant_type":    {"refresh_token"},
 		"refresh_token": {refreshToken},
-		"client_id":     {CIMDURL},
 	}
+	return postTokenForm(ctx, tokenEndpoint, body, cfg, "refresh token exchange", "refresh token endpoint")
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint,
-		strings.NewReader(body.Encode()))
+// postTokenForm POSTs body to tokenEndpoint authenticated as cfg's client.
+// action and label name the request and endpoint in error messages.
+func postTokenForm(ctx context.Context, tokenEndpoint string, body url.Values, cfg Config, action, label string) (*TokenResponse, error) {
+	req, err := newFormRequest(ctx, tokenEndpoint, body, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("build refresh token request: %w", err)
+		return nil, fmt.Errorf("build %s request: %w", action, err)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("refresh token exchange: %w", err)
+		return nil, fmt.Errorf("%s: %w", action, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	return decodeTokenResponse(resp, label)
+}
 
+// newFormRequest builds a form POST of body to endpoint, authenticated as
+// cfg's client.
+func newFormRequest(ctx context.Context, endpoint string, body url.Values, cfg Config) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Client auth may add client_id to the form, so encode afterwards.
+	cfg.setClientAuth(req, body)
+	encoded := body.Encode()
+	req.Body = io.NopCloser(strings.NewReader(encoded))
+	req.ContentLength = int64(len(encoded))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// decodeTokenResponse decodes a token endpoint response, turning non-200
+// statuses into errors that carry the OAuth error code and description.
+// label names the endpoint in error messages.
+func decodeTokenResponse(resp *http.Response, label string) (*TokenResponse, error) {
 	if resp.StatusCode != http.StatusOK {
-		var e struct {
-			Error string `json:"error"`
-			Desc  string `json:"error_description"`
-		}
-		_ = json.NewDecoder(resp.Body).Decode(&e)
-		return nil, fmt.Errorf("refresh token endpoint returned %d: %s — %s", resp.StatusCode, e.Error, e.Desc)
+		e := &TokenError{Endpoint: label, Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(e)
+		return nil, e
 	}
 
 	var tok TokenResponse
@@ -231,13 +374,36 @@ func RefreshAccessToken(ctx context.Context, tokenEndpoint, refreshToken string)
 	return &tok, nil
 }
 
-// StoredToken is the on-disk token record keyed by service URL host.
+// StoredToken is the token record kept in the TokenStore, keyed by its
+// TokenIdentity.
 type StoredToken struct {
+	// ServiceURL is the normalized service URL the token is bound to. It is
+	// empty in legacy host-keyed records.
+	ServiceURL string `json:"service_url,omitempty"`
+
 	AccessToken  string    `json:"access_token"`
 	RefreshToken string    `json:"refresh_token,omitempty"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	Scope        string    `json:"scope,omitempty"`
 	TokenURL     string    `json:"token_url"`
+
+	// GrantType is set to GrantClientCredentials for tokens minted with the
+	// client_credentials grant; the fields below are then used to mint a
+	// replacement when the token expires.
+	GrantType    string   `json:"grant_type,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	AuthMethod   string   `json:"auth_method,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Audience     string   `json:"audience,omitempty"`
+	Resource     string   `json:"resource,omitempty"`
+
+	// Issuer and RevocationURL are recorded when the endpoints came from
+	// authorization server metadata.
+	Issuer        string `json:"issuer,omitempty"`
+	RevocationURL string `json:"revocation_url,omitempty"`
+	// Subject is the sub claim of a validated OpenID Connect ID token.
+	Subject string `json:"subject,omitempty"`
 }
 
 // IsExpired reports whether the token has expired (with a 30s buffer).
@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Token type hints for revocation requests (RFC 7009 §2.1).
+const (
+	HintAccessToken  = "access_token"
+	HintRefreshToken = "refresh_token"
+)
+
+// RevokeToken asks the authorization server to invalidate token at
+// revocationEndpoint (RFC 7009), authenticating as the client in cfg. The
+// server answers 200 whether or not the token was still valid, so only
+// transport failures and error responses are reported.
+func RevokeToken(ctx context.Context, revocationEndpoint, token, hint string, cfg Config) error {
+	body := url.Values{"token": {token}}
+	if hint != "" {
+		body.Set("token_type_hint", hint)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revocationEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("build revocation request: %w", err)
+	}
+	cfg.setClientAuth(req, body)
+	encoded := body.Encode()
+	req.Body = io.NopCloser(strings.NewReader(encoded))
+	req.ContentLength = int64(len(encoded))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revocation: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	e := &TokenError{Endpoint: "revocation endpoint", Status: resp.StatusCode}
+	_ = json.NewDecoder(resp.Body).Decode(e)
+	return e
+}
+
+// Revoke revokes the refresh token and the access token of t at its
+// RevocationURL, refresh token first since revoking it usually invalidates
+// the access tokens issued from it (RFC 7009 §2.1). It returns
+// ErrNoRevocationEndpoint when the token was not obtained through server
+// metadata that advertised one.
+func (t *StoredToken) Revoke(ctx context.Context) error {
+	if t.RevocationURL == "" {
+		return ErrNoRevocationEndpoint
+	}
+	cfg := Config{ClientID: t.ClientID, ClientSecret: t.ClientSecret, AuthMethod: t.AuthMethod}
+	var errs []error
+	if t.RefreshToken != "" {
+		if err := RevokeToken(ctx, t.RevocationURL, t.RefreshToken, HintRefreshToken, cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if t.AccessToken != "" {
+		if err := RevokeToken(ctx, t.RevocationURL, t.AccessToken, HintAccessToken, cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrNoRevocationEndpoint reports that a token has no known revocation
+// endpoint, so logging out can only forget it locally.
+var ErrNoRevocationEndpoint = errors.New("no revocation endpoint known for this token")
@@ -16,17 +16,21 @@ package oauth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
 
 // tokenDir returns the XDG-compliant directory for token storage.
-// Tokens live at ~/.config/a2acli/tokens/<host>.json with 0600 permissions.
+// Tokens live at ~/.config/a2acli/tokens/<key>.json with 0600 permissions.
 func tokenDir() (string, error) {
 	base := os.Getenv("XDG_CONFIG_HOME")
 	if base == "" {
@@ -40,8 +44,88 @@ func tokenDir() (string, error) {
 	return dir, os.MkdirAll(dir, 0700)
 }
 
-// tokenKey derives a filesystem-safe key from a service URL (uses the host).
-func tokenKey(serviceURL string) (string, error) {
+// GrantStatic marks a static token saved with 'config env add --token' into
+// a non-file token store, so it stays out of config.yaml.
+const GrantStatic = "static"
+
+// GrantCommand marks a token printed by an environment's token_command,
+// cached until it expires so the command does not run on every call.
+const GrantCommand = "token_command"
+
+// TokenIdentity is what a stored credential is bound to: the exact service
+// URL (scheme, host, port and path) plus the issuer and audience it was
+// obtained from. Two agents on one host, or two tenants behind one gateway,
+// therefore never share a token.
+type TokenIdentity struct {
+	ServiceURL string
+	Issuer     string
+	Audience   string
+}
+
+// normalizeServiceURL canonicalises a service URL for keying: lower-case
+// scheme and host, default ports dropped, no trailing slash, query or
+// fragment.
+func normalizeServiceURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("service URL %q must be absolute", raw)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return scheme + "://" + host + strings.TrimRight(u.Path, "/"), nil
+}
+
+// keySlug turns a normalized service URL into the readable part of a key.
+func keySlug(norm string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.Replace(norm, "://", "_", 1))
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	return slug
+}
+
+// key returns the store key for id: a readable slug of the service URL
+// followed by a hash of the full identity, e.g.
+// https_agent_example_com_agents_a-1f2e3d4c.
+func (id TokenIdentity) key() (string, error) {
+	norm, err := normalizeServiceURL(id.ServiceURL)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(norm + "\n" + strings.TrimSuffix(id.Issuer, "/") + "\n" + id.Audience))
+	return keySlug(norm) + "-" + hex.EncodeToString(sum[:4]), nil
+}
+
+// isKeyFor reports whether key was derived from a service URL with slug.
+func isKeyFor(key, slug string) bool {
+	rest, ok := strings.CutPrefix(key, slug+"-")
+	if !ok || len(rest) != 8 {
+		return false
+	}
+	_, err := hex.DecodeString(rest)
+	return err == nil
+}
+
+// legacyTokenKey is the host-only key used before tokens were bound to
+// their full identity (~/.config/a2acli/tokens/<host>.json).
+func legacyTokenKey(serviceURL string) (string, error) {
 	u, err := url.Parse(serviceURL)
 	if err != nil {
 		return "", err
@@ -49,55 +133,157 @@ func tokenKey(serviceURL string) (string, error) {
 	host := u.Hostname()
 	// Sanitise: replace any remaining non-alphanum with '_'
 	safe := strings.NewReplacer(".", "_", ":", "_", "/", "_").Replace(host)
-	return safe + ".json", nil
+	return safe, nil
+}
+
+// StoredEntry is one credential in the token store.
+type StoredEntry struct {
+	Key   string
+	Token *StoredToken
+	// Legacy is set for host-keyed tokens that predate identity keys; they
+	// are migrated the first time a service on that host uses them.
+	Legacy bool
+}
+
+// getEntry loads and decodes the token stored under key, or nil.
+func getEntry(key string) (*StoredToken, error) {
+	b, err := store.Get(key)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	var tok StoredToken
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, fmt.Errorf("decode token %s from %s store: %w", key, store.Name(), err)
+	}
+	return &tok, nil
+}
+
+// ListTokens returns every credential in the active store, sorted by key.
+// Entries that cannot be read are skipped.
+func ListTokens() ([]StoredEntry, error) {
+	keys, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+	var out []StoredEntry
+	for _, key := range keys {
+		tok, err := getEntry(key)
+		if err != nil || tok == nil {
+			continue
+		}
+		out = append(out, StoredEntry{Key: key, Token: tok, Legacy: tok.ServiceURL == ""})
+	}
+	return out, nil
+}
+
+// TokensFor returns every token stored for a service URL, one per issuer
+// and audience. Legacy host-keyed tokens are not included.
+func TokensFor(serviceURL string) ([]StoredEntry, error) {
+	norm, err := normalizeServiceURL(serviceURL)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	slug := keySlug(norm)
+	var out []StoredEntry
+	for _, key := range keys {
+		if !isKeyFor(key, slug) {
+			continue
+		}
+		tok, err := getEntry(key)
+		if err != nil {
+			return nil, err
+		}
+		// The slug is lossy; the record carries the exact URL.
+		if tok != nil && tok.ServiceURL == norm {
+			out = append(out, StoredEntry{Key: key, Token: tok})
+		}
+	}
+	return out, nil
 }
 
-// SaveToken persists a StoredToken for the given service URL.
+// SaveToken persists a StoredToken for the given service URL in the active
+// TokenStore, keyed by the service URL and the token's Issuer and Audience.
 func SaveToken(serviceURL string, tok *StoredToken) error {
-	dir, err := tokenDir()
+	norm, err := normalizeServiceURL(serviceURL)
 	if err != nil {
 		return err
 	}
-	key, err := tokenKey(serviceURL)
+	tok.ServiceURL = norm
+	key, err := TokenIdentity{ServiceURL: norm, Issuer: tok.Issuer, Audience: tok.Audience}.key()
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, key)
 	b, err := json.MarshalIndent(tok, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, b, 0600)
+	return store.Store(key, b)
 }
 
-// LoadToken retrieves the stored token for a service URL.
-// Returns nil, nil if no token is stored.
+// LoadToken retrieves the stored token for a service URL. When tokens from
+// several issuers or audiences are stored, an unexpired one is preferred,
+// then the one that expires last. A legacy host-keyed token is migrated to
+// this service URL on first use. Returns nil, nil if no token is stored.
 func LoadToken(serviceURL string) (*StoredToken, error) {
-	dir, err := tokenDir()
+	entries, err := TokensFor(serviceURL)
 	if err != nil {
 		return nil, err
 	}
-	key, err := tokenKey(serviceURL)
-	if err != nil {
-		return nil, err
+	// Cached token_command output is only used through LoadCommandToken.
+	entries = slices.DeleteFunc(entries, func(e StoredEntry) bool { return e.Token.GrantType == GrantCommand })
+	if len(entries) == 0 {
+		return migrateLegacyToken(serviceURL)
+	}
+	best := entries[0].Token
+	for _, e := range entries[1:] {
+		if betterToken(e.Token, best) {
+			best = e.Token
+		}
+	}
+	return best, nil
+}
+
+// betterToken reports whether a should be used in preference to b.
+func betterToken(a, b *StoredToken) bool {
+	if a.IsExpired() != b.IsExpired() {
+		return !a.IsExpired()
 	}
-	b, err := os.ReadFile(filepath.Join(dir, key))
-	if errors.Is(err, os.ErrNotExist) {
-		return nil, nil
+	if a.ExpiresAt.IsZero() || b.ExpiresAt.IsZero() {
+		return a.ExpiresAt.IsZero() && !b.ExpiresAt.IsZero()
 	}
+	return a.ExpiresAt.After(b.ExpiresAt)
+}
+
+// migrateLegacyToken moves a host-keyed token to serviceURL's identity key.
+// The legacy entry is removed, so other services on the same host must log
+// in again rather than silently sharing it.
+func migrateLegacyToken(serviceURL string) (*StoredToken, error) {
+	key, err := legacyTokenKey(serviceURL)
 	if err != nil {
 		return nil, err
 	}
-	var tok StoredToken
-	if err := json.Unmarshal(b, &tok); err != nil {
+	tok, err := getEntry(key)
+	if err != nil || tok == nil || tok.ServiceURL != "" {
 		return nil, err
 	}
-	return &tok, nil
+	if err := SaveToken(serviceURL, tok); err != nil {
+		return nil, fmt.Errorf("migrate legacy token %s: %w", key, err)
+	}
+	if err := store.Erase(key); err != nil {
+		return nil, fmt.Errorf("migrate legacy token %s: %w", key, err)
+	}
+	return tok, nil
 }
 
 // LoadValidToken retrieves the stored token for a service URL.
 // If the token is expired but carries a RefreshToken and TokenURL, it attempts
 // to refresh the token, saves the updated token back to disk, and returns it.
+// Expired client_credentials tokens are re-minted the same way.
 // If refresh fails or no refresh token is available, it returns the stored token as-is.
 func LoadValidToken(ctx context.Context, serviceURL string) (*StoredToken, error) {
 	tok, err := LoadToken(serviceURL)
@@ -107,14 +293,24 @@ func LoadValidToken(ctx context.Context, serviceURL string) (*StoredToken, error
 	if !tok.IsExpired() {
 		return tok, nil
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if tok.RefreshToken == "" && tok.GrantType == GrantClientCredentials && tok.ClientID != "" {
+		newTok, err := ClientCredentialsToken(ctx, tok.clientCredentials())
+		if err != nil {
+			return tok, nil
+		}
+		tok.apply(newTok)
+		_ = SaveToken(serviceURL, tok)
+		return tok, nil
+	}
 	if tok.RefreshToken == "" || tok.TokenURL == "" {
 		return tok, nil
 	}
 
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	newTok, err := RefreshAccessToken(ctx, tok.TokenURL, tok.RefreshToken)
+	newTok, err := RefreshAccessTokenWithConfig(ctx, tok.TokenURL, tok.RefreshToken,
+		Config{ClientID: tok.ClientID, ClientSecret: tok.ClientSecret, AuthMethod: tok.AuthMethod})
 	if err != nil {
 		return tok, nil
 	}
@@ -134,19 +330,75 @@ func LoadValidToken(ctx context.Context, serviceURL string) (*StoredToken, error
 	return tok, nil
 }
 
-// DeleteToken removes the stored token for a service URL.
+// commandTokenKey binds cached token_command output to the service URL and
+// the exact command, so editing the command invalidates the cache.
+func commandTokenKey(serviceURL, command string) (string, error) {
+	sum := sha256.Sum256([]byte(command))
+	return TokenIdentity{ServiceURL: serviceURL, Issuer: GrantCommand, Audience: hex.EncodeToString(sum[:8])}.key()
+}
+
+// LoadCommandToken returns the cached output of command for serviceURL,
+// or nil when nothing is cached or the cached token has expired.
+func LoadCommandToken(serviceURL, command string) (*StoredToken, error) {
+	key, err := commandTokenKey(serviceURL, command)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := getEntry(key)
+	if err != nil || tok == nil || tok.GrantType != GrantCommand || tok.IsExpired() {
+		return nil, err
+	}
+	return tok, nil
+}
+
+// SaveCommandToken caches the output of command for serviceURL until
+// tok.ExpiresAt.
+func SaveCommandToken(serviceURL, command string, tok *StoredToken) error {
+	norm, err := normalizeServiceURL(serviceURL)
+	if err != nil {
+		return err
+	}
+	key, err := commandTokenKey(norm, command)
+	if err != nil {
+		return err
+	}
+	tok.ServiceURL = norm
+	tok.GrantType = GrantCommand
+	b, err := json.MarshalIndent(tok, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.Store(key, b)
+}
+
+// DeleteCommandToken drops the cached output of command for serviceURL.
+func DeleteCommandToken(serviceURL, command string) error {
+	key, err := commandTokenKey(serviceURL, command)
+	if err != nil {
+		return err
+	}
+	return store.Erase(key)
+}
+
+// DeleteToken removes every token stored for a service URL, whichever
+// issuer or audience it was obtained from, and any legacy host-keyed token
+// that would otherwise be migrated to it.
 func DeleteToken(serviceURL string) error {
-	dir, err := tokenDir()
+	entries, err := TokensFor(serviceURL)
 	if err != nil {
 		return err
 	}
-	key, err := tokenKey(serviceURL)
+	for _, e := range entries {
+		if err := store.Erase(e.Key); err != nil {
+			return err
+		}
+	}
+	key, err := legacyTokenKey(serviceURL)
 	if err != nil {
 		return err
 	}
-	err = os.Remove(filepath.Join(dir, key))
-	if errors.Is(err, os.ErrNotExist) {
-		return nil
+	if tok, err := getEntry(key); err == nil && tok != nil && tok.ServiceURL == "" {
+		return store.Erase(key)
 	}
-	return err
+	return nil
 }
@@ -0,0 +1,356 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TokenStore persists serialized tokens under opaque keys. Get returns
+// nil, nil when nothing is stored for key, and Erase of a missing key is not
+// an error.
+type TokenStore interface {
+	Get(key string) ([]byte, error)
+	Store(key string, data []byte) error
+	Erase(key string) error
+	// List returns every key a2acli has stored.
+	List() ([]string, error)
+	// Name describes the backend for diagnostics, e.g. "file" or
+	// "helper:pass".
+	Name() string
+}
+
+// store is the backend used by SaveToken, LoadToken and DeleteToken.
+var store TokenStore = FileStore{}
+
+// SetTokenStore selects the backend used by SaveToken, LoadToken and
+// DeleteToken. A nil store restores the default FileStore.
+func SetTokenStore(s TokenStore) {
+	if s == nil {
+		s = FileStore{}
+	}
+	store = s
+}
+
+// CurrentTokenStore returns the active backend.
+func CurrentTokenStore() TokenStore { return store }
+
+// NewTokenStore builds a backend from its config spelling:
+//
+//	file                    JSON files under ~/.config/a2acli/tokens (default)
+//	secret-service          the freedesktop Secret Service via libsecret's secret-tool
+//	helper:<name>           the credential helper a2acli-credential-<name> on PATH
+//	helper:/path/to/helper  a credential helper at an explicit path
+func NewTokenStore(spec string) (TokenStore, error) {
+	switch {
+	case spec == "" || spec == "file":
+		return FileStore{}, nil
+	case spec == "secret-service" || spec == "libsecret":
+		return SecretServiceStore{}, nil
+	case strings.HasPrefix(spec, "helper:"):
+		name := strings.TrimPrefix(spec, "helper:")
+		if name == "" {
+			return nil, fmt.Errorf("token store %q names no credential helper", spec)
+		}
+		return HelperStore{Program: name}, nil
+	}
+	return nil, fmt.Errorf("unknown token store %q (use file, secret-service or helper:<name>)", spec)
+}
+
+// FileStore keeps each token in <Dir>/<key>.json with 0600 permissions.
+// An empty Dir uses the XDG token directory.
+type FileStore struct {
+	Dir string
+}
+
+func (f FileStore) dir() (string, error) {
+	if f.Dir == "" {
+		return tokenDir()
+	}
+	return f.Dir, os.MkdirAll(f.Dir, 0700)
+}
+
+func (f FileStore) path(key string) (string, error) {
+	dir, err := f.dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+".json"), nil
+}
+
+// Get implements TokenStore.
+func (f FileStore) Get(key string) ([]byte, error) {
+	p, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// Store implements TokenStore.
+func (f FileStore) Store(key string, data []byte) error {
+	p, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0600)
+}
+
+// Erase implements TokenStore.
+func (f FileStore) Erase(key string) error {
+	p, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List implements TokenStore.
+func (f FileStore) List() ([]string, error) {
+	dir, err := f.dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && e.Type().IsRegular() {
+			keys = append(keys, name)
+		}
+	}
+	return keys, nil
+}
+
+// Name implements TokenStore.
+func (FileStore) Name() string { return "file" }
+
+// secretToolAttr is the attribute every a2acli secret is tagged with.
+const secretToolAttr = "a2acli"
+
+// SecretServiceStore keeps tokens in the desktop keyring (GNOME Keyring,
+// KWallet, KeePassXC) through libsecret's secret-tool, so no D-Bus bindings
+// are linked into a2acli.
+type SecretServiceStore struct{}
+
+func secretTool(stdin string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, fmt.Errorf("secret-service token store needs secret-tool (libsecret-tools): %w", err)
+	}
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, err
+}
+
+// Get implements TokenStore. secret-tool exits 1 without output when the
+// item does not exist.
+func (SecretServiceStore) Get(key string) ([]byte, error) {
+	out, err := secretTool("", "lookup", "application", secretToolAttr, "key", key)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(out) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	return out, nil
+}
+
+// Store implements TokenStore.
+func (SecretServiceStore) Store(key string, data []byte) error {
+	_, err := secretTool(string(data), "store", "--label", "a2acli token "+key,
+		"application", secretToolAttr, "key", key)
+	if err != nil {
+		return fmt.Errorf("secret-tool store: %w", err)
+	}
+	return nil
+}
+
+// Erase implements TokenStore.
+func (SecretServiceStore) Erase(key string) error {
+	_, err := secretTool("", "clear", "application", secretToolAttr, "key", key)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil // nothing to clear
+	}
+	if err != nil {
+		return fmt.Errorf("secret-tool clear: %w", err)
+	}
+	return nil
+}
+
+// List implements TokenStore by parsing the "attribute.key = <key>" lines
+// of secret-tool search. Older libsecret releases print attributes on
+// stderr, so both streams are scanned.
+func (SecretServiceStore) List() ([]string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, fmt.Errorf("secret-service token store needs secret-tool (libsecret-tools): %w", err)
+	}
+	cmd := exec.Command("secret-tool", "search", "--all", "application", secretToolAttr)
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("secret-tool search: %w", err)
+	}
+	var keys []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "attribute.key = "); ok {
+			keys = append(keys, v)
+		}
+	}
+	return keys, nil
+}
+
+// Name implements TokenStore.
+func (SecretServiceStore) Name() string { return "secret-service" }
+
+// HelperStore delegates to an external credential helper speaking the
+// docker-credential-helpers protocol, so existing helpers (pass, osxkeychain,
+// wincred, or a corporate vault bridge) can be wrapped with a small script:
+//
+//	<helper> get     stdin: key            stdout: {"ServerURL","Username","Secret"}
+//	<helper> store   stdin: {"ServerURL","Username","Secret"}
+//	<helper> erase   stdin: key
+//	<helper> list    stdout: {"<key>": "<username>", ...}
+//
+// The key is passed as ServerURL, Username is always "a2acli" and Secret is
+// the serialized token. A get for an unknown key must exit non-zero and
+// print "credentials not found" (docker's errCredentialsNotFound message).
+type HelperStore struct {
+	// Program is a helper name, resolved as a2acli-credential-<name> on
+	// PATH, or a path to an executable.
+	Program string
+}
+
+// helperCredentials is the JSON document exchanged with credential helpers.
+type helperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+const helperNotFound = "credentials not found"
+
+func (h HelperStore) program() string {
+	if strings.ContainsRune(h.Program, filepath.Separator) || strings.ContainsRune(h.Program, '/') {
+		return h.Program
+	}
+	return "a2acli-credential-" + h.Program
+}
+
+func (h HelperStore) run(action, stdin string) ([]byte, error) {
+	cmd := exec.Command(h.program(), action)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(string(out) + " " + stderr.String())
+		if strings.Contains(msg, helperNotFound) {
+			return nil, errHelperNotFound
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("credential helper %s %s: %w: %s", h.program(), action, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %s %s: %w", h.program(), action, err)
+	}
+	return out, nil
+}
+
+var errHelperNotFound = errors.New(helperNotFound)
+
+// Get implements TokenStore.
+func (h HelperStore) Get(key string) ([]byte, error) {
+	out, err := h.run("get", key)
+	if errors.Is(err, errHelperNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c helperCredentials
+	if err := json.Unmarshal(out, &c); err != nil {
+		return nil, fmt.Errorf("credential helper %s get: decode response: %w", h.program(), err)
+	}
+	if c.Secret == "" {
+		return nil, nil
+	}
+	return []byte(c.Secret), nil
+}
+
+// Store implements TokenStore.
+func (h HelperStore) Store(key string, data []byte) error {
+	b, err := json.Marshal(helperCredentials{ServerURL: key, Username: "a2acli", Secret: string(data)})
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", string(b))
+	return err
+}
+
+// Erase implements TokenStore.
+func (h HelperStore) Erase(key string) error {
+	_, err := h.run("erase", key)
+	if errors.Is(err, errHelperNotFound) {
+		return nil
+	}
+	return err
+}
+
+// List implements TokenStore, keeping only the entries stored by a2acli
+// since helpers such as docker's return every credential they hold.
+func (h HelperStore) List() ([]string, error) {
+	out, err := h.run("list", "")
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]string
+	if err := json.Unmarshal(out, &all); err != nil {
+		return nil, fmt.Errorf("credential helper %s list: decode response: %w", h.program(), err)
+	}
+	var keys []string
+	for key, user := range all {
+		if user == "a2acli" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Name implements TokenStore.
+func (h HelperStore) Name() string { return "helper:" + h.Program }
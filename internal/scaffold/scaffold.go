@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scaffold generates agent projects from an AgentCard: a server that
+// serves the card and its interfaces, an executor with a case for each skill,
+// and tests, ready to build and run.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// DefaultA2AVersion is the a2a-go version generated Go projects require when
+// Project.A2AVersion is empty.
+const DefaultA2AVersion = "v2.4.0"
+
+// goVersion is the go directive of generated Go projects, the minimum that
+// a2a-go v2 requires.
+const goVersion = "1.25.0"
+
+// Project describes the project to generate.
+type Project struct {
+	// Module is the Go module path, e.g. example.com/weather-agent.
+	Module string
+	// Card is the agent's card and CardJSON the document it was parsed
+	// from, which is written to agent-card.json as is.
+	Card     *a2a.AgentCard
+	CardJSON []byte
+	// A2AVersion is the a2a-go module version to require.
+	A2AVersion string
+}
+
+// File is a generated file; Path is relative to the project directory.
+type File struct {
+	Path string
+	Data []byte
+}
+
+// Languages returns the languages projects can be generated in.
+func Languages() []string {
+	entries, _ := templates.ReadDir("templates")
+	var langs []string
+	for _, e := range entries {
+		langs = append(langs, e.Name())
+	}
+	return langs
+}
+
+// templateData is what the templates see.
+type templateData struct {
+	Project
+	// Binary is the command name, the last element of the module path.
+	Binary string
+	// BaseURL is the scheme and host of the card's first HTTP interface.
+	BaseURL   string
+	GoVersion string
+}
+
+var funcs = template.FuncMap{
+	// oneline collapses whitespace, for text in line comments.
+	"oneline": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}
+
+// Render generates the files of a project in lang.
+func Render(lang string, p Project) ([]File, error) {
+	root := path.Join("templates", lang)
+	if _, err := fs.Stat(templates, root); err != nil {
+		return nil, fmt.Errorf("unsupported language %q (supported: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	if p.Card == nil {
+		return nil, fmt.Errorf("no agent card")
+	}
+	// Each skill becomes a case of a switch.
+	seen := map[string]bool{}
+	for _, s := range p.Card.Skills {
+		if s.ID == "" || seen[s.ID] {
+			return nil, fmt.Errorf("skill IDs must be unique and not empty; %q is not", s.ID)
+		}
+		seen[s.ID] = true
+	}
+	if p.A2AVersion == "" {
+		p.A2AVersion = DefaultA2AVersion
+	}
+	data := templateData{Project: p, Binary: path.Base(p.Module), BaseURL: BaseURL(p.Card), GoVersion: goVersion}
+
+	files := []File{{Path: "agent-card.json", Data: append(bytes.TrimSpace(p.CardJSON), '\n')}}
+	err := fs.WalkDir(templates, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		src, err := templates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(path.Base(name)).Funcs(funcs).Parse(string(src))
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return err
+		}
+		rel := strings.TrimSuffix(strings.TrimPrefix(name, root+"/"), ".tmpl")
+		b := out.Bytes()
+		if strings.HasSuffix(rel, ".go") {
+			if b, err = format.Source(b); err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+		}
+		files = append(files, File{Path: rel, Data: b})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// BaseURL returns the scheme and host of the card's first HTTP interface, or
+// the generated server's default address if it has none.
+func BaseURL(card *a2a.AgentCard) string {
+	for _, iface := range card.SupportedInterfaces {
+		if iface.ProtocolBinding == a2a.TransportProtocolGRPC {
+			continue
+		}
+		if u, err := url.Parse(iface.URL); err == nil && u.Host != "" {
+			return u.Scheme + "://" + u.Host
+		}
+	}
+	return "http://localhost:8080"
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffold_test
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/v2/a2a"
+	"github.com/ghchinoy/a2acli/internal/scaffold"
+)
+
+const weatherCard = `{
+  "name": "Weather",
+  "description": "Forecasts",
+  "version": "1.0.0",
+  "supportedInterfaces": [
+    {"url": "http://localhost:7000/invoke", "protocolBinding": "JSONRPC", "protocolVersion": "1.0"},
+    {"url": "localhost:7001", "protocolBinding": "GRPC", "protocolVersion": "1.0"}
+  ],
+  "securitySchemes": {"bearer": {"httpAuthSecurityScheme": {"scheme": "Bearer"}}},
+  "securityRequirements": [{"schemes": {"bearer": []}}],
+  "capabilities": {"streaming": true},
+  "defaultInputModes": ["text/plain"],
+  "defaultOutputModes": ["text/plain"],
+  "skills": [
+    {"id": "forecast", "name": "Forecast", "description": "Gives the\n forecast", "tags": []},
+    {"id": "current-\"conditions\"", "name": "Now", "description": "Current conditions", "tags": []}
+  ]
+}`
+
+func project(t *testing.T, raw string) scaffold.Project {
+	t.Helper()
+	var card a2a.AgentCard
+	if err := json.Unmarshal([]byte(raw), &card); err != nil {
+		t.Fatal(err)
+	}
+	return scaffold.Project{Module: "example.com/weather", Card: &card, CardJSON: []byte(raw)}
+}
+
+func TestRenderGo(t *testing.T) {
+	files, err := scaffold.Render("go", project(t, weatherCard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	got := map[string]string{}
+	for _, f := range files {
+		paths = append(paths, f.Path)
+		got[f.Path] = string(f.Data)
+	}
+	want := []string{"README.md", "agent-card.json", "auth.go", "executor.go", "executor_test.go", "go.mod", "main.go", "main_test.go"}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("files = %v, want %v", paths, want)
+	}
+
+	for _, p := range paths {
+		if !strings.HasSuffix(p, ".go") {
+			continue
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), p, got[p], parser.AllErrors); err != nil {
+			t.Errorf("%s does not parse: %v", p, err)
+		}
+	}
+	if got["agent-card.json"] != weatherCard+"\n" {
+		t.Errorf("agent-card.json is not the card as given:\n%s", got["agent-card.json"])
+	}
+	for _, s := range []string{"module example.com/weather", "go 1.25.0", "github.com/a2aproject/a2a-go/v2 " + scaffold.DefaultA2AVersion} {
+		if !strings.Contains(got["go.mod"], s) {
+			t.Errorf("go.mod lacks %q:\n%s", s, got["go.mod"])
+		}
+	}
+	for _, s := range []string{`case "forecast", "":`, `case "current-\"conditions\"":`, "// TODO: Gives the forecast"} {
+		if !strings.Contains(got["executor.go"], s) {
+			t.Errorf("executor.go lacks %q", s)
+		}
+	}
+	if !strings.Contains(got["README.md"], "a2acli discover -u http://localhost:7000") {
+		t.Errorf("README.md does not use the card's URL:\n%s", got["README.md"])
+	}
+}
+
+func TestRenderMultilineName(t *testing.T) {
+	// A name with a line break must not end the package comment and inject
+	// code into main.go.
+	raw := strings.Replace(weatherCard, `"name": "Weather"`, `"name": "Weather\nfunc init() { panic(1) }\n//"`, 1)
+	p := project(t, raw)
+	p.Module = "example.com/weather\nagent"
+	files, err := scaffold.Render("go", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.Path != "main.go" {
+			continue
+		}
+		src := string(f.Data)
+		if _, err := parser.ParseFile(token.NewFileSet(), f.Path, src, parser.AllErrors); err != nil {
+			t.Errorf("main.go does not parse: %v", err)
+		}
+		if first, _, _ := strings.Cut(src, "\n"); first != "// Command weather agent serves the Weather func init() { panic(1) } // A2A agent." {
+			t.Errorf("package comment = %q", first)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := scaffold.Render("cobol", project(t, weatherCard)); err == nil || !strings.Contains(err.Error(), "go") {
+		t.Errorf("unsupported language: err = %v, want one naming the supported languages", err)
+	}
+	if _, err := scaffold.Render("go", scaffold.Project{Module: "example.com/x"}); err == nil {
+		t.Error("no card: err = nil")
+	}
+	dup := strings.Replace(weatherCard, `current-\"conditions\"`, "forecast", 1)
+	if _, err := scaffold.Render("go", project(t, dup)); err == nil || !strings.Contains(err.Error(), "forecast") {
+		t.Errorf("duplicate skill: err = %v", err)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	p := project(t, weatherCard)
+	if got := scaffold.BaseURL(p.Card); got != "http://localhost:7000" {
+		t.Errorf("BaseURL = %q", got)
+	}
+	if got := scaffold.BaseURL(&a2a.AgentCard{}); got != "http://localhost:8080" {
+		t.Errorf("BaseURL of a card without HTTP interfaces = %q", got)
+	}
+}